@@ -2,30 +2,59 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"errors"
 	"fmt"
+	"math"
+	"net/http"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/asymmetric-research/solana-exporter/pkg/api"
 	"github.com/asymmetric-research/solana-exporter/pkg/rpc"
 	"github.com/asymmetric-research/solana-exporter/pkg/slog"
+	"github.com/asymmetric-research/solana-exporter/pkg/version"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"slices"
 )
 
 const (
-	SkipStatusLabel      = "status"
-	StateLabel           = "state"
-	NodekeyLabel         = "nodekey"
-	VotekeyLabel         = "votekey"
-	VersionLabel         = "version"
-	IdentityLabel        = "identity"
-	AddressLabel         = "address"
-	EpochLabel           = "epoch"
-	TransactionTypeLabel = "transaction_type"
-	IsFiredancerLabel    = "is_firedancer"
-	ClusterLabel         = "cluster"
+	SkipStatusLabel        = "status"
+	StateLabel             = "state"
+	NodekeyLabel           = "nodekey"
+	VotekeyLabel           = "votekey"
+	VersionLabel           = "version"
+	IdentityLabel          = "identity"
+	AddressLabel           = "address"
+	EpochLabel             = "epoch"
+	TransactionTypeLabel   = "transaction_type"
+	IsFiredancerLabel      = "is_firedancer"
+	ClusterLabel           = "cluster"
+	NameLabel              = "name"
+	CollectorLabel         = "collector"
+	ClientLabel            = "client"
+	MintLabel              = "mint"
+	WithdrawAuthorityLabel = "withdraw_authority"
+	CommitLabel            = "commit"
+	GoVersionLabel         = "go_version"
+	PortTypeLabel          = "port_type"
+	CommitmentLabel        = "commitment"
+	DataSizeLabel          = "data_size"
+	GenesisHashLabel       = "genesis_hash"
+	ProgramLabel           = "program"
+	VoteAccountsLabel      = "vote_accounts"
+	SlotLabel              = "slot"
+	SlotWatcherLabel       = "slot_watcher"
+	BalancesLabel          = "balances"
+	SampleIndexLabel       = "sample_index"
+
+	// ClusterUnknown is the cluster label of nodes whose genesis hash is not that of a known cluster:
+	ClusterUnknown = "unknown"
 
 	StatusSkipped = "skipped"
 	StatusValid   = "valid"
@@ -33,80 +62,462 @@ const (
 	StateCurrent    = "current"
 	StateDelinquent = "delinquent"
 
+	ClientAgave      = "agave"
+	ClientFiredancer = "firedancer"
+
 	TransactionTypeVote    = "vote"
 	TransactionTypeNonVote = "non_vote"
+
+	PortTypeTpu     = "tpu"
+	PortTypeTpuQuic = "tpu_quic"
+	PortTypeRpc     = "rpc"
+
+	// maxBlockTimeLookback is the number of slots to walk back from the latest slot to find a block time:
+	maxBlockTimeLookback = 10
+	// nextLeaderSlotWindow is the number of upcoming slots searched for the next leader slots (the maximum allowed by
+	// getSlotLeaders), which is also reported for nodes that do not lead within it:
+	nextLeaderSlotWindow = 5000
+	// performanceSampleCount is the number of (minutely) performance samples the average slot time is taken over:
+	performanceSampleCount = 30
+	// rpcErrorLogInterval is the minimum interval between two logs of the same rpc error, so that an error which
+	// persists across scrapes does not flood the logs:
+	rpcErrorLogInterval = time.Minute
+
+	CollectorHealth              = "health"
+	CollectorMinimumLedgerSlot   = "minimum_ledger_slot"
+	CollectorFirstAvailableBlock = "first_available_block"
+	CollectorVoteAccounts        = "vote_accounts"
+	CollectorVersion             = "version"
+	CollectorIdentity            = "identity"
+	CollectorBalances            = "balances"
+	CollectorMinRequiredVersion  = "min_required_version"
+	CollectorNodeIsOutdated      = "node_is_outdated"
+	CollectorNodeNeedsUpdate     = "node_needs_update"
+	CollectorNodeAboveMaxVersion = "node_above_max_version"
+	CollectorFiredancer          = "firedancer"
+	CollectorStakeAccounts       = "stake_accounts"
+	CollectorBlockTimeLag        = "block_time_lag"
+	CollectorSnapshotSlots       = "snapshot_slots"
+	CollectorLargestAccounts     = "largest_accounts"
+	CollectorTokenAccounts       = "token_accounts"
+	CollectorStakePool           = "stake_pool"
+	CollectorNextLeaderSlot      = "next_leader_slot"
+	CollectorEpochCountdown      = "epoch_countdown"
+	CollectorTransactionCount    = "transaction_count"
+	CollectorReference           = "reference"
+	CollectorGossip              = "gossip"
+	CollectorConfirmationProbe   = "confirmation_probe"
+	CollectorCommitmentSlots     = "commitment_slots"
+	CollectorClusterSkipRate     = "cluster_skip_rate"
+	CollectorRentExempt          = "rent_exempt"
+	CollectorCluster             = "cluster"
+	CollectorPrograms            = "programs"
+	CollectorStakeMinimum        = "stake_minimum_delegation"
+	CollectorRpcPortProbe        = "rpc_port_probe"
+	CollectorPerformanceSamples  = "performance_samples"
+
+	// the collectors of the slot watcher, which run as the slots are watched rather than on scrape:
+	CollectorBlockProduction  = "block_production"
+	CollectorBlockInfos       = "block_infos"
+	CollectorInflationRewards = "inflation_rewards"
 )
 
+// Collectors lists all the collectors run by the SolanaCollector, in the order in which they are run.
+var Collectors = []string{
+	CollectorHealth,
+	CollectorMinimumLedgerSlot,
+	CollectorFirstAvailableBlock,
+	CollectorVoteAccounts,
+	CollectorVersion,
+	CollectorIdentity,
+	CollectorBalances,
+	CollectorMinRequiredVersion,
+	CollectorNodeIsOutdated,
+	CollectorNodeNeedsUpdate,
+	CollectorNodeAboveMaxVersion,
+	CollectorFiredancer,
+	CollectorStakeAccounts,
+	CollectorBlockTimeLag,
+	CollectorSnapshotSlots,
+	CollectorLargestAccounts,
+	CollectorTokenAccounts,
+	CollectorStakePool,
+	CollectorNextLeaderSlot,
+	CollectorEpochCountdown,
+	CollectorTransactionCount,
+	CollectorReference,
+	CollectorGossip,
+	CollectorConfirmationProbe,
+	CollectorCommitmentSlots,
+	CollectorClusterSkipRate,
+	CollectorRentExempt,
+	CollectorCluster,
+	CollectorPrograms,
+	CollectorStakeMinimum,
+	CollectorRpcPortProbe,
+	CollectorPerformanceSamples,
+}
+
+// SlotWatcherCollectors lists the collectors run by the SlotWatcher, which can be skipped just as those of the
+// SolanaCollector.
+var SlotWatcherCollectors = []string{
+	CollectorBlockProduction,
+	CollectorBlockInfos,
+	CollectorInflationRewards,
+}
+
+// VersionComplianceCollectors lists the collectors that depend on the foundation required versions API, which are
+// all skipped when version compliance is disabled.
+var VersionComplianceCollectors = []string{
+	CollectorMinRequiredVersion,
+	CollectorNodeIsOutdated,
+	CollectorNodeNeedsUpdate,
+	CollectorNodeAboveMaxVersion,
+}
+
+// LightModeCollectors lists the collectors that are skipped in light mode, as their metrics are visible through any
+// trusted node, and can be expensive to collect.
+var LightModeCollectors = []string{
+	CollectorVoteAccounts,
+	CollectorBalances,
+	CollectorStakeAccounts,
+	CollectorLargestAccounts,
+	CollectorTokenAccounts,
+	CollectorStakePool,
+	CollectorClusterSkipRate,
+	CollectorPrograms,
+	CollectorStakeMinimum,
+	CollectorPerformanceSamples,
+	CollectorBlockProduction,
+	CollectorBlockInfos,
+	CollectorInflationRewards,
+}
+
+// clusterSlots counts the leader slots of the whole cluster in an epoch, and how many of them were skipped.
+type clusterSlots struct {
+	epoch   int64
+	leader  int64
+	skipped int64
+}
+
+// scrapeNodeInfo holds the node details shared by several collectors, so that they are only fetched once per scrape.
+type scrapeNodeInfo struct {
+	version     string
+	featureSet  int64
+	versionErr  error
+	genesisHash string
+	cluster     string
+	clusterErr  error
+	// lastCluster is the cluster of the last successful detection, which clusterOrDefault falls back to:
+	lastCluster string
+}
+
+// voteParticipation tracks the progression of the last vote of a validator against that of the slot, across the scrapes
+// within an epoch.
+type voteParticipation struct {
+	epoch    int64
+	slot     int64
+	lastVote int64
+	// voted counts the slots elapsed since the first scrape of the epoch which were voted on (at most elapsed):
+	voted   int64
+	elapsed int64
+}
+
+// rpcErrorKey identifies identical rpc errors.
+type rpcErrorKey struct {
+	method  string
+	code    int64
+	message string
+}
+
+// rpcErrorLog records when an rpc error was last logged, and how many times it was repeated since.
+type rpcErrorLog struct {
+	loggedAt time.Time
+	repeated int
+}
+
+// collectorPool runs collectors concurrently, bounding how many of them (and so, how many rpc calls) run at once.
+type collectorPool struct {
+	wg    sync.WaitGroup
+	slots chan struct{}
+}
+
+// stakePoolSummary aggregates the stake accounts of a stake pool.
+type stakePoolSummary struct {
+	totalStake   int64
+	accountCount int
+}
+
 type SolanaCollector struct {
 	rpcClient *rpc.Client
 	apiClient *api.Client
-	logger    *zap.SugaredLogger
+	// referenceClient queries the -reference-rpc-url, or is nil if it is not set:
+	referenceClient *rpc.Client
+	logger          *zap.SugaredLogger
+	// collectLogLevel is the level at which the progress of each scrape is logged (see -log-collect-verbosity):
+	collectLogLevel zapcore.Level
 
 	config *ExporterConfig
 
 	/// descriptors:
-	ValidatorActiveStake         *GaugeDesc
-	ClusterActiveStake           *GaugeDesc
-	ValidatorLastVote            *GaugeDesc
-	ClusterLastVote              *GaugeDesc
-	ValidatorRootSlot            *GaugeDesc
-	ClusterRootSlot              *GaugeDesc
-	ValidatorDelinquent          *GaugeDesc
-	ClusterValidatorCount        *GaugeDesc
-	AccountBalances              *GaugeDesc
-	NodeVersion                  *GaugeDesc
-	NodeIsHealthy                *GaugeDesc
-	NodeNumSlotsBehind           *GaugeDesc
-	NodeMinimumLedgerSlot        *GaugeDesc
-	NodeFirstAvailableBlock      *GaugeDesc
-	NodeIdentity                 *GaugeDesc
-	NodeIsActive                 *GaugeDesc
-	FoundationMinRequiredVersion *GaugeDesc
-	NodeIsOutdated               *GaugeDesc
-	NodeNeedsUpdate              *GaugeDesc
-
-	isFiredancer bool
+	ValidatorActiveStake                *GaugeDesc
+	ClusterActiveStake                  *GaugeDesc
+	ValidatorLastVote                   *GaugeDesc
+	ValidatorLastVoteAge                *GaugeDesc
+	ClusterLastVote                     *GaugeDesc
+	ValidatorRootSlot                   *GaugeDesc
+	ValidatorRootSlotLag                *GaugeDesc
+	ValidatorVoteParticipation          *GaugeDesc
+	ClusterRootSlot                     *GaugeDesc
+	ValidatorDelinquent                 *GaugeDesc
+	ClusterValidatorCount               *GaugeDesc
+	ClusterDelinquentStake              *GaugeDesc
+	ClusterDelinquentStakePercent       *GaugeDesc
+	ValidatorIsSuperminority            *GaugeDesc
+	ValidatorCreditsRank                *GaugeDesc
+	ValidatorCommissionChanged          *GaugeDesc
+	ValidatorFound                      *GaugeDesc
+	ValidatorIdentityVotePair           *GaugeDesc
+	TrackedValidatorsDelinquent         *GaugeDesc
+	VoteAccountsProcessed               *GaugeDesc
+	AccountBalances                     *GaugeDesc
+	AccountBalanceChange                *GaugeDesc
+	NodeVersion                         *GaugeDesc
+	NodeVersionNumeric                  *GaugeDesc
+	NodeFeatureSet                      *GaugeDesc
+	NodeIsHealthy                       *GaugeDesc
+	NodeNumSlotsBehind                  *GaugeDesc
+	NodeMinimumLedgerSlot               *GaugeDesc
+	NodeFirstAvailableBlock             *GaugeDesc
+	NodeLedgerSpanSlots                 *GaugeDesc
+	NodeIdentity                        *GaugeDesc
+	NodeIsActive                        *GaugeDesc
+	NodeIdentityMatchesConfigured       *GaugeDesc
+	FoundationMinRequiredVersion        *GaugeDesc
+	FoundationMinRequiredVersionNumeric *GaugeDesc
+	FoundationVersionInherited          *GaugeDesc
+	FoundationAPIUp                     *GaugeDesc
+	FoundationAPICacheAge               *GaugeDesc
+	NodeIsOutdated                      *GaugeDesc
+	NodeNeedsUpdate                     *GaugeDesc
+	NodeAboveMaxVersion                 *GaugeDesc
+	NodeIsFiredancer                    *GaugeDesc
+	StakeAccountActive                  *GaugeDesc
+	StakeAccountActivating              *GaugeDesc
+	StakeAccountDeactivating            *GaugeDesc
+	NodeBlockTimeLag                    *GaugeDesc
+	NodeHighestFullSnapshotSlot         *GaugeDesc
+	NodeHighestIncrementalSnapshotSlot  *GaugeDesc
+	ClusterLargestAccountBalance        *GaugeDesc
+	TokenAccountBalance                 *GaugeDesc
+	StakePoolTotalStake                 *GaugeDesc
+	StakePoolAccountCount               *GaugeDesc
+	NodeNextLeaderSlot                  *GaugeDesc
+	NodeEpochSecondsRemaining           *GaugeDesc
+	NodeEpochElapsedSlots               *GaugeDesc
+	NodeEpochStartedTimestamp           *GaugeDesc
+	NodeTransactionsTotal               *GaugeDesc
+	ReferenceSlot                       *GaugeDesc
+	NodeSlotsBehindReference            *GaugeDesc
+	ClusterGossipPeers                  *GaugeDesc
+	NodeVisibleInGossip                 *GaugeDesc
+	NodeAdvertisedPort                  *GaugeDesc
+	NodeFeatureSetMatchesCluster        *GaugeDesc
+	NodeConfirmationLatency             *GaugeDesc
+	NodeRpcPortReachable                *GaugeDesc
+	NodeSlot                            *GaugeDesc
+	NodeSlotSourceDisagreement          *GaugeDesc
+	NodeSlotAdvancing                   *GaugeDesc
+	NodeRestartSuspectedTotal           *GaugeDesc
+	ClusterSkipRate                     *GaugeDesc
+	RentExemptMinimum                   *GaugeDesc
+	NodeCluster                         *GaugeDesc
+	ProgramUpgradeable                  *GaugeDesc
+	ProgramLastDeploySlot               *GaugeDesc
+	ClusterStakeMinimumDelegation       *GaugeDesc
+	ClusterPerformanceSampleTps         *GaugeDesc
+	CollectDuration                     *GaugeDesc
+	CollectorErrorsTotal                *GaugeDesc
+	ScrapeDuration                      *GaugeDesc
+	BuildInfo                           *GaugeDesc
+	CommitmentConfig                    *GaugeDesc
+
+	// collectorDescs maps each collector to the descriptors it emits:
+	collectorDescs map[string][]*GaugeDesc
+	// descCollectors maps each descriptor to the collector emitting it, the reverse of collectorDescs:
+	descCollectors map[*prometheus.Desc]string
+	// disabledDescs contains the descriptors of all the metrics disabled through the config:
+	disabledDescs map[*prometheus.Desc]struct{}
+
+	// previousBalances holds the balances of the previous scrape, from which the balance changes are computed:
+	previousBalances   map[string]float64
+	previousBalancesMu sync.Mutex
+
+	// isFiredancer caches whether the node was detected to be running Firedancer, as of firedancerDetectedAt:
+	isFiredancer         atomic.Bool
+	firedancerDetectedAt time.Time
+	firedancerMu         sync.Mutex
+
+	// largestAccounts caches the largest accounts on the cluster, as of largestAccountsFetchedAt:
+	largestAccounts          []rpc.LargestAccount
+	largestAccountsFetchedAt time.Time
+	largestAccountsMu        sync.Mutex
+
+	// tokenAccountMints caches the mint of each token account, which never changes:
+	tokenAccountMints   map[string]string
+	tokenAccountMintsMu sync.Mutex
+	// programDataAddresses caches the ProgramData account address of each upgradeable program, which never changes:
+	programDataAddresses   map[string]string
+	programDataAddressesMu sync.Mutex
+
+	// stakePool caches the summary of the monitored stake pool, as of stakePoolFetchedAt:
+	stakePool          stakePoolSummary
+	stakePoolFetchedAt time.Time
+	stakePoolMu        sync.Mutex
+
+	// voteParticipations tracks the vote participation of each validator (by votekey and nodekey) in the current epoch:
+	voteParticipations   map[[2]string]*voteParticipation
+	voteParticipationsMu sync.Mutex
+
+	// commissions holds the commission of each validator (by votekey and nodekey) as of the previous scrape:
+	commissions   map[[2]string]int
+	commissionsMu sync.Mutex
+
+	// epochSchedule caches the epoch schedule of the cluster, which never changes:
+	epochSchedule   *rpc.EpochSchedule
+	epochScheduleMu sync.Mutex
+
+	// epochStartedAt caches the estimated start time of the epoch starting at epochStartSlot:
+	epochStartedAt time.Time
+	epochStartSlot int64
+	epochStartMu   sync.Mutex
+
+	// transactionCount is the highest transaction count seen, which keeps the counter monotonic when the rpc returns
+	// a lower count (e.g., when it is load-balanced across nodes at different slots):
+	transactionCount   int64
+	transactionCountMu sync.Mutex
+
+	// nodeSlot is the confirmed slot of the node as of the previous scrape, and restartsSuspected counts the scrapes in
+	// which it went backwards:
+	nodeSlot          int64
+	restartsSuspected int64
+	nodeSlotMu        sync.Mutex
+
+	// clusterSlots caches the block production of the cluster in the current epoch, up to clusterSlotsWatermark, so that
+	// each scrape only fetches that of the new slots:
+	clusterSlots          clusterSlots
+	clusterSlotsWatermark int64
+	clusterSlotsMu        sync.Mutex
+
+	// probeKey signs the confirmation latency probes, or is nil if -probe-keypair is not set:
+	probeKey ed25519.PrivateKey
+	// confirmationProbe submits the confirmation latency probes in the background, once per -probe-interval:
+	confirmationProbe *probeCache[time.Duration]
+
+	// rpcPortProbeClient probes the rpc ports advertised in gossip. It is separate from the rpc client, as the probes
+	// are neither sent to the node's own rpc nor counted in the rpc metrics:
+	rpcPortProbeClient *http.Client
+	// rpcPortProbe probes the rpc ports in the background, once per -probe-interval:
+	rpcPortProbe *probeCache[[]rpcPortReachability]
+
+	// collectorErrors counts the collections in which each collector emitted invalid metrics:
+	collectorErrors   map[string]int
+	collectorErrorsMu sync.Mutex
+
+	// rpcErrorLogs rate-limits the logs of each rpc error, by method, code and message:
+	rpcErrorLogs   map[rpcErrorKey]*rpcErrorLog
+	rpcErrorLogsMu sync.Mutex
+
+	// lastCluster is the cluster of the node as of the last successful detection, which is used when the detection
+	// fails (e.g., on a transient rpc error), rather than the mainnet-beta default:
+	lastCluster   string
+	lastClusterMu sync.Mutex
+
+	// scrapeFailed records whether the ongoing scrape has hit a fatal rpc failure:
+	scrapeFailed atomic.Bool
+	// lastSuccessfulScrape is the unix-nano timestamp of the last scrape that completed without fatal rpc failures:
+	lastSuccessfulScrape atomic.Int64
 }
 
 func NewSolanaCollector(rpcClient *rpc.Client, config *ExporterConfig) *SolanaCollector {
 	collector := &SolanaCollector{
-		rpcClient: rpcClient,
-		apiClient: api.NewClient(rpcClient),
-		logger:    slog.Get(),
-		config:    config,
+		rpcClient:       rpcClient,
+		apiClient:       api.NewClient(rpcClient, config.RequiredVersionsAPIURL),
+		referenceClient: config.NewReferenceRPCClient(),
+		logger:          slog.Get(),
+		collectLogLevel: config.CollectLogLevel(),
+		config:          config,
+		// the probes are bounded by rpcPortProbeTimeout, as unreachable ports commonly drop rather than refuse:
+		rpcPortProbeClient: &http.Client{Timeout: rpcPortProbeTimeout},
 		ValidatorActiveStake: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_validator_active_stake",
 			fmt.Sprintf("Active stake (in SOL) per validator (represented by %s and %s)", VotekeyLabel, NodekeyLabel),
-			VotekeyLabel, NodekeyLabel,
+			VotekeyLabel, NodekeyLabel, NameLabel,
 		),
 		ClusterActiveStake: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_cluster_active_stake",
 			"Total active stake (in SOL) of the cluster",
 		),
 		ValidatorLastVote: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_validator_last_vote",
 			fmt.Sprintf("Last voted-on slot per validator (represented by %s and %s)", VotekeyLabel, NodekeyLabel),
-			VotekeyLabel, NodekeyLabel,
+			VotekeyLabel, NodekeyLabel, NameLabel,
+		),
+		ValidatorLastVoteAge: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_validator_last_vote_age_slots",
+			fmt.Sprintf(
+				"Slots elapsed since the last voted-on slot per validator (represented by %s and %s)",
+				VotekeyLabel, NodekeyLabel,
+			),
+			VotekeyLabel, NodekeyLabel, NameLabel,
 		),
 		ClusterLastVote: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_cluster_last_vote",
 			"Most recent voted-on slot of the cluster",
 		),
 		ValidatorRootSlot: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_validator_root_slot",
 			fmt.Sprintf("Root slot per validator (represented by %s and %s)", VotekeyLabel, NodekeyLabel),
-			VotekeyLabel, NodekeyLabel,
+			VotekeyLabel, NodekeyLabel, NameLabel,
+		),
+		ValidatorRootSlotLag: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_validator_root_slot_lag",
+			fmt.Sprintf(
+				"Slots elapsed since the root slot per validator (represented by %s and %s)",
+				VotekeyLabel, NodekeyLabel,
+			),
+			VotekeyLabel, NodekeyLabel, NameLabel,
+		),
+		ValidatorVoteParticipation: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_validator_vote_participation",
+			fmt.Sprintf(
+				"Fraction of the slots elapsed across the scrapes of the current epoch which were voted on, per "+
+					"validator (represented by %s and %s)",
+				VotekeyLabel, NodekeyLabel,
+			),
+			VotekeyLabel, NodekeyLabel, NameLabel,
 		),
 		ClusterRootSlot: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_cluster_root_slot",
 			"Max root slot of the cluster",
 		),
 		ValidatorDelinquent: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_validator_delinquent",
 			fmt.Sprintf("Whether a validator (represented by %s and %s) is delinquent", VotekeyLabel, NodekeyLabel),
-			VotekeyLabel, NodekeyLabel,
+			VotekeyLabel, NodekeyLabel, NameLabel,
 		),
 		ClusterValidatorCount: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_cluster_validator_count",
 			fmt.Sprintf(
 				"Total number of validators in the cluster, grouped by %s ('%s' or '%s')",
@@ -114,240 +525,1957 @@ func NewSolanaCollector(rpcClient *rpc.Client, config *ExporterConfig) *SolanaCo
 			),
 			StateLabel,
 		),
+		ClusterDelinquentStake: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_cluster_delinquent_stake",
+			"Total active stake (in SOL) of the delinquent validators in the cluster",
+		),
+		ClusterDelinquentStakePercent: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_cluster_delinquent_stake_percent",
+			"Percentage of the cluster's active stake held by delinquent validators",
+		),
+		ValidatorIsSuperminority: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_validator_is_superminority",
+			fmt.Sprintf(
+				"Whether a validator (represented by %s and %s) is in the superminority, i.e., the highest staked "+
+					"validators which together hold more than a third of the cluster's active stake",
+				VotekeyLabel, NodekeyLabel,
+			),
+			VotekeyLabel, NodekeyLabel, NameLabel,
+		),
+		ValidatorCreditsRank: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_validator_credits_rank",
+			fmt.Sprintf(
+				"Rank of a validator (represented by %s and %s) among all vote accounts by the vote credits earned "+
+					"in the latest epoch, starting at 1 for the most credits",
+				VotekeyLabel, NodekeyLabel,
+			),
+			VotekeyLabel, NodekeyLabel, NameLabel,
+		),
+		ValidatorCommissionChanged: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_validator_commission_changed",
+			fmt.Sprintf(
+				"Whether the commission of a validator (represented by %s and %s) changed since the previous scrape",
+				VotekeyLabel, NodekeyLabel,
+			),
+			VotekeyLabel, NodekeyLabel, NameLabel,
+		),
+		ValidatorFound: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_validator_found",
+			fmt.Sprintf("Whether a tracked validator (represented by %s) was found in the vote accounts", NodekeyLabel),
+			NodekeyLabel, NameLabel,
+		),
+		ValidatorIdentityVotePair: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_validator_identity_vote_pair",
+			fmt.Sprintf(
+				"Always 1, to associate the %s of a tracked validator with the %s of its vote account",
+				NodekeyLabel, VotekeyLabel,
+			),
+			NodekeyLabel, VotekeyLabel,
+		),
+		TrackedValidatorsDelinquent: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_tracked_validators_delinquent",
+			fmt.Sprintf("Number of the tracked validators (represented by %s) which are delinquent", NodekeyLabel),
+		),
+		VoteAccountsProcessed: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_exporter_vote_accounts_processed",
+			"Number of (current and delinquent) vote accounts processed in the last scrape",
+		),
+		NodeEpochSecondsRemaining: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_epoch_seconds_remaining",
+			fmt.Sprintf(
+				"Estimated time (in seconds) until the end of the current epoch, at the average slot time of the "+
+					"last %d minutes",
+				performanceSampleCount,
+			),
+		),
+		NodeEpochElapsedSlots: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_epoch_elapsed_slots",
+			"Number of slots elapsed since the start of the current epoch (i.e., the slot index)",
+		),
+		NodeEpochStartedTimestamp: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_epoch_started_timestamp",
+			"Estimated start time (as a unix timestamp) of the current epoch, from the block time of its first slot",
+		),
+		NodeTransactionsTotal: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_transactions_total",
+			"Total number of transactions in the ledger since genesis, including failed ones.",
+		),
+		ReferenceSlot: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_reference_slot",
+			"The current slot of the reference rpc node (see -reference-rpc-url)",
+		),
+		NodeSlotsBehindReference: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_slots_behind_reference",
+			"Number of slots the node is behind the reference rpc node (negative if it is ahead)",
+		),
+		ClusterGossipPeers: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_cluster_gossip_peers",
+			"Number of nodes in the gossip table of the node",
+		),
+		NodeVisibleInGossip: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_visible_in_gossip",
+			fmt.Sprintf("Whether the node (represented by its %s) appears in its own gossip table", IdentityLabel),
+			IdentityLabel,
+		),
+		NodeAdvertisedPort: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_advertised_port",
+			fmt.Sprintf(
+				"Whether the node advertises a %s (%s, %s or %s) in gossip",
+				PortTypeLabel, PortTypeTpu, PortTypeTpuQuic, PortTypeRpc,
+			),
+			PortTypeLabel,
+		),
+		NodeFeatureSetMatchesCluster: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_feature_set_matches_cluster",
+			"Whether the feature set of the node matches the -expected-feature-set or, if it is not set, the most "+
+				"common feature set in the gossip table of the node",
+		),
+		NodeConfirmationLatency: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_confirmation_latency_seconds",
+			"Time it took for the latest probe transaction submitted through the node to be confirmed",
+		),
+		NodeRpcPortReachable: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_rpc_port_reachable",
+			fmt.Sprintf(
+				"Whether the rpc port advertised in gossip by a node (represented by its %s) answers a "+
+					"getHealth request",
+				NodekeyLabel,
+			),
+			NodekeyLabel,
+		),
+		NodeSlot: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_slot",
+			fmt.Sprintf("The current slot of the node, at each %s level", CommitmentLabel),
+			CommitmentLabel,
+		),
+		NodeSlotSourceDisagreement: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_slot_source_disagreement",
+			"Absolute difference between the (confirmed) slots reported by getEpochInfo and getSlot, which should "+
+				"only disagree if the node is behind an inconsistent load balancer",
+		),
+		NodeSlotAdvancing: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_slot_advancing",
+			"Whether the (confirmed) slot of the node has increased since the previous scrape",
+		),
+		NodeRestartSuspectedTotal: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_restart_suspected_total",
+			"Number of scrapes in which the (confirmed) slot of the node went backwards, which suggests that the node "+
+				"restarted from an older snapshot, or that its ledger was reset",
+		),
+		ClusterSkipRate: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_cluster_skip_rate",
+			"Fraction of the leader slots of the current epoch (so far) skipped across all validators",
+		),
+		RentExemptMinimum: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_rent_exempt_minimum_lamports",
+			fmt.Sprintf(
+				"Minimum balance (in lamports) for an account to be rent exempt, grouped by %s (in bytes)",
+				DataSizeLabel,
+			),
+			DataSizeLabel,
+		),
+		NodeCluster: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_cluster",
+			fmt.Sprintf(
+				"The cluster of the node (or '%s'), as detected from its %s, always set to 1",
+				ClusterUnknown, GenesisHashLabel,
+			),
+			ClusterLabel, GenesisHashLabel,
+		),
+		ProgramUpgradeable: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_program_upgradeable",
+			fmt.Sprintf("Whether the %s (see -programs) has an upgrade authority (1) or is immutable (0)", ProgramLabel),
+			ProgramLabel,
+		),
+		ProgramLastDeploySlot: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_program_last_deploy_slot",
+			fmt.Sprintf("The slot in which the upgradeable %s (see -programs) was last deployed", ProgramLabel),
+			ProgramLabel,
+		),
+		ClusterStakeMinimumDelegation: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_cluster_stake_minimum_delegation",
+			"Minimum delegation (in SOL) of a stake account",
+		),
+		ClusterPerformanceSampleTps: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_cluster_performance_sample_tps",
+			fmt.Sprintf(
+				"Transactions per second in each of the most recent (minutely) performance samples, the newest one "+
+					"at %s 0",
+				SampleIndexLabel,
+			),
+			SampleIndexLabel,
+		),
 		AccountBalances: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_account_balance",
 			fmt.Sprintf("Solana account balances, grouped by %s", AddressLabel),
-			AddressLabel,
+			AddressLabel, NameLabel,
+		),
+		AccountBalanceChange: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_account_balance_change",
+			fmt.Sprintf("Change in Solana account balances since the previous scrape, grouped by %s", AddressLabel),
+			AddressLabel, NameLabel,
 		),
 		NodeVersion: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_node_version",
 			"Node version of solana",
 			VersionLabel, IsFiredancerLabel,
 		),
+		NodeVersionNumeric: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_version_numeric",
+			fmt.Sprintf(
+				"Node version of solana, encoded as major*1e10 + minor*1e5 + patch, grouped by %s ('%s' or '%s')",
+				ClientLabel, ClientAgave, ClientFiredancer,
+			),
+			ClientLabel,
+		),
+		NodeFeatureSet: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_feature_set",
+			"Feature set of the node, which distinguishes otherwise identical versions across forks",
+		),
 		NodeIdentity: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_node_identity",
 			"Node identity of solana",
 			IdentityLabel,
 		),
 		NodeIsHealthy: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_node_is_healthy",
 			"Whether the node is healthy",
 		),
 		NodeNumSlotsBehind: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_node_num_slots_behind",
 			"The number of slots that the node is behind the latest cluster confirmed slot.",
 		),
 		NodeMinimumLedgerSlot: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_node_minimum_ledger_slot",
 			"The lowest slot that the node has information about in its ledger.",
 		),
 		NodeFirstAvailableBlock: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_node_first_available_block",
 			"The slot of the lowest confirmed block that has not been purged from the node's ledger.",
 		),
+		NodeLedgerSpanSlots: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_ledger_span_slots",
+			"The number of slots between the first available block and the current slot of the node, i.e., the depth "+
+				"of the history it retains.",
+		),
 		NodeIsActive: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_node_is_active",
 			fmt.Sprintf("Whether the node is active and participating in consensus (using %s pubkey)", IdentityLabel),
 			IdentityLabel,
 		),
+		NodeIdentityMatchesConfigured: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_identity_matches_configured",
+			fmt.Sprintf(
+				"Whether the identity of the node (represented by %s) is one of the configured nodekeys", IdentityLabel,
+			),
+			IdentityLabel,
+		),
 		FoundationMinRequiredVersion: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_foundation_min_required_version",
 			"Minimum required Solana version for the solana foundation delegation program",
 			"agave_min_version", "firedancer_min_version", ClusterLabel, EpochLabel,
 		),
+		FoundationMinRequiredVersionNumeric: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_foundation_min_required_version_numeric",
+			fmt.Sprintf(
+				"Minimum required Solana version for the solana foundation delegation program, encoded as "+
+					"major*1e10 + minor*1e5 + patch, grouped by %s ('%s' or '%s')",
+				ClientLabel, ClientAgave, ClientFiredancer,
+			),
+			ClientLabel,
+		),
+		FoundationVersionInherited: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_foundation_version_inherited",
+			"Whether the current epoch's required versions were inherited from the previous epoch, rather than freshly set",
+			ClusterLabel, EpochLabel,
+		),
+		FoundationAPIUp: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_foundation_api_up",
+			"Whether the last request to the foundation required versions API succeeded",
+		),
+		FoundationAPICacheAge: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_foundation_api_cache_age_seconds",
+			"Time (in seconds) since the required versions were last successfully fetched from the foundation API",
+		),
 		NodeIsOutdated: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_node_is_outdated",
 			"Whether the node is running a version below the required minimum for Firedancer",
 			IsFiredancerLabel, VersionLabel, "required_version", ClusterLabel, EpochLabel,
 		),
 		NodeNeedsUpdate: NewGaugeDesc(
+			config.MetricPrefix,
 			"solana_node_needs_update",
 			"Whether the node needs to be updated before the next epoch to remain compliant",
 			IsFiredancerLabel, VersionLabel, "required_version", ClusterLabel, EpochLabel,
 		),
+		NodeAboveMaxVersion: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_above_max_version",
+			"Whether the node is running a version above the allowed maximum for its client (if there is one)",
+			IsFiredancerLabel, VersionLabel, "max_version", ClusterLabel,
+		),
+		NodeIsFiredancer: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_is_firedancer",
+			"Whether the node was detected to be running Firedancer",
+		),
+		StakeAccountActive: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_stake_account_active",
+			fmt.Sprintf(
+				"Active stake (in SOL) per stake account (represented by %s), with its activation %s",
+				AddressLabel, StateLabel,
+			),
+			AddressLabel, StateLabel,
+		),
+		StakeAccountActivating: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_stake_account_activating",
+			fmt.Sprintf(
+				"Activating stake (in SOL) per stake account (represented by %s), with its activation %s",
+				AddressLabel, StateLabel,
+			),
+			AddressLabel, StateLabel,
+		),
+		StakeAccountDeactivating: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_stake_account_deactivating",
+			fmt.Sprintf(
+				"Deactivating stake (in SOL) per stake account (represented by %s), with its activation %s",
+				AddressLabel, StateLabel,
+			),
+			AddressLabel, StateLabel,
+		),
+		NodeBlockTimeLag: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_block_time_lag_seconds",
+			"Time (in seconds) elapsed since the production of the latest confirmed block on the node",
+		),
+		NodeHighestFullSnapshotSlot: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_highest_full_snapshot_slot",
+			"The highest slot of the full snapshots of the node (0 if it has none)",
+		),
+		NodeHighestIncrementalSnapshotSlot: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_highest_incremental_snapshot_slot",
+			"The highest slot of the incremental snapshots of the node (0 if it has none)",
+		),
+		ClusterLargestAccountBalance: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_cluster_largest_account_balance",
+			fmt.Sprintf("Balances (in SOL) of the largest accounts on the cluster, grouped by %s", AddressLabel),
+			AddressLabel,
+		),
+		TokenAccountBalance: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_token_account_balance",
+			fmt.Sprintf(
+				"Token balance (with decimals applied) per SPL token account (represented by %s), with its %s",
+				AddressLabel, MintLabel,
+			),
+			AddressLabel, MintLabel,
+		),
+		StakePoolTotalStake: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_stake_pool_total_stake",
+			fmt.Sprintf(
+				"Total delegated stake (in SOL) of the stake accounts withdrawable by %s",
+				WithdrawAuthorityLabel,
+			),
+			WithdrawAuthorityLabel,
+		),
+		StakePoolAccountCount: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_stake_pool_account_count",
+			fmt.Sprintf("Number of stake accounts withdrawable by %s", WithdrawAuthorityLabel),
+			WithdrawAuthorityLabel,
+		),
+		NodeNextLeaderSlot: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_node_next_leader_slot",
+			fmt.Sprintf(
+				"Number of slots until the next leader slot of a validator (represented by %s), or %d if it does "+
+					"not lead within the next %d slots",
+				NodekeyLabel, nextLeaderSlotWindow, nextLeaderSlotWindow,
+			),
+			NodekeyLabel, NameLabel,
+		),
+		CollectDuration: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_exporter_collect_duration_seconds",
+			fmt.Sprintf("Time taken by each collector (represented by %s) during the last scrape", CollectorLabel),
+			CollectorLabel,
+		),
+		CollectorErrorsTotal: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_exporter_collector_errors_total",
+			fmt.Sprintf(
+				"Number of collections in which each collector (represented by %s) emitted invalid metrics",
+				CollectorLabel,
+			),
+			CollectorLabel,
+		),
+		ScrapeDuration: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_exporter_scrape_duration_seconds",
+			"Time taken by the last scrape",
+		),
+		BuildInfo: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_exporter_build_info",
+			fmt.Sprintf(
+				"Build information of the exporter (%s, %s and %s), always set to 1",
+				VersionLabel, CommitLabel, GoVersionLabel,
+			),
+			VersionLabel, CommitLabel, GoVersionLabel,
+		),
+		CommitmentConfig: NewGaugeDesc(
+			config.MetricPrefix,
+			"solana_exporter_commitment_config",
+			fmt.Sprintf(
+				"Commitment levels at which the %s, %s, %s and %s metrics are read, always set to 1",
+				VoteAccountsLabel, SlotLabel, SlotWatcherLabel, BalancesLabel,
+			),
+			VoteAccountsLabel, SlotLabel, SlotWatcherLabel, BalancesLabel,
+		),
+	}
+	collector.collectorDescs = map[string][]*GaugeDesc{
+		CollectorHealth:              {collector.NodeIsHealthy, collector.NodeNumSlotsBehind},
+		CollectorMinimumLedgerSlot:   {collector.NodeMinimumLedgerSlot},
+		CollectorFirstAvailableBlock: {collector.NodeFirstAvailableBlock, collector.NodeLedgerSpanSlots},
+		CollectorVoteAccounts: {
+			collector.ValidatorActiveStake,
+			collector.ClusterActiveStake,
+			collector.ValidatorLastVote,
+			collector.ValidatorLastVoteAge,
+			collector.ClusterLastVote,
+			collector.ValidatorRootSlot,
+			collector.ValidatorRootSlotLag,
+			collector.ValidatorVoteParticipation,
+			collector.ClusterRootSlot,
+			collector.ValidatorDelinquent,
+			collector.ClusterValidatorCount,
+			collector.ClusterDelinquentStake,
+			collector.ClusterDelinquentStakePercent,
+			collector.ValidatorIsSuperminority,
+			collector.ValidatorCreditsRank,
+			collector.ValidatorCommissionChanged,
+			collector.ValidatorFound,
+			collector.ValidatorIdentityVotePair,
+			collector.TrackedValidatorsDelinquent,
+			collector.VoteAccountsProcessed,
+		},
+		CollectorVersion: {collector.NodeVersion, collector.NodeVersionNumeric, collector.NodeFeatureSet},
+		CollectorIdentity: {
+			collector.NodeIdentity, collector.NodeIsActive, collector.NodeIdentityMatchesConfigured,
+		},
+		CollectorBalances: {collector.AccountBalances, collector.AccountBalanceChange},
+		CollectorMinRequiredVersion: {
+			collector.FoundationMinRequiredVersion,
+			collector.FoundationMinRequiredVersionNumeric,
+			collector.FoundationVersionInherited,
+			collector.FoundationAPIUp,
+			collector.FoundationAPICacheAge,
+		},
+		CollectorNodeIsOutdated:      {collector.NodeIsOutdated},
+		CollectorNodeNeedsUpdate:     {collector.NodeNeedsUpdate},
+		CollectorNodeAboveMaxVersion: {collector.NodeAboveMaxVersion},
+		CollectorFiredancer:          {collector.NodeIsFiredancer},
+		CollectorStakeAccounts: {
+			collector.StakeAccountActive, collector.StakeAccountActivating, collector.StakeAccountDeactivating,
+		},
+		CollectorBlockTimeLag: {collector.NodeBlockTimeLag},
+		CollectorSnapshotSlots: {
+			collector.NodeHighestFullSnapshotSlot, collector.NodeHighestIncrementalSnapshotSlot,
+		},
+		CollectorLargestAccounts: {collector.ClusterLargestAccountBalance},
+		CollectorTokenAccounts:   {collector.TokenAccountBalance},
+		CollectorStakePool:       {collector.StakePoolTotalStake, collector.StakePoolAccountCount},
+		CollectorNextLeaderSlot:  {collector.NodeNextLeaderSlot},
+		CollectorEpochCountdown: {
+			collector.NodeEpochSecondsRemaining,
+			collector.NodeEpochElapsedSlots,
+			collector.NodeEpochStartedTimestamp,
+		},
+		CollectorTransactionCount: {collector.NodeTransactionsTotal},
+		CollectorReference:        {collector.ReferenceSlot, collector.NodeSlotsBehindReference},
+		CollectorGossip: {
+			collector.ClusterGossipPeers,
+			collector.NodeVisibleInGossip,
+			collector.NodeAdvertisedPort,
+			collector.NodeFeatureSetMatchesCluster,
+		},
+		CollectorConfirmationProbe: {collector.NodeConfirmationLatency},
+		CollectorRpcPortProbe:      {collector.NodeRpcPortReachable},
+		CollectorCommitmentSlots: {
+			collector.NodeSlot,
+			collector.NodeSlotSourceDisagreement,
+			collector.NodeSlotAdvancing,
+			collector.NodeRestartSuspectedTotal,
+		},
+		CollectorClusterSkipRate:    {collector.ClusterSkipRate},
+		CollectorRentExempt:         {collector.RentExemptMinimum},
+		CollectorCluster:            {collector.NodeCluster},
+		CollectorPrograms:           {collector.ProgramUpgradeable, collector.ProgramLastDeploySlot},
+		CollectorStakeMinimum:       {collector.ClusterStakeMinimumDelegation},
+		CollectorPerformanceSamples: {collector.ClusterPerformanceSampleTps},
+	}
+	probeKey, err := config.LoadProbeKeypair()
+	if err != nil {
+		collector.logger.Errorf("Failed to load probe keypair, not probing confirmation latency: %v", err)
+	}
+	collector.probeKey = probeKey
+	collector.rpcPortProbe = newProbeCache(config.ProbeInterval, collector.probeRpcPorts)
+	collector.confirmationProbe = newProbeCache(config.ProbeInterval, func(ctx context.Context) (time.Duration, error) {
+		return ProbeConfirmationLatency(ctx, rpcClient, probeKey)
+	})
+	collector.descCollectors = make(map[*prometheus.Desc]string)
+	for name, descs := range collector.collectorDescs {
+		for _, desc := range descs {
+			collector.descCollectors[desc.Desc] = name
+		}
+	}
+	collector.collectorErrors = make(map[string]int)
+	collector.disabledDescs = make(map[*prometheus.Desc]struct{})
+	var metricNames []string
+	for _, desc := range collector.descs() {
+		metricNames = append(metricNames, desc.Name)
+		if !config.MetricEnabled(desc.Name) {
+			collector.logger.Infof("Metric %s is disabled.", desc.Name)
+			collector.disabledDescs[desc.Desc] = struct{}{}
+		}
+	}
+	for _, name := range Collectors {
+		if !config.CollectorEnabled(name) {
+			collector.logger.Infof("Collector %s is disabled.", name)
+			for _, desc := range collector.collectorDescs[name] {
+				collector.disabledDescs[desc.Desc] = struct{}{}
+			}
+		}
+	}
+	for _, name := range append(config.DisabledMetrics, config.EnabledMetrics...) {
+		if !slices.Contains(metricNames, name) {
+			collector.logger.Warnf("Unknown metric %s configured, ignoring.", name)
+		}
+	}
+	for _, name := range Collectors {
+		if config.CollectorEnabled(name) && !collector.collectorEnabled(name) {
+			collector.logger.Infof("Skipping %s collector, as all its metrics are disabled.", name)
+		}
+	}
+	// consider the exporter healthy until the first scrape is overdue:
+	collector.lastSuccessfulScrape.Store(time.Now().UnixNano())
+	return collector
+}
+
+// LastSuccessfulScrape returns the time at which the last scrape without fatal rpc failures completed. Before any
+// scrape has completed, this is the time at which the collector was created.
+func (c *SolanaCollector) LastSuccessfulScrape() time.Time {
+	return time.Unix(0, c.lastSuccessfulScrape.Load())
+}
+
+// recordRPCError marks the ongoing scrape as failed if err means that the rpc node could not be reached or did not
+// respond properly. Errors returned by the node itself (e.g., because it is unhealthy) are not considered fatal, and
+// are logged with structured fields instead, for log pipelines to aggregate them by method and code. Identical errors
+// are logged at most once per rpcErrorLogInterval, along with how many times they were repeated in between.
+func (c *SolanaCollector) recordRPCError(err error) {
+	var rpcError *rpc.Error
+	if !errors.As(err, &rpcError) {
+		c.scrapeFailed.Store(true)
+		return
+	}
+
+	c.rpcErrorLogsMu.Lock()
+	defer c.rpcErrorLogsMu.Unlock()
+	if c.rpcErrorLogs == nil {
+		c.rpcErrorLogs = make(map[rpcErrorKey]*rpcErrorLog)
+	}
+	key := rpcErrorKey{method: rpcError.Method, code: rpcError.Code, message: rpcError.Message}
+	entry, ok := c.rpcErrorLogs[key]
+	if ok && time.Since(entry.loggedAt) < rpcErrorLogInterval {
+		entry.repeated++
+		return
+	}
+	if !ok {
+		entry = &rpcErrorLog{}
+		c.rpcErrorLogs[key] = entry
+	}
+	c.logger.Warnw(
+		"rpc error",
+		"method", rpcError.Method, "code", rpcError.Code, "message", rpcError.Message, "repeated", entry.repeated,
+	)
+	entry.loggedAt, entry.repeated = time.Now(), 0
+}
+
+// descs returns all the descriptors of the collector.
+func (c *SolanaCollector) descs() []*GaugeDesc {
+	var descs []*GaugeDesc
+	for _, name := range Collectors {
+		descs = append(descs, c.collectorDescs[name]...)
+	}
+	return append(descs, c.CollectDuration, c.CollectorErrorsTotal, c.ScrapeDuration, c.BuildInfo, c.CommitmentConfig)
+}
+
+// collectorEnabled returns whether the named collector has any enabled metrics to collect.
+func (c *SolanaCollector) collectorEnabled(name string) bool {
+	for _, desc := range c.collectorDescs[name] {
+		if _, ok := c.disabledDescs[desc.Desc]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// descsEnabled returns whether any of the provided descs is enabled.
+func (c *SolanaCollector) descsEnabled(descs ...*GaugeDesc) bool {
+	return slices.ContainsFunc(descs, func(desc *GaugeDesc) bool {
+		_, disabled := c.disabledDescs[desc.Desc]
+		return !disabled
+	})
+}
+
+// anyCollectorEnabled returns whether any of the named collectors is enabled.
+func (c *SolanaCollector) anyCollectorEnabled(names ...string) bool {
+	return slices.ContainsFunc(names, c.collectorEnabled)
+}
+
+// timeCollector runs the named collector through collect (if it is enabled), and reports how long it took.
+func (c *SolanaCollector) timeCollector(name string, ch chan<- prometheus.Metric, collect func()) {
+	if !c.collectorEnabled(name) {
+		return
+	}
+	start := time.Now()
+	collect()
+	ch <- c.CollectDuration.MustNewConstMetric(time.Since(start).Seconds(), name)
+}
+
+func newCollectorPool(size int) *collectorPool {
+	return &collectorPool{slots: make(chan struct{}, max(size, 1))}
+}
+
+// Go runs collect in the pool once all the after channels are closed.
+func (p *collectorPool) Go(collect func(), after ...<-chan struct{}) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for _, ch := range after {
+			<-ch
+		}
+		p.slots <- struct{}{}
+		defer func() { <-p.slots }()
+		collect()
+	}()
+}
+
+// Wait blocks until all the collectors in the pool have finished.
+func (p *collectorPool) Wait() {
+	p.wg.Wait()
+}
+
+// filterDisabledMetrics returns a channel which forwards all metrics to ch, except for those that have been
+// disabled through the config. The returned function closes the channel and waits for forwarding to finish.
+func (c *SolanaCollector) filterDisabledMetrics(ch chan<- prometheus.Metric) (chan<- prometheus.Metric, func()) {
+	if len(c.disabledDescs) == 0 {
+		return ch, func() {}
+	}
+	filtered := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for metric := range filtered {
+			if _, ok := c.disabledDescs[metric.Desc()]; !ok {
+				ch <- metric
+			}
+		}
+	}()
+	return filtered, func() {
+		close(filtered)
+		<-done
+	}
+}
+
+// findFailedCollectors returns a channel forwarding metrics to ch, along with a function which closes it and returns
+// the collectors that emitted invalid metrics through it.
+func (c *SolanaCollector) findFailedCollectors(
+	ch chan<- prometheus.Metric,
+) (chan<- prometheus.Metric, func() []string) {
+	forwarded := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	var failed []string
+	go func() {
+		defer close(done)
+		for metric := range forwarded {
+			if _, ok := metric.(invalidMetric); ok {
+				if name, ok := c.descCollectors[metric.Desc()]; ok && !slices.Contains(failed, name) {
+					failed = append(failed, name)
+				}
+			}
+			ch <- metric
+		}
+	}()
+	return forwarded, func() []string {
+		close(forwarded)
+		<-done
+		return failed
+	}
+}
+
+// emitCollectorErrors counts a collection error for each of the failed collectors, and emits the error counts of all
+// the enabled collectors.
+func (c *SolanaCollector) emitCollectorErrors(ch chan<- prometheus.Metric, failed []string) {
+	c.collectorErrorsMu.Lock()
+	defer c.collectorErrorsMu.Unlock()
+	for _, name := range failed {
+		c.collectorErrors[name]++
+	}
+	for _, name := range Collectors {
+		if c.collectorEnabled(name) {
+			ch <- c.CollectorErrorsTotal.MustNewConstCounter(float64(c.collectorErrors[name]), name)
+		}
+	}
+}
+
+func (c *SolanaCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range c.descs() {
+		if _, ok := c.disabledDescs[desc.Desc]; !ok {
+			ch <- desc.Desc
+		}
+	}
+}
+
+func (c *SolanaCollector) collectVoteAccounts(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorVoteAccounts) {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting vote accounts...")
+	voteAccounts, err := c.fetchVoteAccounts(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to get vote accounts: %v", err)
+		c.recordRPCError(err)
+		ch <- c.ValidatorActiveStake.NewInvalidMetric(err)
+		ch <- c.ClusterActiveStake.NewInvalidMetric(err)
+		ch <- c.ValidatorLastVote.NewInvalidMetric(err)
+		ch <- c.ValidatorLastVoteAge.NewInvalidMetric(err)
+		ch <- c.ClusterLastVote.NewInvalidMetric(err)
+		ch <- c.ValidatorRootSlot.NewInvalidMetric(err)
+		ch <- c.ValidatorRootSlotLag.NewInvalidMetric(err)
+		ch <- c.ValidatorVoteParticipation.NewInvalidMetric(err)
+		ch <- c.ClusterRootSlot.NewInvalidMetric(err)
+		ch <- c.ValidatorDelinquent.NewInvalidMetric(err)
+		ch <- c.ClusterValidatorCount.NewInvalidMetric(err)
+		ch <- c.ClusterDelinquentStake.NewInvalidMetric(err)
+		ch <- c.ClusterDelinquentStakePercent.NewInvalidMetric(err)
+		ch <- c.ValidatorIsSuperminority.NewInvalidMetric(err)
+		ch <- c.ValidatorCreditsRank.NewInvalidMetric(err)
+		ch <- c.ValidatorCommissionChanged.NewInvalidMetric(err)
+		ch <- c.ValidatorFound.NewInvalidMetric(err)
+		ch <- c.ValidatorIdentityVotePair.NewInvalidMetric(err)
+		ch <- c.TrackedValidatorsDelinquent.NewInvalidMetric(err)
+		ch <- c.VoteAccountsProcessed.NewInvalidMetric(err)
+		return
+	}
+
+	// the age of the last votes, the lag of the root slots and the vote participation are relative to the current slot:
+	var currentSlot int64
+	if c.descsEnabled(c.ValidatorLastVoteAge, c.ValidatorRootSlotLag, c.ValidatorVoteParticipation) {
+		currentSlot, err = c.rpcClient.GetSlot(ctx, c.voteAccountsCommitment())
+		if err != nil {
+			c.logger.Errorf("failed to get current slot: %v", err)
+			c.recordRPCError(err)
+			ch <- c.ValidatorLastVoteAge.NewInvalidMetric(err)
+			ch <- c.ValidatorRootSlotLag.NewInvalidMetric(err)
+			ch <- c.ValidatorVoteParticipation.NewInvalidMetric(err)
+		}
+	}
+	// the vote participation is reset at the start of each epoch:
+	currentEpoch := int64(-1)
+	if currentSlot > 0 && c.descsEnabled(c.ValidatorVoteParticipation) {
+		schedule, err := c.getEpochSchedule(ctx)
+		if err != nil {
+			c.logger.Errorf("failed to get epoch schedule: %v", err)
+			c.recordRPCError(err)
+			ch <- c.ValidatorVoteParticipation.NewInvalidMetric(err)
+		} else {
+			currentEpoch = schedule.Epoch(currentSlot)
+		}
+	}
+
+	superminority := GetSuperminority(append(voteAccounts.Current, voteAccounts.Delinquent...))
+	creditsRanks := GetCreditsRanks(append(voteAccounts.Current, voteAccounts.Delinquent...))
+	var (
+		totalStake      float64
+		delinquentStake float64
+		maxLastVote     float64
+		maxRootSlot     float64
+	)
+	for _, account := range append(voteAccounts.Current, voteAccounts.Delinquent...) {
+		accounts := []string{account.VotePubkey, account.NodePubkey, c.config.IdentityName(account.NodePubkey)}
+		stake, lastVote, rootSlot :=
+			float64(account.ActivatedStake)/rpc.LamportsInSol,
+			float64(account.LastVote),
+			float64(account.RootSlot)
+
+		if slices.Contains(c.config.NodeKeys, account.NodePubkey) || c.config.ComprehensiveVoteAccountTracking {
+			ch <- c.ValidatorActiveStake.MustNewConstMetric(stake, accounts...)
+			ch <- c.ValidatorLastVote.MustNewConstMetric(lastVote, accounts...)
+			if currentSlot > 0 {
+				ch <- c.ValidatorLastVoteAge.MustNewConstMetric(max(0, float64(currentSlot)-lastVote), accounts...)
+			}
+			ch <- c.ValidatorRootSlot.MustNewConstMetric(rootSlot, accounts...)
+			if currentSlot > 0 {
+				ch <- c.ValidatorRootSlotLag.MustNewConstMetric(max(0, float64(currentSlot)-rootSlot), accounts...)
+			}
+			if currentEpoch >= 0 {
+				participation, ok := c.updateVoteParticipation(
+					account.VotePubkey, account.NodePubkey, currentEpoch, currentSlot, int64(account.LastVote),
+				)
+				if ok {
+					ch <- c.ValidatorVoteParticipation.MustNewConstMetric(participation, accounts...)
+				}
+			}
+			_, isSuperminority := superminority[account.VotePubkey]
+			ch <- c.ValidatorIsSuperminority.MustNewConstMetric(BoolToFloat64(isSuperminority), accounts...)
+			ch <- c.ValidatorCreditsRank.MustNewConstMetric(float64(creditsRanks[account.VotePubkey]), accounts...)
+			changed := c.updateCommission(account.VotePubkey, account.NodePubkey, account.Commission)
+			ch <- c.ValidatorCommissionChanged.MustNewConstMetric(BoolToFloat64(changed), accounts...)
+			// a change of the vote account of a validator shows up as a new series:
+			ch <- c.ValidatorIdentityVotePair.MustNewConstMetric(1, account.NodePubkey, account.VotePubkey)
+		}
+
+		totalStake += stake
+		maxLastVote = max(maxLastVote, lastVote)
+		maxRootSlot = max(maxRootSlot, rootSlot)
+	}
+
+	{
+		var trackedDelinquent int
+		for _, account := range voteAccounts.Current {
+			if slices.Contains(c.config.NodeKeys, account.NodePubkey) || c.config.ComprehensiveVoteAccountTracking {
+				ch <- c.ValidatorDelinquent.MustNewConstMetric(
+					0, account.VotePubkey, account.NodePubkey, c.config.IdentityName(account.NodePubkey),
+				)
+			}
+		}
+		for _, account := range voteAccounts.Delinquent {
+			delinquentStake += float64(account.ActivatedStake) / rpc.LamportsInSol
+			if slices.Contains(c.config.NodeKeys, account.NodePubkey) {
+				trackedDelinquent++
+			}
+			if slices.Contains(c.config.NodeKeys, account.NodePubkey) || c.config.ComprehensiveVoteAccountTracking {
+				ch <- c.ValidatorDelinquent.MustNewConstMetric(
+					1, account.VotePubkey, account.NodePubkey, c.config.IdentityName(account.NodePubkey),
+				)
+			}
+		}
+		ch <- c.TrackedValidatorsDelinquent.MustNewConstMetric(float64(trackedDelinquent))
+	}
+
+	// distinguish tracked validators which are absent from those with zero-valued metrics:
+	found := make(map[string]struct{})
+	for _, account := range append(voteAccounts.Current, voteAccounts.Delinquent...) {
+		found[account.NodePubkey] = struct{}{}
+	}
+	for _, nodekey := range c.config.NodeKeys {
+		_, ok := found[nodekey]
+		ch <- c.ValidatorFound.MustNewConstMetric(BoolToFloat64(ok), nodekey, c.config.IdentityName(nodekey))
+	}
+
+	ch <- c.ClusterActiveStake.MustNewConstMetric(totalStake)
+	ch <- c.ClusterLastVote.MustNewConstMetric(maxLastVote)
+	ch <- c.ClusterRootSlot.MustNewConstMetric(maxRootSlot)
+	ch <- c.ClusterValidatorCount.MustNewConstMetric(float64(len(voteAccounts.Current)), StateCurrent)
+	ch <- c.ClusterValidatorCount.MustNewConstMetric(float64(len(voteAccounts.Delinquent)), StateDelinquent)
+	ch <- c.VoteAccountsProcessed.MustNewConstMetric(float64(len(voteAccounts.Current) + len(voteAccounts.Delinquent)))
+
+	var delinquentStakePercent float64
+	if totalStake > 0 {
+		delinquentStakePercent = 100 * delinquentStake / totalStake
+	}
+	ch <- c.ClusterDelinquentStake.MustNewConstMetric(delinquentStake)
+	ch <- c.ClusterDelinquentStakePercent.MustNewConstMetric(delinquentStakePercent)
+
+	c.logger.Log(c.collectLogLevel, "Vote accounts collected.")
+}
+
+// updateVoteParticipation records the last vote of the validator as of slot, and returns the fraction of the
+// slots elapsed since its first scrape in epoch which were voted on, or false if there is no estimate yet. Over each
+// interval between scrapes, the progression of the last vote is capped at that of the slot, so that catching up after
+// a stall (where the last vote jumps ahead) does not make up for the slots missed.
+func (c *SolanaCollector) updateVoteParticipation(
+	votekey, nodekey string, epoch, slot, lastVote int64,
+) (float64, bool) {
+	c.voteParticipationsMu.Lock()
+	defer c.voteParticipationsMu.Unlock()
+	if c.voteParticipations == nil {
+		c.voteParticipations = make(map[[2]string]*voteParticipation)
+	}
+
+	key := [2]string{votekey, nodekey}
+	participation, ok := c.voteParticipations[key]
+	if !ok || participation.epoch != epoch {
+		c.voteParticipations[key] = &voteParticipation{epoch: epoch, slot: slot, lastVote: lastVote}
+		return 0, false
+	}
+	// the slot and last vote may go backwards if the rpc is load-balanced across nodes, which is ignored:
+	if elapsed := slot - participation.slot; elapsed > 0 {
+		participation.voted += min(max(0, lastVote-participation.lastVote), elapsed)
+		participation.elapsed += elapsed
+		participation.slot = slot
+	}
+	participation.lastVote = max(participation.lastVote, lastVote)
+	if participation.elapsed == 0 {
+		return 0, false
+	}
+	return float64(participation.voted) / float64(participation.elapsed), true
+}
+
+// updateCommission records the commission of the validator, and returns whether it changed since the previous scrape.
+func (c *SolanaCollector) updateCommission(votekey, nodekey string, commission int) bool {
+	c.commissionsMu.Lock()
+	defer c.commissionsMu.Unlock()
+	if c.commissions == nil {
+		c.commissions = make(map[[2]string]int)
+	}
+
+	key := [2]string{votekey, nodekey}
+	previous, ok := c.commissions[key]
+	c.commissions[key] = commission
+	return ok && previous != commission
+}
+
+// voteAccountsCommitment returns the commitment level at which vote accounts (and the slot they are compared to) are
+// fetched.
+func (c *SolanaCollector) voteAccountsCommitment() rpc.Commitment {
+	return c.config.Commitment(c.config.VoteAccountsCommitment, rpc.CommitmentConfirmed)
+}
+
+// slotCommitment returns the commitment level at which the slot of the node is fetched by the collector (e.g., for
+// solana_node_ledger_span_slots), unlike the slot watcher (see ExporterConfig.SlotWatcherCommitment).
+func (c *SolanaCollector) slotCommitment() rpc.Commitment {
+	return c.config.Commitment("", rpc.CommitmentConfirmed)
+}
+
+// balancesCommitment returns the commitment level at which balances are fetched.
+func (c *SolanaCollector) balancesCommitment() rpc.Commitment {
+	return c.config.Commitment("", rpc.CommitmentConfirmed)
+}
+
+// fetchVoteAccounts fetches the vote accounts needed by collectVoteAccounts. The cluster-wide, superminority and
+// credits rank metrics need every vote account, but if they are all disabled and only the tracked validators are
+// monitored, then only their vote accounts are fetched, which is a much smaller payload on mainnet. As getVoteAccounts
+// only filters by a single votePubkey, this makes one request per tracked vote account.
+func (c *SolanaCollector) fetchVoteAccounts(ctx context.Context) (*rpc.VoteAccounts, error) {
+	commitment := c.voteAccountsCommitment()
+	if c.config.ComprehensiveVoteAccountTracking || c.descsEnabled(
+		c.ClusterActiveStake, c.ClusterLastVote, c.ClusterRootSlot, c.ClusterValidatorCount,
+		c.ClusterDelinquentStake, c.ClusterDelinquentStakePercent, c.ValidatorIsSuperminority, c.ValidatorCreditsRank,
+	) {
+		return c.rpcClient.GetVoteAccounts(ctx, commitment, "")
+	}
+
+	var voteAccounts rpc.VoteAccounts
+	for _, votekey := range c.config.VoteKeys {
+		filtered, err := c.rpcClient.GetVoteAccounts(ctx, commitment, votekey)
+		if err != nil {
+			return nil, err
+		}
+		voteAccounts.Current = append(voteAccounts.Current, filtered.Current...)
+		voteAccounts.Delinquent = append(voteAccounts.Delinquent, filtered.Delinquent...)
+	}
+	return &voteAccounts, nil
+}
+
+func (c *SolanaCollector) collectVersion(ch chan<- prometheus.Metric, info *scrapeNodeInfo) {
+	if !c.collectorEnabled(CollectorVersion) {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting version...")
+	if info.versionErr != nil {
+		c.logger.Errorf("failed to get version: %v", info.versionErr)
+		ch <- c.NodeVersion.NewInvalidMetric(info.versionErr)
+		ch <- c.NodeVersionNumeric.NewInvalidMetric(info.versionErr)
+		ch <- c.NodeFeatureSet.NewInvalidMetric(info.versionErr)
+		return
+	}
+
+	// Use the isFiredancer field that was set by detectFiredancer
+	isFiredancerStr, client := "0", ClientAgave
+	if c.isFiredancer.Load() {
+		isFiredancerStr, client = "1", ClientFiredancer
+	}
+
+	ch <- c.NodeVersion.MustNewConstMetric(1, info.version, isFiredancerStr)
+	if versionNumber, err := parseVersionToNumber(info.version); err != nil {
+		c.logger.Errorf("failed to parse version: %v", err)
+		ch <- c.NodeVersionNumeric.NewInvalidMetric(err)
+	} else {
+		ch <- c.NodeVersionNumeric.MustNewConstMetric(versionNumber, client)
+	}
+	// nodes which do not report their feature set have none to export:
+	if info.featureSet != 0 {
+		ch <- c.NodeFeatureSet.MustNewConstMetric(float64(info.featureSet))
+	}
+	c.logger.Log(c.collectLogLevel, "Version collected.")
+}
+
+func (c *SolanaCollector) collectIdentity(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorIdentity) {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting identity...")
+	identity, err := c.rpcClient.GetIdentity(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to get identity: %v", err)
+		c.recordRPCError(err)
+		ch <- c.NodeIdentity.NewInvalidMetric(err)
+		return
+	}
+
+	if c.config.ActiveIdentity != "" {
+		isActive := 0
+		if c.config.ActiveIdentity == identity {
+			isActive = 1
+		}
+		ch <- c.NodeIsActive.MustNewConstMetric(float64(isActive), identity)
+		c.logger.Log(c.collectLogLevel, "NodeIsActive collected.")
+	}
+
+	// catch the exporter being pointed at a node other than the monitored validators:
+	if len(c.config.NodeKeys) > 0 {
+		matches := slices.Contains(c.config.NodeKeys, identity)
+		if !matches {
+			c.logger.Warnf("node identity %s is not one of the configured nodekeys %v", identity, c.config.NodeKeys)
+		}
+		ch <- c.NodeIdentityMatchesConfigured.MustNewConstMetric(BoolToFloat64(matches), identity)
+	}
+
+	ch <- c.NodeIdentity.MustNewConstMetric(1, identity)
+	c.logger.Log(c.collectLogLevel, "Identity collected.")
+}
+
+func (c *SolanaCollector) collectMinimumLedgerSlot(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorMinimumLedgerSlot) {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting minimum ledger slot...")
+	slot, err := c.rpcClient.GetMinimumLedgerSlot(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to get minimum lidger slot: %v", err)
+		c.recordRPCError(err)
+		ch <- c.NodeMinimumLedgerSlot.NewInvalidMetric(err)
+		return
+	}
+
+	ch <- c.NodeMinimumLedgerSlot.MustNewConstMetric(float64(slot))
+	c.logger.Log(c.collectLogLevel, "Minimum ledger slot collected.")
+}
+
+func (c *SolanaCollector) collectFirstAvailableBlock(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorFirstAvailableBlock) {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting first available block...")
+	block, err := c.rpcClient.GetFirstAvailableBlock(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to get first available block: %v", err)
+		c.recordRPCError(err)
+		ch <- c.NodeFirstAvailableBlock.NewInvalidMetric(err)
+		ch <- c.NodeLedgerSpanSlots.NewInvalidMetric(err)
+		return
+	}
+
+	ch <- c.NodeFirstAvailableBlock.MustNewConstMetric(float64(block))
+	if c.descsEnabled(c.NodeLedgerSpanSlots) {
+		slot, err := c.rpcClient.GetSlot(ctx, c.slotCommitment())
+		if err != nil {
+			c.logger.Errorf("failed to get slot: %v", err)
+			c.recordRPCError(err)
+			ch <- c.NodeLedgerSpanSlots.NewInvalidMetric(err)
+			return
+		}
+		// the span is clamped to zero, as the calls are not atomic:
+		ch <- c.NodeLedgerSpanSlots.MustNewConstMetric(float64(max(0, slot-block)))
+	}
+	c.logger.Log(c.collectLogLevel, "First available block collected.")
+}
+
+func (c *SolanaCollector) collectBalances(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorBalances) {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting balances...")
+	balances, err := FetchBalances(
+		ctx,
+		c.rpcClient,
+		c.balancesCommitment(),
+		CombineUnique(c.config.BalanceAddresses, c.config.NodeKeys, c.config.VoteKeys),
+		c.config.BalanceFetchConcurrency,
+	)
+	if err != nil {
+		c.logger.Errorf("failed to get balances: %v", err)
+		c.recordRPCError(err)
+		ch <- c.AccountBalances.NewInvalidMetric(err)
+		ch <- c.AccountBalanceChange.NewInvalidMetric(err)
+		return
+	}
+
+	c.previousBalancesMu.Lock()
+	defer c.previousBalancesMu.Unlock()
+	for address, balance := range balances {
+		name := c.config.BalanceAddressName(address)
+		ch <- c.AccountBalances.MustNewConstMetric(balance, address, name)
+		// there is no change to report on the first scrape of an address:
+		if previous, ok := c.previousBalances[address]; ok {
+			ch <- c.AccountBalanceChange.MustNewConstMetric(balance-previous, address, name)
+		}
+	}
+	c.previousBalances = balances
+	c.logger.Log(c.collectLogLevel, "Balances collected.")
+}
+
+func (c *SolanaCollector) collectBlockTimeLag(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorBlockTimeLag) {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting block time lag...")
+	blockTime, err := c.getLatestBlockTime(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to get latest block time: %v", err)
+		c.recordRPCError(err)
+		ch <- c.NodeBlockTimeLag.NewInvalidMetric(err)
+		return
+	}
+
+	ch <- c.NodeBlockTimeLag.MustNewConstMetric(time.Since(blockTime).Seconds())
+	c.logger.Log(c.collectLogLevel, "Block time lag collected.")
+}
+
+// getLatestBlockTime returns the production time of the latest confirmed block. As skipped slots have no block time,
+// this walks back up to maxBlockTimeLookback slots from the latest confirmed slot.
+func (c *SolanaCollector) getLatestBlockTime(ctx context.Context) (time.Time, error) {
+	slot, err := c.rpcClient.GetSlot(ctx, c.slotCommitment())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get latest slot: %w", err)
+	}
+
+	for i := int64(0); i < maxBlockTimeLookback && slot-i >= 0; i++ {
+		blockTime, err := c.rpcClient.GetBlockTime(ctx, slot-i)
+		if err != nil && !rpc.IsSkippedSlotError(err) {
+			return time.Time{}, fmt.Errorf("failed to get block time of slot %d: %w", slot-i, err)
+		}
+		if blockTime == nil {
+			// the slot was skipped, so look at the previous one:
+			continue
+		}
+		return time.Unix(*blockTime, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("no block time found within %d slots of slot %d", maxBlockTimeLookback, slot)
+}
+
+func (c *SolanaCollector) collectSnapshotSlots(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorSnapshotSlots) {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting snapshot slots...")
+	var fullSlot, incrementalSlot int64
+	snapshotSlot, err := c.rpcClient.GetHighestSnapshotSlot(ctx)
+	if err != nil {
+		// nodes that have not produced any snapshot yet return a specific error, which is not a failure:
+		var rpcError *rpc.Error
+		if !errors.As(err, &rpcError) || rpcError.Code != rpc.NoSnapshotCode {
+			c.logger.Errorf("failed to get highest snapshot slot: %v", err)
+			c.recordRPCError(err)
+			ch <- c.NodeHighestFullSnapshotSlot.NewInvalidMetric(err)
+			ch <- c.NodeHighestIncrementalSnapshotSlot.NewInvalidMetric(err)
+			return
+		}
+		c.logger.Debug("Node has no snapshot yet.")
+	} else {
+		fullSlot = snapshotSlot.Full
+		if snapshotSlot.Incremental != nil {
+			incrementalSlot = *snapshotSlot.Incremental
+		}
+	}
+
+	ch <- c.NodeHighestFullSnapshotSlot.MustNewConstMetric(float64(fullSlot))
+	ch <- c.NodeHighestIncrementalSnapshotSlot.MustNewConstMetric(float64(incrementalSlot))
+	c.logger.Log(c.collectLogLevel, "Snapshot slots collected.")
+}
+
+func (c *SolanaCollector) collectNextLeaderSlot(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorNextLeaderSlot) || len(c.config.NodeKeys) == 0 {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting next leader slots...")
+	leaders, err := c.getUpcomingLeaders(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to get upcoming leaders: %v", err)
+		c.recordRPCError(err)
+		ch <- c.NodeNextLeaderSlot.NewInvalidMetric(err)
+		return
+	}
+
+	for _, nodekey := range c.config.NodeKeys {
+		distance := slices.Index(leaders, nodekey)
+		if distance < 0 {
+			distance = nextLeaderSlotWindow
+		}
+		ch <- c.NodeNextLeaderSlot.MustNewConstMetric(float64(distance), nodekey, c.config.IdentityName(nodekey))
+	}
+	c.logger.Log(c.collectLogLevel, "Next leader slots collected.")
+}
+
+// getUpcomingLeaders returns the leaders of the next nextLeaderSlotWindow slots, starting at the current slot.
+func (c *SolanaCollector) getUpcomingLeaders(ctx context.Context) ([]string, error) {
+	slot, err := c.rpcClient.GetSlot(ctx, c.config.Commitment("", rpc.CommitmentProcessed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current slot: %w", err)
+	}
+	return c.rpcClient.GetSlotLeaders(ctx, slot, nextLeaderSlotWindow)
+}
+
+func (c *SolanaCollector) collectEpochCountdown(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorEpochCountdown) {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting epoch countdown...")
+	schedule, slot, err := c.getCurrentSlot(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to get epoch progress: %v", err)
+		c.recordRPCError(err)
+		ch <- c.NodeEpochSecondsRemaining.NewInvalidMetric(err)
+		ch <- c.NodeEpochElapsedSlots.NewInvalidMetric(err)
+		ch <- c.NodeEpochStartedTimestamp.NewInvalidMetric(err)
+		return
+	}
+	firstSlot := schedule.FirstSlot(slot)
+	ch <- c.NodeEpochElapsedSlots.MustNewConstMetric(float64(slot - firstSlot))
+
+	if c.descsEnabled(c.NodeEpochStartedTimestamp) {
+		startedAt, err := c.getEpochStartTime(ctx, firstSlot, slot)
+		if err != nil {
+			c.logger.Errorf("failed to estimate epoch start time: %v", err)
+			c.recordRPCError(err)
+			ch <- c.NodeEpochStartedTimestamp.NewInvalidMetric(err)
+		} else {
+			ch <- c.NodeEpochStartedTimestamp.MustNewConstMetric(float64(startedAt.Unix()))
+		}
+	}
+	if c.descsEnabled(c.NodeEpochSecondsRemaining) {
+		secondsRemaining, err := c.estimateEpochSecondsRemaining(ctx, schedule.SlotsRemaining(slot))
+		if err != nil {
+			c.logger.Errorf("failed to estimate epoch seconds remaining: %v", err)
+			c.recordRPCError(err)
+			ch <- c.NodeEpochSecondsRemaining.NewInvalidMetric(err)
+		} else {
+			ch <- c.NodeEpochSecondsRemaining.MustNewConstMetric(secondsRemaining)
+		}
+	}
+	c.logger.Log(c.collectLogLevel, "Epoch countdown collected.")
+}
+
+// estimateEpochSecondsRemaining estimates the time (in seconds) until the end of the current epoch, from the
+// slotsRemaining in it and the recent average slot time.
+func (c *SolanaCollector) estimateEpochSecondsRemaining(ctx context.Context, slotsRemaining int64) (float64, error) {
+	samples, err := c.rpcClient.GetRecentPerformanceSamples(ctx, performanceSampleCount)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get performance samples: %w", err)
+	}
+	return EstimateSecondsRemaining(slotsRemaining, samples)
+}
+
+// getCurrentSlot returns the epoch schedule of the cluster, along with the current (confirmed) slot.
+func (c *SolanaCollector) getCurrentSlot(ctx context.Context) (*rpc.EpochSchedule, int64, error) {
+	schedule, err := c.getEpochSchedule(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get epoch schedule: %w", err)
+	}
+	slot, err := c.rpcClient.GetSlot(ctx, c.slotCommitment())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get current slot: %w", err)
+	}
+	return schedule, slot, nil
+}
+
+// getEpochStartTime estimates the start time of the epoch starting at firstSlot, from the block time of its first slot
+// which was not skipped (up to maxBlockTimeLookback slots in, and up to the current slot). The estimate is cached for
+// the rest of the epoch.
+func (c *SolanaCollector) getEpochStartTime(ctx context.Context, firstSlot, slot int64) (time.Time, error) {
+	c.epochStartMu.Lock()
+	defer c.epochStartMu.Unlock()
+	if !c.epochStartedAt.IsZero() && c.epochStartSlot == firstSlot {
+		return c.epochStartedAt, nil
+	}
+
+	for s := firstSlot; s <= slot && s < firstSlot+maxBlockTimeLookback; s++ {
+		blockTime, err := c.rpcClient.GetBlockTime(ctx, s)
+		if err != nil && !rpc.IsSkippedSlotError(err) {
+			return time.Time{}, fmt.Errorf("failed to get block time of slot %d: %w", s, err)
+		}
+		if blockTime == nil {
+			// the slot was skipped, so look at the next one:
+			continue
+		}
+		c.epochStartedAt, c.epochStartSlot = time.Unix(*blockTime, 0), firstSlot
+		return c.epochStartedAt, nil
+	}
+	return time.Time{}, fmt.Errorf("no block time found within %d slots of slot %d", maxBlockTimeLookback, firstSlot)
+}
+
+func (c *SolanaCollector) collectTransactionCount(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorTransactionCount) {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting transaction count...")
+	count, err := c.rpcClient.GetTransactionCount(ctx, c.config.Commitment("", rpc.CommitmentFinalized))
+	if err != nil {
+		c.logger.Errorf("failed to get transaction count: %v", err)
+		c.recordRPCError(err)
+		ch <- c.NodeTransactionsTotal.NewInvalidMetric(err)
+		return
+	}
+
+	c.transactionCountMu.Lock()
+	c.transactionCount = max(c.transactionCount, count)
+	count = c.transactionCount
+	c.transactionCountMu.Unlock()
+
+	ch <- c.NodeTransactionsTotal.MustNewConstCounter(float64(count))
+	c.logger.Log(c.collectLogLevel, "Transaction count collected.")
+}
+
+// collectReference compares the slot of the node with that of the reference rpc node, if one is configured.
+func (c *SolanaCollector) collectReference(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorReference) || c.referenceClient == nil {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting reference slot...")
+	commitment := c.slotCommitment()
+	referenceSlot, err := c.referenceClient.GetSlot(ctx, commitment)
+	if err != nil {
+		// the reference node being unreachable does not mean that the scrape failed:
+		c.logger.Errorf("failed to get reference slot: %v", err)
+		ch <- c.ReferenceSlot.NewInvalidMetric(err)
+		ch <- c.NodeSlotsBehindReference.NewInvalidMetric(err)
+		return
+	}
+	ch <- c.ReferenceSlot.MustNewConstMetric(float64(referenceSlot))
+
+	slot, err := c.rpcClient.GetSlot(ctx, commitment)
+	if err != nil {
+		c.logger.Errorf("failed to get current slot: %v", err)
+		c.recordRPCError(err)
+		ch <- c.NodeSlotsBehindReference.NewInvalidMetric(err)
+		return
+	}
+	ch <- c.NodeSlotsBehindReference.MustNewConstMetric(float64(referenceSlot - slot))
+	c.logger.Log(c.collectLogLevel, "Reference slot collected.")
+}
+
+func (c *SolanaCollector) collectGossip(ctx context.Context, ch chan<- prometheus.Metric, info *scrapeNodeInfo) {
+	if !c.collectorEnabled(CollectorGossip) {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting gossip...")
+	nodes, err := c.rpcClient.GetClusterNodes(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to get cluster nodes: %v", err)
+		c.recordRPCError(err)
+		ch <- c.ClusterGossipPeers.NewInvalidMetric(err)
+		ch <- c.NodeVisibleInGossip.NewInvalidMetric(err)
+		ch <- c.NodeAdvertisedPort.NewInvalidMetric(err)
+		ch <- c.NodeFeatureSetMatchesCluster.NewInvalidMetric(err)
+		return
+	}
+	ch <- c.ClusterGossipPeers.MustNewConstMetric(float64(len(nodes)))
+	c.emitFeatureSetMatchesCluster(ch, nodes, info)
+
+	identity, err := c.rpcClient.GetIdentity(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to get identity: %v", err)
+		c.recordRPCError(err)
+		ch <- c.NodeVisibleInGossip.NewInvalidMetric(err)
+		ch <- c.NodeAdvertisedPort.NewInvalidMetric(err)
+		return
+	}
+	i := slices.IndexFunc(nodes, func(node rpc.ContactInfo) bool { return node.Pubkey == identity })
+	ch <- c.NodeVisibleInGossip.MustNewConstMetric(BoolToFloat64(i >= 0), identity)
+	if i < 0 {
+		// without a contact info, the advertised ports are unknown rather than missing:
+		c.logger.Warnf("node identity %s is missing from its own gossip table", identity)
+		return
+	}
+	node := nodes[i]
+	ch <- c.NodeAdvertisedPort.MustNewConstMetric(BoolToFloat64(node.Tpu != nil), PortTypeTpu)
+	ch <- c.NodeAdvertisedPort.MustNewConstMetric(BoolToFloat64(node.TpuQuic != nil), PortTypeTpuQuic)
+	ch <- c.NodeAdvertisedPort.MustNewConstMetric(BoolToFloat64(node.Rpc != nil), PortTypeRpc)
+	c.logger.Log(c.collectLogLevel, "Gossip collected.")
+}
+
+// emitFeatureSetMatchesCluster compares the feature set of the node with the -expected-feature-set or, if it is not
+// set, with the majority feature set of the gossip nodes. Nothing is emitted if either feature set is unknown.
+func (c *SolanaCollector) emitFeatureSetMatchesCluster(
+	ch chan<- prometheus.Metric, nodes []rpc.ContactInfo, info *scrapeNodeInfo,
+) {
+	if info.versionErr != nil {
+		ch <- c.NodeFeatureSetMatchesCluster.NewInvalidMetric(info.versionErr)
+		return
+	}
+	expected := c.config.ExpectedFeatureSet
+	if expected == 0 {
+		expected = GetMajorityFeatureSet(nodes)
+	}
+	if info.featureSet == 0 || expected == 0 {
+		return
+	}
+	ch <- c.NodeFeatureSetMatchesCluster.MustNewConstMetric(BoolToFloat64(info.featureSet == expected))
+}
+
+func (c *SolanaCollector) collectConfirmationLatency(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorConfirmationProbe) || c.probeKey == nil {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting confirmation latency...")
+	// the probe runs in the background, as every probe pays a fee and can take up to probeTimeout. Its error is not
+	// recorded as an rpc error of the scrape, as it is served from the cache until the next probe is due:
+	latency, ok, err := c.confirmationProbe.Get(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to probe confirmation latency: %v", err)
+		ch <- c.NodeConfirmationLatency.NewInvalidMetric(err)
+		return
+	}
+	if !ok {
+		c.logger.Warn("The first confirmation latency probe has not completed yet.")
+		return
+	}
+	ch <- c.NodeConfirmationLatency.MustNewConstMetric(latency.Seconds())
+	c.logger.Log(c.collectLogLevel, "Confirmation latency collected.")
+}
+
+// collectCommitmentSlots reports the slot of the node at every commitment level (regardless of -default-commitment),
+// such that the gap between the processed and the finalized slot can be tracked, and checks the confirmed slot
+// against the one reported by getEpochInfo and against that of the previous scrape, to catch stalls (which getHealth
+// may not report) and restarts.
+func (c *SolanaCollector) collectCommitmentSlots(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorCommitmentSlots) {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting commitment slots...")
+	var confirmedSlot int64
+	for _, commitment := range rpc.Commitments {
+		slot, err := c.rpcClient.GetSlot(ctx, commitment)
+		if err != nil {
+			c.logger.Errorf("failed to get %s slot: %v", commitment, err)
+			c.recordRPCError(err)
+			ch <- c.NodeSlot.NewInvalidMetric(err)
+			ch <- c.NodeSlotSourceDisagreement.NewInvalidMetric(err)
+			ch <- c.NodeSlotAdvancing.NewInvalidMetric(err)
+			ch <- c.NodeRestartSuspectedTotal.NewInvalidMetric(err)
+			return
+		}
+		ch <- c.NodeSlot.MustNewConstMetric(float64(slot), string(commitment))
+		if commitment == rpc.CommitmentConfirmed {
+			confirmedSlot = slot
+		}
+	}
+
+	c.nodeSlotMu.Lock()
+	advancing, previousSlot := confirmedSlot > c.nodeSlot, c.nodeSlot
+	if previousSlot > 0 && confirmedSlot < previousSlot {
+		c.logger.Warnf("slot went backwards from %d to %d, the node may have restarted", previousSlot, confirmedSlot)
+		c.restartsSuspected++
+	}
+	c.nodeSlot = confirmedSlot
+	restartsSuspected := c.restartsSuspected
+	c.nodeSlotMu.Unlock()
+
+	// the slot can only be compared from the second scrape on:
+	if previousSlot > 0 {
+		ch <- c.NodeSlotAdvancing.MustNewConstMetric(BoolToFloat64(advancing))
+	}
+	ch <- c.NodeRestartSuspectedTotal.MustNewConstCounter(float64(restartsSuspected))
+
+	epochInfo, err := c.rpcClient.GetEpochInfo(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		c.logger.Errorf("failed to get epoch info: %v", err)
+		c.recordRPCError(err)
+		ch <- c.NodeSlotSourceDisagreement.NewInvalidMetric(err)
+		return
+	}
+	disagreement := epochInfo.AbsoluteSlot - confirmedSlot
+	ch <- c.NodeSlotSourceDisagreement.MustNewConstMetric(math.Abs(float64(disagreement)))
+	c.logger.Log(c.collectLogLevel, "Commitment slots collected.")
+}
+
+func (c *SolanaCollector) collectClusterSkipRate(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorClusterSkipRate) {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting cluster skip rate...")
+	slots, err := c.getClusterSlots(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to get cluster block production: %v", err)
+		c.recordRPCError(err)
+		ch <- c.ClusterSkipRate.NewInvalidMetric(err)
+		return
+	}
+	if slots.leader == 0 {
+		// no slots were led yet in this epoch:
+		return
+	}
+	ch <- c.ClusterSkipRate.MustNewConstMetric(float64(slots.skipped) / float64(slots.leader))
+	c.logger.Log(c.collectLogLevel, "Cluster skip rate collected.")
+}
+
+func (c *SolanaCollector) collectCluster(ch chan<- prometheus.Metric, info *scrapeNodeInfo) {
+	if !c.collectorEnabled(CollectorCluster) {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting cluster...")
+	if info.genesisHash == "" {
+		ch <- c.NodeCluster.NewInvalidMetric(info.clusterErr)
+		return
+	}
+	// genesis hashes of unknown clusters are still exported, so that the failed detection is visible:
+	cluster := info.cluster
+	if info.clusterErr != nil {
+		cluster = ClusterUnknown
+	}
+	ch <- c.NodeCluster.MustNewConstMetric(1, cluster, info.genesisHash)
+	c.logger.Log(c.collectLogLevel, "Cluster collected.")
+}
+
+func (c *SolanaCollector) collectRentExempt(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorRentExempt) || len(c.config.RentExemptDataSizes) == 0 {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting rent exempt minimums...")
+	for _, dataSize := range c.config.RentExemptDataSizes {
+		minimum, err := c.rpcClient.GetMinimumBalanceForRentExemption(
+			ctx, c.config.Commitment("", rpc.CommitmentFinalized), dataSize,
+		)
+		if err != nil {
+			c.logger.Errorf("failed to get rent exempt minimum for %d bytes: %v", dataSize, err)
+			c.recordRPCError(err)
+			ch <- c.RentExemptMinimum.NewInvalidMetric(err)
+			return
+		}
+		ch <- c.RentExemptMinimum.MustNewConstMetric(float64(minimum), toString(dataSize))
+	}
+	c.logger.Log(c.collectLogLevel, "Rent exempt minimums collected.")
+}
+
+func (c *SolanaCollector) collectStakeMinimumDelegation(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorStakeMinimum) {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting stake minimum delegation...")
+	minimum, err := c.rpcClient.GetStakeMinimumDelegation(ctx, c.config.Commitment("", rpc.CommitmentFinalized))
+	if err != nil {
+		c.logger.Errorf("failed to get stake minimum delegation: %v", err)
+		c.recordRPCError(err)
+		ch <- c.ClusterStakeMinimumDelegation.NewInvalidMetric(err)
+		return
+	}
+	ch <- c.ClusterStakeMinimumDelegation.MustNewConstMetric(minimum)
+	c.logger.Log(c.collectLogLevel, "Stake minimum delegation collected.")
+}
+
+func (c *SolanaCollector) collectPerformanceSamples(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorPerformanceSamples) || c.config.PerformanceSampleCount == 0 {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting performance samples...")
+	samples, err := c.rpcClient.GetRecentPerformanceSamples(ctx, int64(c.config.PerformanceSampleCount))
+	if err != nil {
+		c.logger.Errorf("failed to get performance samples: %v", err)
+		c.recordRPCError(err)
+		ch <- c.ClusterPerformanceSampleTps.NewInvalidMetric(err)
+		return
+	}
+	// the samples are returned newest first, such that each index covers the same minute ago across scrapes:
+	for i, sample := range samples[:min(len(samples), c.config.PerformanceSampleCount)] {
+		if sample.SamplePeriodSecs <= 0 {
+			continue
+		}
+		tps := float64(sample.NumTransactions) / float64(sample.SamplePeriodSecs)
+		ch <- c.ClusterPerformanceSampleTps.MustNewConstMetric(tps, strconv.Itoa(i))
+	}
+	c.logger.Log(c.collectLogLevel, "Performance samples collected.")
+}
+
+// getClusterSlots returns the leader and skipped slots of the whole cluster in the current epoch. The block production
+// of the slots already seen in this epoch is cached, such that only that of the new slots is fetched.
+func (c *SolanaCollector) getClusterSlots(ctx context.Context) (clusterSlots, error) {
+	c.clusterSlotsMu.Lock()
+	defer c.clusterSlotsMu.Unlock()
+
+	commitment := c.config.Commitment("", rpc.CommitmentFinalized)
+	epochInfo, err := c.rpcClient.GetEpochInfo(ctx, commitment)
+	if err != nil {
+		return clusterSlots{}, fmt.Errorf("failed to get epoch info: %w", err)
+	}
+	if epochInfo.Epoch != c.clusterSlots.epoch || c.clusterSlotsWatermark == 0 {
+		firstSlot, _ := GetEpochBounds(epochInfo)
+		c.clusterSlots = clusterSlots{epoch: epochInfo.Epoch}
+		c.clusterSlotsWatermark = firstSlot - 1
+	}
+	if epochInfo.AbsoluteSlot <= c.clusterSlotsWatermark {
+		return c.clusterSlots, nil
 	}
-	return collector
+
+	production, err := c.rpcClient.GetBlockProduction(
+		ctx, commitment, c.clusterSlotsWatermark+1, epochInfo.AbsoluteSlot,
+	)
+	if err != nil {
+		return clusterSlots{}, err
+	}
+	for _, host := range production.ByIdentity {
+		c.clusterSlots.leader += host.LeaderSlots
+		c.clusterSlots.skipped += host.LeaderSlots - host.BlocksProduced
+	}
+	c.clusterSlotsWatermark = epochInfo.AbsoluteSlot
+	return c.clusterSlots, nil
 }
 
-func (c *SolanaCollector) Describe(ch chan<- *prometheus.Desc) {
-	ch <- c.NodeVersion.Desc
-	ch <- c.NodeIdentity.Desc
-	ch <- c.ValidatorActiveStake.Desc
-	ch <- c.ClusterActiveStake.Desc
-	ch <- c.ValidatorLastVote.Desc
-	ch <- c.ClusterLastVote.Desc
-	ch <- c.ValidatorRootSlot.Desc
-	ch <- c.ClusterRootSlot.Desc
-	ch <- c.ValidatorDelinquent.Desc
-	ch <- c.ClusterValidatorCount.Desc
-	ch <- c.AccountBalances.Desc
-	ch <- c.NodeIsHealthy.Desc
-	ch <- c.NodeNumSlotsBehind.Desc
-	ch <- c.NodeMinimumLedgerSlot.Desc
-	ch <- c.NodeFirstAvailableBlock.Desc
-	ch <- c.NodeIsActive.Desc
-	ch <- c.FoundationMinRequiredVersion.Desc
-	ch <- c.NodeIsOutdated.Desc
-	ch <- c.NodeNeedsUpdate.Desc
+// getEpochSchedule returns the epoch schedule of the cluster, which is only fetched once.
+func (c *SolanaCollector) getEpochSchedule(ctx context.Context) (*rpc.EpochSchedule, error) {
+	c.epochScheduleMu.Lock()
+	defer c.epochScheduleMu.Unlock()
+	if c.epochSchedule != nil {
+		return c.epochSchedule, nil
+	}
+
+	schedule, err := c.rpcClient.GetEpochSchedule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.epochSchedule = schedule
+	return schedule, nil
 }
 
-func (c *SolanaCollector) collectVoteAccounts(ctx context.Context, ch chan<- prometheus.Metric) {
-	if c.config.LightMode {
-		c.logger.Debug("Skipping vote-accounts collection in light mode.")
+func (c *SolanaCollector) collectLargestAccounts(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorLargestAccounts) || !c.config.MonitorLargestAccounts {
 		return
 	}
-	c.logger.Info("Collecting vote accounts...")
-	voteAccounts, err := c.rpcClient.GetVoteAccounts(ctx, rpc.CommitmentConfirmed)
+	c.logger.Log(c.collectLogLevel, "Collecting largest accounts...")
+	accounts, err := c.getLargestAccounts(ctx)
 	if err != nil {
-		c.logger.Errorf("failed to get vote accounts: %v", err)
-		ch <- c.ValidatorActiveStake.NewInvalidMetric(err)
-		ch <- c.ClusterActiveStake.NewInvalidMetric(err)
-		ch <- c.ValidatorLastVote.NewInvalidMetric(err)
-		ch <- c.ClusterLastVote.NewInvalidMetric(err)
-		ch <- c.ValidatorRootSlot.NewInvalidMetric(err)
-		ch <- c.ClusterRootSlot.NewInvalidMetric(err)
-		ch <- c.ValidatorDelinquent.NewInvalidMetric(err)
-		ch <- c.ClusterValidatorCount.NewInvalidMetric(err)
+		c.logger.Errorf("failed to get largest accounts: %v", err)
+		c.recordRPCError(err)
+		ch <- c.ClusterLargestAccountBalance.NewInvalidMetric(err)
 		return
 	}
 
-	var (
-		totalStake  float64
-		maxLastVote float64
-		maxRootSlot float64
-	)
-	for _, account := range append(voteAccounts.Current, voteAccounts.Delinquent...) {
-		accounts := []string{account.VotePubkey, account.NodePubkey}
-		stake, lastVote, rootSlot :=
-			float64(account.ActivatedStake)/rpc.LamportsInSol,
-			float64(account.LastVote),
-			float64(account.RootSlot)
-
-		if slices.Contains(c.config.NodeKeys, account.NodePubkey) || c.config.ComprehensiveVoteAccountTracking {
-			ch <- c.ValidatorActiveStake.MustNewConstMetric(stake, accounts...)
-			ch <- c.ValidatorLastVote.MustNewConstMetric(lastVote, accounts...)
-			ch <- c.ValidatorRootSlot.MustNewConstMetric(rootSlot, accounts...)
+	for i, account := range accounts {
+		if i >= c.config.LargestAccountsCount {
+			break
 		}
-
-		totalStake += stake
-		maxLastVote = max(maxLastVote, lastVote)
-		maxRootSlot = max(maxRootSlot, rootSlot)
+		ch <- c.ClusterLargestAccountBalance.MustNewConstMetric(
+			float64(account.Lamports)/float64(rpc.LamportsInSol), account.Address,
+		)
 	}
+	c.logger.Log(c.collectLogLevel, "Largest accounts collected.")
+}
 
-	{
-		for _, account := range voteAccounts.Current {
-			if slices.Contains(c.config.NodeKeys, account.NodePubkey) || c.config.ComprehensiveVoteAccountTracking {
-				ch <- c.ValidatorDelinquent.MustNewConstMetric(0, account.VotePubkey, account.NodePubkey)
-			}
-		}
-		for _, account := range voteAccounts.Delinquent {
-			if slices.Contains(c.config.NodeKeys, account.NodePubkey) || c.config.ComprehensiveVoteAccountTracking {
-				ch <- c.ValidatorDelinquent.MustNewConstMetric(1, account.VotePubkey, account.NodePubkey)
-			}
-		}
+// getLargestAccounts returns the largest accounts on the cluster, which are cached for the configured largest
+// accounts interval, as getLargestAccounts is an expensive rpc call.
+func (c *SolanaCollector) getLargestAccounts(ctx context.Context) ([]rpc.LargestAccount, error) {
+	c.largestAccountsMu.Lock()
+	defer c.largestAccountsMu.Unlock()
+	if !c.largestAccountsFetchedAt.IsZero() && time.Since(c.largestAccountsFetchedAt) < c.config.LargestAccountsInterval {
+		return c.largestAccounts, nil
 	}
 
-	ch <- c.ClusterActiveStake.MustNewConstMetric(totalStake)
-	ch <- c.ClusterLastVote.MustNewConstMetric(maxLastVote)
-	ch <- c.ClusterRootSlot.MustNewConstMetric(maxRootSlot)
-	ch <- c.ClusterValidatorCount.MustNewConstMetric(float64(len(voteAccounts.Current)), StateCurrent)
-	ch <- c.ClusterValidatorCount.MustNewConstMetric(float64(len(voteAccounts.Delinquent)), StateDelinquent)
-
-	c.logger.Info("Vote accounts collected.")
+	accounts, err := c.rpcClient.GetLargestAccounts(ctx, c.config.Commitment("", rpc.CommitmentFinalized))
+	if err != nil {
+		return nil, err
+	}
+	c.largestAccounts, c.largestAccountsFetchedAt = accounts, time.Now()
+	return accounts, nil
 }
 
-func (c *SolanaCollector) collectVersion(ctx context.Context, ch chan<- prometheus.Metric) {
-	c.logger.Info("Collecting version...")
-	version, err := c.rpcClient.GetVersion(ctx)
-	if err != nil {
-		c.logger.Errorf("failed to get version: %v", err)
-		ch <- c.NodeVersion.NewInvalidMetric(err)
+func (c *SolanaCollector) collectStakeAccounts(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorStakeAccounts) || len(c.config.StakeAccounts) == 0 {
 		return
 	}
+	c.logger.Log(c.collectLogLevel, "Collecting stake accounts...")
+	for _, address := range c.config.StakeAccounts {
+		activation, err := c.rpcClient.GetStakeActivation(ctx, c.config.Commitment("", rpc.CommitmentConfirmed), address)
+		if err != nil {
+			c.logger.Errorf("failed to get stake activation of %s: %v", address, err)
+			c.recordRPCError(err)
+			ch <- c.StakeAccountActive.NewInvalidMetric(err)
+			ch <- c.StakeAccountActivating.NewInvalidMetric(err)
+			ch <- c.StakeAccountDeactivating.NewInvalidMetric(err)
+			return
+		}
 
-	// Use the isFiredancer field that was set in Collect
-	isFiredancer := "0"
-	if c.isFiredancer {
-		isFiredancer = "1"
+		active, inactive := float64(activation.Active)/rpc.LamportsInSol, float64(activation.Inactive)/rpc.LamportsInSol
+		// while activating, the inactive stake is warming up, and while deactivating, the active stake is cooling down:
+		var activating, deactivating float64
+		switch activation.State {
+		case rpc.StakeStateActivating:
+			activating = inactive
+		case rpc.StakeStateDeactivating:
+			deactivating = active
+		}
+		ch <- c.StakeAccountActive.MustNewConstMetric(active, address, activation.State)
+		ch <- c.StakeAccountActivating.MustNewConstMetric(activating, address, activation.State)
+		ch <- c.StakeAccountDeactivating.MustNewConstMetric(deactivating, address, activation.State)
 	}
+	c.logger.Log(c.collectLogLevel, "Stake accounts collected.")
+}
 
-	ch <- c.NodeVersion.MustNewConstMetric(1, version, isFiredancer)
-	c.logger.Info("Version collected.")
+func (c *SolanaCollector) collectTokenAccounts(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorTokenAccounts) || len(c.config.TokenAccounts) == 0 {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting token accounts...")
+	for _, address := range c.config.TokenAccounts {
+		mint, err := c.getTokenAccountMint(ctx, address)
+		if err != nil {
+			c.logger.Errorf("failed to get mint of token account %s: %v", address, err)
+			c.recordRPCError(err)
+			ch <- c.TokenAccountBalance.NewInvalidMetric(err)
+			return
+		}
+		balance, err := c.rpcClient.GetTokenAccountBalance(ctx, c.config.Commitment("", rpc.CommitmentConfirmed), address)
+		if err != nil {
+			c.logger.Errorf("failed to get balance of token account %s: %v", address, err)
+			c.recordRPCError(err)
+			ch <- c.TokenAccountBalance.NewInvalidMetric(err)
+			return
+		}
+		amount, err := balance.UiAmount()
+		if err != nil {
+			c.logger.Errorf("failed to parse balance of token account %s: %v", address, err)
+			ch <- c.TokenAccountBalance.NewInvalidMetric(err)
+			return
+		}
+		ch <- c.TokenAccountBalance.MustNewConstMetric(amount, address, mint)
+	}
+	c.logger.Log(c.collectLogLevel, "Token accounts collected.")
 }
 
-func (c *SolanaCollector) collectIdentity(ctx context.Context, ch chan<- prometheus.Metric) {
-	c.logger.Info("Collecting identity...")
-	identity, err := c.rpcClient.GetIdentity(ctx)
+// getTokenAccountMint returns the mint of the provided token account, which is only fetched once.
+func (c *SolanaCollector) getTokenAccountMint(ctx context.Context, address string) (string, error) {
+	c.tokenAccountMintsMu.Lock()
+	defer c.tokenAccountMintsMu.Unlock()
+	if mint, ok := c.tokenAccountMints[address]; ok {
+		return mint, nil
+	}
+	mint, err := c.rpcClient.GetTokenAccountMint(ctx, c.config.Commitment("", rpc.CommitmentConfirmed), address)
 	if err != nil {
-		c.logger.Errorf("failed to get identity: %v", err)
-		ch <- c.NodeIdentity.NewInvalidMetric(err)
-		return
+		return "", err
+	}
+	if c.tokenAccountMints == nil {
+		c.tokenAccountMints = make(map[string]string)
 	}
+	c.tokenAccountMints[address] = mint
+	return mint, nil
+}
 
-	if c.config.ActiveIdentity != "" {
-		isActive := 0
-		if c.config.ActiveIdentity == identity {
-			isActive = 1
+func (c *SolanaCollector) collectPrograms(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorPrograms) || len(c.config.Programs) == 0 {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting programs...")
+	for _, program := range c.config.Programs {
+		state, err := c.getProgramData(ctx, program)
+		if err != nil {
+			c.logger.Errorf("failed to get program data of %s: %v", program, err)
+			c.recordRPCError(err)
+			ch <- c.ProgramUpgradeable.NewInvalidMetric(err)
+			ch <- c.ProgramLastDeploySlot.NewInvalidMetric(err)
+			return
 		}
-		ch <- c.NodeIsActive.MustNewConstMetric(float64(isActive), identity)
-		c.logger.Info("NodeIsActive collected.")
+		if state == nil {
+			// programs of the older (non-upgradeable) loaders can never be upgraded:
+			ch <- c.ProgramUpgradeable.MustNewConstMetric(0, program)
+			continue
+		}
+		ch <- c.ProgramUpgradeable.MustNewConstMetric(BoolToFloat64(state.Authority != nil), program)
+		ch <- c.ProgramLastDeploySlot.MustNewConstMetric(float64(state.Slot), program)
 	}
-
-	ch <- c.NodeIdentity.MustNewConstMetric(1, identity)
-	c.logger.Info("Identity collected.")
+	c.logger.Log(c.collectLogLevel, "Programs collected.")
 }
 
-func (c *SolanaCollector) collectMinimumLedgerSlot(ctx context.Context, ch chan<- prometheus.Metric) {
-	c.logger.Info("Collecting minimum ledger slot...")
-	slot, err := c.rpcClient.GetMinimumLedgerSlot(ctx)
+// getProgramData returns the state of the ProgramData account of the provided program, or nil if the program is not
+// owned by the upgradeable loader.
+func (c *SolanaCollector) getProgramData(ctx context.Context, program string) (*rpc.UpgradeableLoaderState, error) {
+	commitment := c.config.Commitment("", rpc.CommitmentConfirmed)
+	programData, err := c.getProgramDataAddress(ctx, commitment, program)
+	if err != nil || programData == "" {
+		return nil, err
+	}
+	account, err := c.rpcClient.GetAccountInfo(ctx, commitment, programData)
 	if err != nil {
-		c.logger.Errorf("failed to get minimum lidger slot: %v", err)
-		ch <- c.NodeMinimumLedgerSlot.NewInvalidMetric(err)
-		return
+		return nil, err
+	}
+	if account == nil {
+		return nil, fmt.Errorf("program data account %s not found", programData)
+	}
+	state, err := account.UpgradeableLoaderState()
+	if err != nil {
+		return nil, err
+	}
+	if state.Type != "programData" {
+		return nil, fmt.Errorf("account %s is a %s account, not a program data account", programData, state.Type)
 	}
+	return state, nil
+}
 
-	ch <- c.NodeMinimumLedgerSlot.MustNewConstMetric(float64(slot))
-	c.logger.Info("Minimum ledger slot collected.")
+// getProgramDataAddress returns the address of the ProgramData account of the provided program, which is only fetched
+// once, or an empty address if the program is not owned by the upgradeable loader.
+func (c *SolanaCollector) getProgramDataAddress(
+	ctx context.Context, commitment rpc.Commitment, program string,
+) (string, error) {
+	c.programDataAddressesMu.Lock()
+	defer c.programDataAddressesMu.Unlock()
+	if address, ok := c.programDataAddresses[program]; ok {
+		return address, nil
+	}
+	account, err := c.rpcClient.GetAccountInfo(ctx, commitment, program)
+	if err != nil {
+		return "", err
+	}
+	if account == nil {
+		return "", fmt.Errorf("program %s not found", program)
+	}
+	if !account.Executable {
+		return "", fmt.Errorf("account %s is not a program", program)
+	}
+	var address string
+	if account.Owner == rpc.UpgradeableLoaderProgram {
+		state, err := account.UpgradeableLoaderState()
+		if err != nil {
+			return "", err
+		}
+		address = state.ProgramData
+	}
+	if c.programDataAddresses == nil {
+		c.programDataAddresses = make(map[string]string)
+	}
+	c.programDataAddresses[program] = address
+	return address, nil
 }
 
-func (c *SolanaCollector) collectFirstAvailableBlock(ctx context.Context, ch chan<- prometheus.Metric) {
-	c.logger.Info("Collecting first available block...")
-	block, err := c.rpcClient.GetFirstAvailableBlock(ctx)
+func (c *SolanaCollector) collectStakePool(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorStakePool) || c.config.StakePoolWithdrawAuthority == "" {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting stake pool...")
+	summary, err := c.getStakePool(ctx)
 	if err != nil {
-		c.logger.Errorf("failed to get first available block: %v", err)
-		ch <- c.NodeFirstAvailableBlock.NewInvalidMetric(err)
+		c.logger.Errorf("failed to get stake pool: %v", err)
+		c.recordRPCError(err)
+		ch <- c.StakePoolTotalStake.NewInvalidMetric(err)
+		ch <- c.StakePoolAccountCount.NewInvalidMetric(err)
 		return
 	}
 
-	ch <- c.NodeFirstAvailableBlock.MustNewConstMetric(float64(block))
-	c.logger.Info("First available block collected.")
+	authority := c.config.StakePoolWithdrawAuthority
+	ch <- c.StakePoolTotalStake.MustNewConstMetric(float64(summary.totalStake)/rpc.LamportsInSol, authority)
+	ch <- c.StakePoolAccountCount.MustNewConstMetric(float64(summary.accountCount), authority)
+	c.logger.Log(c.collectLogLevel, "Stake pool collected.")
 }
 
-func (c *SolanaCollector) collectBalances(ctx context.Context, ch chan<- prometheus.Metric) {
-	if c.config.LightMode {
-		c.logger.Debug("Skipping balance collection in light mode.")
-		return
+// getStakePool returns the summary of the stake accounts withdrawable by the configured stake pool withdraw
+// authority, which is cached for the configured stake pool interval, as getProgramAccounts is an expensive rpc call.
+func (c *SolanaCollector) getStakePool(ctx context.Context) (stakePoolSummary, error) {
+	c.stakePoolMu.Lock()
+	defer c.stakePoolMu.Unlock()
+	if !c.stakePoolFetchedAt.IsZero() && time.Since(c.stakePoolFetchedAt) < c.config.StakePoolInterval {
+		return c.stakePool, nil
 	}
-	c.logger.Info("Collecting balances...")
-	balances, err := FetchBalances(
-		ctx, c.rpcClient, CombineUnique(c.config.BalanceAddresses, c.config.NodeKeys, c.config.VoteKeys),
+
+	accounts, err := c.rpcClient.GetProgramAccounts(
+		ctx,
+		c.config.Commitment("", rpc.CommitmentFinalized),
+		rpc.StakeProgram,
+		rpc.ProgramAccountsFilter{DataSize: rpc.StakeAccountSize},
+		rpc.ProgramAccountsFilter{
+			Memcmp: &rpc.MemcmpFilter{Offset: rpc.StakeWithdrawerOffset, Bytes: c.config.StakePoolWithdrawAuthority},
+		},
 	)
 	if err != nil {
-		c.logger.Errorf("failed to get balances: %v", err)
-		ch <- c.AccountBalances.NewInvalidMetric(err)
-		return
+		return stakePoolSummary{}, err
 	}
-
-	for address, balance := range balances {
-		ch <- c.AccountBalances.MustNewConstMetric(balance, address)
+	summary := stakePoolSummary{accountCount: len(accounts)}
+	for _, account := range accounts {
+		stake, err := account.DelegatedStake()
+		if err != nil {
+			return stakePoolSummary{}, err
+		}
+		summary.totalStake += stake
 	}
-	c.logger.Info("Balances collected.")
+	c.stakePool, c.stakePoolFetchedAt = summary, time.Now()
+	return summary, nil
 }
 
 func (c *SolanaCollector) collectHealth(ctx context.Context, ch chan<- prometheus.Metric) {
-	c.logger.Info("Collecting health...")
+	if !c.collectorEnabled(CollectorHealth) {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting health...")
 
 	health, err := c.rpcClient.GetHealth(ctx)
+	if err != nil {
+		c.recordRPCError(err)
+	}
 	isHealthy, isHealthyErr, numSlotsBehind, numSlotsBehindErr := ExtractHealthAndNumSlotsBehind(health, err)
 	if isHealthyErr != nil {
 		c.logger.Errorf("failed to determine node health: %v", isHealthyErr)
@@ -356,6 +2484,15 @@ func (c *SolanaCollector) collectHealth(ctx context.Context, ch chan<- prometheu
 		ch <- c.NodeIsHealthy.MustNewConstMetric(BoolToFloat64(isHealthy))
 	}
 
+	// a healthy node reports no slots behind, even though it may lag slightly, which is measured instead:
+	if isHealthyErr == nil && isHealthy {
+		if slotsBehind, err := c.getHealthySlotsBehind(ctx); err != nil {
+			numSlotsBehindErr = err
+		} else {
+			numSlotsBehind = max(0, slotsBehind)
+		}
+	}
+
 	if numSlotsBehindErr != nil {
 		c.logger.Errorf("failed to determine number of slots behind: %v", numSlotsBehindErr)
 		ch <- c.NodeNumSlotsBehind.NewInvalidMetric(numSlotsBehindErr)
@@ -363,10 +2500,46 @@ func (c *SolanaCollector) collectHealth(ctx context.Context, ch chan<- prometheu
 		ch <- c.NodeNumSlotsBehind.MustNewConstMetric(float64(numSlotsBehind))
 	}
 
-	c.logger.Info("Health collected.")
+	c.logger.Log(c.collectLogLevel, "Health collected.")
 	return
 }
 
+// getHealthySlotsBehind returns the number of slots the (healthy) node is behind the reference rpc node if one is
+// configured or, otherwise, behind the highest slot it has received shreds for, i.e., the slot the cluster is at.
+func (c *SolanaCollector) getHealthySlotsBehind(ctx context.Context) (int64, error) {
+	if c.referenceClient != nil {
+		return c.getSlotsBehindReference(ctx)
+	}
+	maxShredInsertSlot, err := c.rpcClient.GetMaxShredInsertSlot(ctx)
+	if err != nil {
+		c.recordRPCError(err)
+		return 0, fmt.Errorf("failed to get max shred insert slot: %w", err)
+	}
+	// the shreds of a slot are received as it is produced, so they are compared with the processed slot:
+	slot, err := c.rpcClient.GetSlot(ctx, rpc.CommitmentProcessed)
+	if err != nil {
+		c.recordRPCError(err)
+		return 0, fmt.Errorf("failed to get current slot: %w", err)
+	}
+	return maxShredInsertSlot - slot, nil
+}
+
+// getSlotsBehindReference returns the number of slots the node is behind the reference rpc node (negative if it is
+// ahead), at the confirmed commitment level.
+func (c *SolanaCollector) getSlotsBehindReference(ctx context.Context) (int64, error) {
+	commitment := c.slotCommitment()
+	referenceSlot, err := c.referenceClient.GetSlot(ctx, commitment)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get reference slot: %w", err)
+	}
+	slot, err := c.rpcClient.GetSlot(ctx, commitment)
+	if err != nil {
+		c.recordRPCError(err)
+		return 0, fmt.Errorf("failed to get current slot: %w", err)
+	}
+	return referenceSlot - slot, nil
+}
+
 func compareVersions(a, b string) int {
 	// Compare dot-separated version strings, e.g., "0.503.20214"
 	aParts := strings.Split(a, ".")
@@ -392,32 +2565,33 @@ func compareVersions(a, b string) int {
 	return 0
 }
 
-func (c *SolanaCollector) collectNodeIsOutdated(ch chan<- prometheus.Metric) {
-	version, err := c.rpcClient.GetVersion(context.Background())
-	if err != nil {
-		c.logger.Errorw("failed to get version", "error", err)
+func (c *SolanaCollector) collectNodeIsOutdated(
+	ctx context.Context, ch chan<- prometheus.Metric, info *scrapeNodeInfo,
+) {
+	if !c.collectorEnabled(CollectorNodeIsOutdated) {
 		return
 	}
-
-	cluster := "mainnet-beta" // Default to mainnet-beta
-	genesisHash, err := c.rpcClient.GetGenesisHash(context.Background())
-	if err == nil {
-		cluster, err = rpc.GetClusterFromGenesisHash(genesisHash)
-		if err != nil {
-			c.logger.Errorw("failed to get cluster from genesis hash", "error", err)
-		}
+	if info.versionErr != nil {
+		c.logger.Errorw("failed to get version", "error", info.versionErr)
+		return
 	}
+	version, cluster, isFiredancer := info.version, info.clusterOrDefault(), c.isFiredancer.Load()
 
-	agaveMinVersion, _, epoch, firedancerMinVersion, err := c.apiClient.GetMinRequiredVersion(context.Background(), cluster)
+	required, err := c.apiClient.GetMinRequiredVersion(ctx, cluster)
 	if err != nil {
 		c.logger.Errorw("failed to get required version", "error", err)
 		return
 	}
 
 	// Choose the appropriate minimum version based on whether the node is running Firedancer
-	requiredVersion := agaveMinVersion
-	if c.isFiredancer {
-		requiredVersion = firedancerMinVersion
+	requiredVersion := required.AgaveMinVersion
+	if isFiredancer {
+		requiredVersion = required.FiredancerMinVersion
+	}
+	// a missing required version (e.g., null in the API response) would compare as met, so nothing is reported:
+	if requiredVersion == "" {
+		c.logger.Warnw("no required version for the node", "cluster", cluster, "is_firedancer", isFiredancer)
+		return
 	}
 
 	// Compare versions and determine if the node is outdated
@@ -427,14 +2601,14 @@ func (c *SolanaCollector) collectNodeIsOutdated(ch chan<- prometheus.Metric) {
 		"required_version", requiredVersion,
 		"is_outdated", isOutdated,
 		"cluster", cluster,
-		"is_firedancer", c.isFiredancer,
-		"agave_min_version", agaveMinVersion,
-		"firedancer_min_version", firedancerMinVersion,
-		"epoch", epoch,
+		"is_firedancer", isFiredancer,
+		"agave_min_version", required.AgaveMinVersion,
+		"firedancer_min_version", required.FiredancerMinVersion,
+		"epoch", required.Epoch,
 	)
 
 	isFiredancerStr := "0"
-	if c.isFiredancer {
+	if isFiredancer {
 		isFiredancerStr = "1"
 	}
 
@@ -444,49 +2618,89 @@ func (c *SolanaCollector) collectNodeIsOutdated(ch chan<- prometheus.Metric) {
 		version,
 		requiredVersion,
 		cluster,
-		fmt.Sprintf("%d", epoch),
+		fmt.Sprintf("%d", required.Epoch),
 	)
 }
 
-func (c *SolanaCollector) collectNodeNeedsUpdate(ch chan<- prometheus.Metric) {
-	version, err := c.rpcClient.GetVersion(context.Background())
+func (c *SolanaCollector) collectNodeAboveMaxVersion(
+	ctx context.Context, ch chan<- prometheus.Metric, info *scrapeNodeInfo,
+) {
+	if !c.collectorEnabled(CollectorNodeAboveMaxVersion) {
+		return
+	}
+	if info.versionErr != nil {
+		c.logger.Errorw("failed to get version", "error", info.versionErr)
+		return
+	}
+	version, cluster, isFiredancer := info.version, info.clusterOrDefault(), c.isFiredancer.Load()
+
+	required, err := c.apiClient.GetMinRequiredVersion(ctx, cluster)
 	if err != nil {
-		c.logger.Errorw("failed to get version", "error", err)
+		c.logger.Errorw("failed to get max allowed version", "error", err)
 		return
 	}
-	c.logger.Infow("current node version", "version", version)
 
-	cluster := "mainnet-beta" // Default to mainnet-beta
-	genesisHash, err := c.rpcClient.GetGenesisHash(context.Background())
-	if err == nil {
-		cluster, err = rpc.GetClusterFromGenesisHash(genesisHash)
-		if err != nil {
-			c.logger.Errorw("failed to get cluster from genesis hash", "error", err)
-		}
+	// Choose the appropriate maximum version based on whether the node is running Firedancer
+	maxVersion, isFiredancerStr := required.AgaveMaxVersion, "0"
+	if isFiredancer {
+		maxVersion, isFiredancerStr = required.FiredancerMaxVersion, "1"
+	}
+
+	// an empty maximum version means that there is no maximum:
+	isAboveMax := maxVersion != "" && compareVersions(version, maxVersion) > 0
+	c.logger.Infow("node max version check",
+		"current_version", version,
+		"max_version", maxVersion,
+		"is_above_max", isAboveMax,
+		"cluster", cluster,
+		"is_firedancer", isFiredancer,
+	)
+
+	ch <- c.NodeAboveMaxVersion.MustNewConstMetric(
+		BoolToFloat64(isAboveMax), isFiredancerStr, version, maxVersion, cluster,
+	)
+}
+
+func (c *SolanaCollector) collectNodeNeedsUpdate(
+	ctx context.Context, ch chan<- prometheus.Metric, info *scrapeNodeInfo,
+) {
+	if !c.collectorEnabled(CollectorNodeNeedsUpdate) {
+		return
+	}
+	if info.versionErr != nil {
+		c.logger.Errorw("failed to get version", "error", info.versionErr)
+		return
 	}
+	version, cluster, isFiredancer := info.version, info.clusterOrDefault(), c.isFiredancer.Load()
+	c.logger.Infow("current node version", "version", version)
 	c.logger.Infow("detected cluster", "cluster", cluster)
 
 	// Get next epoch version requirements
-	nextAgaveMinVersion, _, nextEpoch, nextFiredancerMinVersion, err := c.apiClient.GetNextEpochMinRequiredVersion(context.Background(), cluster)
+	next, err := c.apiClient.GetNextEpochMinRequiredVersion(ctx, cluster)
 	if err != nil {
 		c.logger.Errorw("failed to get next epoch required version", "error", err)
 		return
 	}
 	c.logger.Infow("next epoch version requirements",
-		"next_agave_min_version", nextAgaveMinVersion,
-		"next_firedancer_min_version", nextFiredancerMinVersion,
-		"next_epoch", nextEpoch,
+		"next_agave_min_version", next.AgaveMinVersion,
+		"next_firedancer_min_version", next.FiredancerMinVersion,
+		"next_epoch", next.Epoch,
 	)
 
 	// Choose the appropriate minimum version based on whether the node is running Firedancer
-	nextRequiredVersion := nextAgaveMinVersion
-	if c.isFiredancer {
-		nextRequiredVersion = nextFiredancerMinVersion
+	nextRequiredVersion := next.AgaveMinVersion
+	if isFiredancer {
+		nextRequiredVersion = next.FiredancerMinVersion
 	}
 	c.logger.Infow("selected required version",
-		"is_firedancer", c.isFiredancer,
+		"is_firedancer", isFiredancer,
 		"next_required_version", nextRequiredVersion,
 	)
+	// a missing required version (e.g., null in the API response) would compare as met, so nothing is reported:
+	if nextRequiredVersion == "" {
+		c.logger.Warnw("no next epoch required version for the node", "cluster", cluster, "is_firedancer", isFiredancer)
+		return
+	}
 
 	// Compare versions and determine if the node needs an update for the next epoch
 	needsUpdate := compareVersions(version, nextRequiredVersion) < 0
@@ -495,14 +2709,14 @@ func (c *SolanaCollector) collectNodeNeedsUpdate(ch chan<- prometheus.Metric) {
 		"next_epoch_required_version", nextRequiredVersion,
 		"needs_update", needsUpdate,
 		"cluster", cluster,
-		"is_firedancer", c.isFiredancer,
-		"next_epoch", nextEpoch,
-		"next_agave_min_version", nextAgaveMinVersion,
-		"next_firedancer_min_version", nextFiredancerMinVersion,
+		"is_firedancer", isFiredancer,
+		"next_epoch", next.Epoch,
+		"next_agave_min_version", next.AgaveMinVersion,
+		"next_firedancer_min_version", next.FiredancerMinVersion,
 	)
 
 	isFiredancerStr := "0"
-	if c.isFiredancer {
+	if isFiredancer {
 		isFiredancerStr = "1"
 	}
 
@@ -512,66 +2726,337 @@ func (c *SolanaCollector) collectNodeNeedsUpdate(ch chan<- prometheus.Metric) {
 		version,
 		nextRequiredVersion,
 		cluster,
-		fmt.Sprintf("%d", nextEpoch),
+		fmt.Sprintf("%d", next.Epoch),
 	)
 }
 
-func (c *SolanaCollector) Collect(ch chan<- prometheus.Metric) {
-	c.logger.Info("========== BEGIN COLLECTION ==========")
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	c.collectHealth(ctx, ch)
-	c.collectMinimumLedgerSlot(ctx, ch)
-	c.collectFirstAvailableBlock(ctx, ch)
-	c.collectVoteAccounts(ctx, ch)
+// detectFiredancer checks whether the node is running Firedancer, by probing its metrics endpoint. The outcome is
+// cached for the configured firedancer detection TTL.
+func (c *SolanaCollector) detectFiredancer(ctx context.Context) {
+	c.firedancerMu.Lock()
+	defer c.firedancerMu.Unlock()
+	if !c.firedancerDetectedAt.IsZero() && time.Since(c.firedancerDetectedAt) < c.config.FiredancerDetectionTTL {
+		return
+	}
 
-	// Collect version and firedancer status
-	c.logger.Info("Collecting version...")
-	version, err := c.rpcClient.GetVersion(ctx)
-	isFiredancer := "0"
+	isFiredancer := false
+	resp, err := c.rpcClient.GetFiredancerMetrics(ctx)
 	if err == nil {
-		resp, ferr := c.rpcClient.GetFiredancerMetrics(ctx)
-		if ferr == nil && resp.StatusCode == 200 {
-			isFiredancer = "1"
-			c.isFiredancer = true
-		}
+		//goland:noinspection GoUnhandledErrorResult
+		defer resp.Body.Close()
+		isFiredancer = resp.StatusCode == http.StatusOK
 	}
-	if err != nil {
-		c.logger.Errorf("failed to get version: %v", err)
-		ch <- c.NodeVersion.NewInvalidMetric(err)
-	} else {
-		ch <- c.NodeVersion.MustNewConstMetric(1, version, isFiredancer)
+	if !c.firedancerDetectedAt.IsZero() && isFiredancer != c.isFiredancer.Load() {
+		c.logger.Infow("firedancer detection changed", "is_firedancer", isFiredancer)
 	}
-	c.logger.Info("Version collected.")
+	c.isFiredancer.Store(isFiredancer)
+	c.firedancerDetectedAt = time.Now()
+}
 
-	c.collectIdentity(ctx, ch)
-	c.collectBalances(ctx, ch)
+func (c *SolanaCollector) collectIsFiredancer(ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorFiredancer) {
+		return
+	}
+	ch <- c.NodeIsFiredancer.MustNewConstMetric(BoolToFloat64(c.isFiredancer.Load()))
+}
 
-	// Collect foundation min required version
-	c.logger.Info("Collecting minimum required version...")
-	genesisHash, err := c.rpcClient.GetGenesisHash(ctx)
-	cluster := ""
-	if err == nil {
-		cluster, err = rpc.GetClusterFromGenesisHash(genesisHash)
+func (c *SolanaCollector) collectMinRequiredVersion(
+	ctx context.Context, ch chan<- prometheus.Metric, info *scrapeNodeInfo,
+) {
+	if !c.collectorEnabled(CollectorMinRequiredVersion) {
+		return
 	}
-	agaveMinVersion, minVerCluster, epoch, firedancerMinVersion, minVerErr := "", "", 0, "", err
-	if err == nil {
-		agaveMinVersion, minVerCluster, epoch, firedancerMinVersion, minVerErr = c.apiClient.GetMinRequiredVersion(ctx, cluster)
+	c.logger.Log(c.collectLogLevel, "Collecting minimum required version...")
+	var required *api.RequiredVersionInfo
+	minVerErr := info.clusterErr
+	if info.clusterErr == nil {
+		required, minVerErr = c.apiClient.GetMinRequiredVersion(ctx, info.cluster)
+		c.collectFoundationAPIStatus(ch)
+	} else {
+		ch <- c.FoundationAPIUp.NewInvalidMetric(info.clusterErr)
+		ch <- c.FoundationAPICacheAge.NewInvalidMetric(info.clusterErr)
 	}
 	if minVerErr != nil {
 		c.logger.Errorf("failed to get min required version: %v", minVerErr)
 		ch <- c.FoundationMinRequiredVersion.NewInvalidMetric(minVerErr)
+		ch <- c.FoundationMinRequiredVersionNumeric.NewInvalidMetric(minVerErr)
+		ch <- c.FoundationVersionInherited.NewInvalidMetric(minVerErr)
+		return
+	}
+
+	ch <- c.FoundationMinRequiredVersion.MustNewConstMetric(
+		1, required.AgaveMinVersion, required.FiredancerMinVersion, required.Cluster, fmt.Sprintf("%d", required.Epoch),
+	)
+	ch <- c.FoundationVersionInherited.MustNewConstMetric(
+		BoolToFloat64(required.InheritedFromPrevEpoch), required.Cluster, fmt.Sprintf("%d", required.Epoch),
+	)
+	for client, version := range map[string]string{
+		ClientAgave: required.AgaveMinVersion, ClientFiredancer: required.FiredancerMinVersion,
+	} {
+		if versionNumber, err := parseVersionToNumber(version); err != nil {
+			c.logger.Errorf("failed to parse %s min required version: %v", client, err)
+			ch <- c.FoundationMinRequiredVersionNumeric.NewInvalidMetric(err)
+		} else {
+			ch <- c.FoundationMinRequiredVersionNumeric.MustNewConstMetric(versionNumber, client)
+		}
+	}
+	c.logger.Log(c.collectLogLevel, "Minimum required version collected.")
+}
+
+// collectFoundationAPIStatus reports whether the foundation API is reachable, and how stale the cached required
+// versions are (if any were ever fetched).
+func (c *SolanaCollector) collectFoundationAPIStatus(ch chan<- prometheus.Metric) {
+	ch <- c.FoundationAPIUp.MustNewConstMetric(BoolToFloat64(c.apiClient.Up()))
+	if age, ok := c.apiClient.CacheAge(); ok {
+		ch <- c.FoundationAPICacheAge.MustNewConstMetric(age.Seconds())
+	}
+}
+
+// collectRpcPortReachable reports the outcome of the latest rpc port probes (see probeRpcPorts), with one
+// solana_node_rpc_port_reachable series per probed node.
+func (c *SolanaCollector) collectRpcPortReachable(ctx context.Context, ch chan<- prometheus.Metric) {
+	if !c.collectorEnabled(CollectorRpcPortProbe) || c.config.ProbeRpcPorts == "" {
+		return
+	}
+	c.logger.Log(c.collectLogLevel, "Collecting rpc port reachability...")
+	// the probes run in the background, as probing the whole gossip table can take minutes:
+	ports, ok, err := c.rpcPortProbe.Get(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to probe rpc ports: %v", err)
+		ch <- c.NodeRpcPortReachable.NewInvalidMetric(err)
+		return
+	}
+	if !ok {
+		c.logger.Warn("The first rpc port probes have not completed yet.")
+		return
+	}
+	for _, port := range ports {
+		ch <- c.NodeRpcPortReachable.MustNewConstMetric(BoolToFloat64(port.reachable), port.nodekey)
+	}
+	c.logger.Log(c.collectLogLevel, "Rpc port reachability collected.")
+}
+
+// rpcPortReachability is whether the rpc port advertised in gossip by the node with nodekey answered a probe.
+type rpcPortReachability struct {
+	nodekey   string
+	reachable bool
+}
+
+// probeRpcPorts probes the rpc port advertised in gossip by the node (or by every node, depending on
+// -probe-rpc-ports) with a getHealth request.
+func (c *SolanaCollector) probeRpcPorts(ctx context.Context) ([]rpcPortReachability, error) {
+	nodes, err := c.rpcClient.GetClusterNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+	if c.config.ProbeRpcPorts == RpcPortProbeSelf {
+		identity, err := c.rpcClient.GetIdentity(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get identity: %w", err)
+		}
+		nodes = slices.DeleteFunc(nodes, func(node rpc.ContactInfo) bool { return node.Pubkey != identity })
+	}
+
+	// the probes are bounded by rpcPortProbeConcurrency, as the gossip table can list thousands of nodes:
+	var (
+		wg     sync.WaitGroup
+		slots  = make(chan struct{}, rpcPortProbeConcurrency)
+		ports  []rpcPortReachability
+		portMu sync.Mutex
+	)
+	for _, node := range nodes {
+		// nodes which do not advertise an rpc port are covered by solana_node_advertised_port:
+		if node.Rpc == nil {
+			continue
+		}
+		// the slot is taken before starting the goroutine, so that at most rpcPortProbeConcurrency are running:
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			err := ProbeRpcPort(ctx, c.rpcPortProbeClient, *node.Rpc)
+			if err != nil {
+				c.logger.Debugf("rpc port %s of %s is unreachable: %v", *node.Rpc, node.Pubkey, err)
+			}
+			portMu.Lock()
+			defer portMu.Unlock()
+			ports = append(ports, rpcPortReachability{nodekey: node.Pubkey, reachable: err == nil})
+		}()
+	}
+	wg.Wait()
+	return ports, nil
+}
+
+// fetchNodeInfo fetches the version and cluster of the node into info, if they are needed by any enabled collector.
+// The cluster is detected from the genesis hash of the node, unless it is overridden with -cluster-name.
+// The returned channels are closed once the version and cluster (respectively) have been fetched.
+func (c *SolanaCollector) fetchNodeInfo(
+	ctx context.Context, pool *collectorPool, info *scrapeNodeInfo,
+) (versionFetched, clusterFetched <-chan struct{}) {
+	versionDone, clusterDone := make(chan struct{}), make(chan struct{})
+	if c.anyCollectorEnabled(
+		CollectorVersion,
+		CollectorNodeIsOutdated,
+		CollectorNodeNeedsUpdate,
+		CollectorNodeAboveMaxVersion,
+		CollectorGossip,
+	) {
+		pool.Go(func() {
+			defer close(versionDone)
+			version, err := c.rpcClient.GetVersion(ctx)
+			if err != nil {
+				c.recordRPCError(err)
+				info.versionErr = err
+			} else {
+				info.version, info.featureSet = version.SolanaCore, version.FeatureSet
+			}
+		})
+	} else {
+		close(versionDone)
+	}
+	if c.anyCollectorEnabled(
+		CollectorMinRequiredVersion, CollectorNodeIsOutdated, CollectorNodeNeedsUpdate, CollectorNodeAboveMaxVersion,
+		CollectorCluster,
+	) {
+		pool.Go(func() {
+			defer close(clusterDone)
+			genesisHash, err := c.rpcClient.GetGenesisHash(ctx)
+			if err != nil {
+				c.recordRPCError(err)
+				info.clusterErr = err
+			} else {
+				info.genesisHash = genesisHash
+				if c.config.ClusterName != "" {
+					info.cluster = c.config.ClusterName
+				} else {
+					info.cluster, info.clusterErr = rpc.GetClusterFromGenesisHash(genesisHash)
+				}
+			}
+			c.lastClusterMu.Lock()
+			defer c.lastClusterMu.Unlock()
+			if info.clusterErr != nil {
+				c.logger.Errorw("failed to determine cluster", "error", info.clusterErr, "last_cluster", c.lastCluster)
+				info.lastCluster = c.lastCluster
+			} else {
+				c.lastCluster = info.cluster
+			}
+		})
 	} else {
-		ch <- c.FoundationMinRequiredVersion.MustNewConstMetric(1, agaveMinVersion, firedancerMinVersion, minVerCluster, fmt.Sprintf("%d", epoch))
+		close(clusterDone)
+	}
+	return versionDone, clusterDone
+}
+
+// clusterOrDefault returns the cluster of the node. If it could not be determined, this falls back to the cluster of
+// the last successful detection, or to mainnet-beta if there was none.
+func (i *scrapeNodeInfo) clusterOrDefault() string {
+	switch {
+	case i.clusterErr == nil:
+		return i.cluster
+	case i.lastCluster != "":
+		return i.lastCluster
+	default:
+		return "mainnet-beta"
+	}
+}
+
+func (c *SolanaCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext is like Collect, but the rpc and API calls of the collectors are made with ctx, so that they are
+// aborted once ctx is done (e.g., when the scrape times out, see NewMetricsHandler).
+func (c *SolanaCollector) CollectContext(ctx context.Context, ch chan<- prometheus.Metric) {
+	c.logger.Log(c.collectLogLevel, "========== BEGIN COLLECTION ==========")
+	start := time.Now()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c.scrapeFailed.Store(false)
+	ch, closeFiltered := c.filterDisabledMetrics(ch)
+	defer closeFiltered()
+	// the metrics of the collectors are forwarded to out, recording the collectors which emit invalid metrics:
+	out := ch
+	ch, failedCollectors := c.findFailedCollectors(out)
+
+	pool := newCollectorPool(c.config.MaxConcurrentRPC)
+	run := func(name string, collect func(), after ...<-chan struct{}) {
+		pool.Go(func() { c.timeCollector(name, ch, collect) }, after...)
 	}
-	c.logger.Info("Minimum required version collected.")
 
-	// Collect NodeIsOutdated metric
-	c.collectNodeIsOutdated(ch)
+	// firedancer detection is required by the version and compliance collectors, which run once it is done:
+	firedancerDetected := make(chan struct{})
+	if c.anyCollectorEnabled(
+		CollectorFiredancer, CollectorVersion, CollectorNodeIsOutdated, CollectorNodeNeedsUpdate,
+		CollectorNodeAboveMaxVersion,
+	) {
+		pool.Go(func() {
+			defer close(firedancerDetected)
+			c.detectFiredancer(ctx)
+		})
+	} else {
+		close(firedancerDetected)
+	}
+	// the node version and cluster are shared by several collectors, so they are only fetched once:
+	var info scrapeNodeInfo
+	versionFetched, clusterFetched := c.fetchNodeInfo(ctx, pool, &info)
 
-	// Collect NodeNeedsUpdate metric
-	c.collectNodeNeedsUpdate(ch)
+	run(CollectorHealth, func() { c.collectHealth(ctx, ch) })
+	run(CollectorMinimumLedgerSlot, func() { c.collectMinimumLedgerSlot(ctx, ch) })
+	run(CollectorFirstAvailableBlock, func() { c.collectFirstAvailableBlock(ctx, ch) })
+	run(CollectorVoteAccounts, func() { c.collectVoteAccounts(ctx, ch) })
+	run(CollectorVersion, func() { c.collectVersion(ch, &info) }, firedancerDetected, versionFetched)
+	run(CollectorIdentity, func() { c.collectIdentity(ctx, ch) })
+	run(CollectorBalances, func() { c.collectBalances(ctx, ch) })
+	run(CollectorMinRequiredVersion, func() { c.collectMinRequiredVersion(ctx, ch, &info) }, clusterFetched)
+	run(
+		CollectorNodeIsOutdated, func() { c.collectNodeIsOutdated(ctx, ch, &info) },
+		firedancerDetected, versionFetched, clusterFetched,
+	)
+	run(
+		CollectorNodeNeedsUpdate, func() { c.collectNodeNeedsUpdate(ctx, ch, &info) },
+		firedancerDetected, versionFetched, clusterFetched,
+	)
+	run(
+		CollectorNodeAboveMaxVersion, func() { c.collectNodeAboveMaxVersion(ctx, ch, &info) },
+		firedancerDetected, versionFetched, clusterFetched,
+	)
+	run(CollectorFiredancer, func() { c.collectIsFiredancer(ch) }, firedancerDetected)
+	run(CollectorStakeAccounts, func() { c.collectStakeAccounts(ctx, ch) })
+	run(CollectorBlockTimeLag, func() { c.collectBlockTimeLag(ctx, ch) })
+	run(CollectorSnapshotSlots, func() { c.collectSnapshotSlots(ctx, ch) })
+	run(CollectorLargestAccounts, func() { c.collectLargestAccounts(ctx, ch) })
+	run(CollectorTokenAccounts, func() { c.collectTokenAccounts(ctx, ch) })
+	run(CollectorStakePool, func() { c.collectStakePool(ctx, ch) })
+	run(CollectorNextLeaderSlot, func() { c.collectNextLeaderSlot(ctx, ch) })
+	run(CollectorEpochCountdown, func() { c.collectEpochCountdown(ctx, ch) })
+	run(CollectorTransactionCount, func() { c.collectTransactionCount(ctx, ch) })
+	run(CollectorReference, func() { c.collectReference(ctx, ch) })
+	run(CollectorGossip, func() { c.collectGossip(ctx, ch, &info) }, versionFetched)
+	run(CollectorConfirmationProbe, func() { c.collectConfirmationLatency(ctx, ch) })
+	run(CollectorRpcPortProbe, func() { c.collectRpcPortReachable(ctx, ch) })
+	run(CollectorCommitmentSlots, func() { c.collectCommitmentSlots(ctx, ch) })
+	run(CollectorClusterSkipRate, func() { c.collectClusterSkipRate(ctx, ch) })
+	run(CollectorRentExempt, func() { c.collectRentExempt(ctx, ch) })
+	run(CollectorCluster, func() { c.collectCluster(ch, &info) }, clusterFetched)
+	run(CollectorPrograms, func() { c.collectPrograms(ctx, ch) })
+	run(CollectorStakeMinimum, func() { c.collectStakeMinimumDelegation(ctx, ch) })
+	run(CollectorPerformanceSamples, func() { c.collectPerformanceSamples(ctx, ch) })
+	pool.Wait()
+	c.emitCollectorErrors(out, failedCollectors())
 
-	c.logger.Info("=========== END COLLECTION ===========")
+	if !c.scrapeFailed.Load() {
+		c.lastSuccessfulScrape.Store(time.Now().UnixNano())
+	}
+	out <- c.ScrapeDuration.MustNewConstMetric(time.Since(start).Seconds())
+	out <- c.BuildInfo.MustNewConstMetric(1, version.Version, version.Commit, version.GoVersion)
+	out <- c.CommitmentConfig.MustNewConstMetric(
+		1,
+		string(c.voteAccountsCommitment()),
+		string(c.slotCommitment()),
+		string(c.config.SlotWatcherCommitment()),
+		string(c.balancesCommitment()),
+	)
+	c.logger.Log(c.collectLogLevel, "=========== END COLLECTION ===========")
 }
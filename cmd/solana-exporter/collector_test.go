@@ -6,8 +6,13 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
 	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -15,17 +20,22 @@ import (
 	"github.com/asymmetric-research/solana-exporter/pkg/rpc"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 type (
 	Simulator struct {
 		Server *rpc.MockServer
 
-		Slot             int
-		BlockHeight      int
-		Epoch            int
-		TransactionCount int
+		Slot        int
+		BlockHeight int
+		Epoch       int
+		// transactionCount is read concurrently with Run (see TransactionCount):
+		transactionCount atomic.Int64
 
 		// constants for the simulator
 		SlotTime                time.Duration
@@ -40,7 +50,7 @@ type (
 	}
 )
 
-func NewSimulator(t *testing.T, slot int) (*Simulator, *rpc.Client) {
+func NewSimulator(t testing.TB, slot int) (*Simulator, *rpc.Client) {
 	nodekeys := []string{"aaa", "bbb", "ccc"}
 	votekeys := []string{"AAA", "BBB", "CCC"}
 	feeRewardLamports, inflationRewardLamports := 10, 10
@@ -60,11 +70,22 @@ func NewSimulator(t *testing.T, slot int) (*Simulator, *rpc.Client) {
 	}
 	mockServer, client := rpc.NewMockClient(t,
 		map[string]any{
-			"getVersion":        map[string]string{"solana-core": "v1.0.0"},
-			"getIdentity":       map[string]string{"identity": "testIdentity"},
-			"getLeaderSchedule": leaderSchedule,
-			"getHealth":         "ok",
-			"getGenesisHash":    rpc.MainnetGenesisHash,
+			"getVersion":             map[string]any{"solana-core": "v1.0.0", "feature-set": 3294202862},
+			"getIdentity":            map[string]string{"identity": "testIdentity"},
+			"getLeaderSchedule":      leaderSchedule,
+			"getHealth":              "ok",
+			"getGenesisHash":         rpc.MainnetGenesisHash,
+			"getHighestSnapshotSlot": map[string]any{"full": 20, "incremental": 30},
+			"getEpochSchedule":       map[string]any{"slotsPerEpoch": 24, "warmup": false},
+			"getClusterNodes":        []map[string]any{{"pubkey": "testIdentity"}, {"pubkey": "aaa"}, {"pubkey": "bbb"}},
+			"getStakeMinimumDelegation": map[string]any{
+				"context": map[string]int{"slot": 1}, "value": rpc.LamportsInSol,
+			},
+			// 0.1s per slot:
+			"getRecentPerformanceSamples": []map[string]any{
+				{"slot": 30, "numSlots": 600, "numTransactions": 1000, "samplePeriodSecs": 60},
+				{"slot": 20, "numSlots": 600, "numTransactions": 1000, "samplePeriodSecs": 60},
+			},
 		},
 		nil,
 		map[string]int{
@@ -127,14 +148,23 @@ func (c *Simulator) Run(ctx context.Context) {
 	}
 }
 
+// TransactionCount returns the number of transactions in the blocks produced so far.
+func (c *Simulator) TransactionCount() int {
+	return int(c.transactionCount.Load())
+}
+
 func (c *Simulator) getLeader() string {
-	index := c.Slot % c.EpochSize
+	return c.getLeaderAt(c.Slot)
+}
+
+func (c *Simulator) getLeaderAt(slot int) string {
+	index := slot % c.EpochSize
 	for leader, slots := range c.LeaderSchedule {
 		if slices.Contains(slots, index) {
 			return leader
 		}
 	}
-	panic(fmt.Sprintf("leader not found at slot %d", c.Slot))
+	panic(fmt.Sprintf("leader not found at slot %d", slot))
 }
 
 func (c *Simulator) PopulateSlot(slot int) {
@@ -158,11 +188,19 @@ func (c *Simulator) PopulateSlot(slot int) {
 			c.Server.SetOpt(rpc.ValidatorInfoOpt, nodekey, info)
 		}
 
-		c.TransactionCount += len(transactions)
-		block = &rpc.MockBlockInfo{Fee: c.FeeRewardLamports, Transactions: transactions}
+		c.transactionCount.Add(int64(len(transactions)))
+		block = &rpc.MockBlockInfo{
+			Fee: c.FeeRewardLamports, Transactions: transactions, BlockTime: time.Now().Unix(),
+		}
 	}
 	// add slot info:
 	c.Server.SetOpt(rpc.SlotInfosOpt, slot, rpc.MockSlotInfo{Leader: leader, Block: block})
+	// and the leaders of the upcoming slots, until the end of the next epoch:
+	var upcomingLeaders []string
+	for i := slot; i < (slot/c.EpochSize+2)*c.EpochSize; i++ {
+		upcomingLeaders = append(upcomingLeaders, c.getLeaderAt(i))
+	}
+	c.Server.SetOpt(rpc.EasyResultsOpt, "getSlotLeaders", upcomingLeaders)
 
 	// now update the server:
 	c.Epoch = int(math.Floor(float64(slot) / float64(c.EpochSize)))
@@ -180,9 +218,11 @@ func (c *Simulator) PopulateSlot(slot int) {
 			"epoch":            c.Epoch,
 			"slotIndex":        slot % c.EpochSize,
 			"slotsInEpoch":     c.EpochSize,
-			"transactionCount": c.TransactionCount,
+			"transactionCount": c.TransactionCount(),
 		},
 	)
+	c.Server.SetOpt(rpc.EasyResultsOpt, "getTransactionCount", c.TransactionCount())
+	c.Server.SetOpt(rpc.EasyResultsOpt, "getMaxShredInsertSlot", slot)
 	c.Server.SetOpt(
 		rpc.EasyResultsOpt,
 		"minimumLedgerSlot",
@@ -195,6 +235,20 @@ func (c *Simulator) PopulateSlot(slot int) {
 	)
 }
 
+// newFiredancerMetricsServer points the Firedancer metrics endpoint of client to a mock server, which responds as a
+// Firedancer node would whenever the returned flag is set.
+func newFiredancerMetricsServer(t *testing.T, client *rpc.Client) *atomic.Bool {
+	var isFiredancer atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isFiredancer.Load() {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	client.FiredancerMetricsPort = server.Listener.Addr().(*net.TCPAddr).Port
+	return &isFiredancer
+}
+
 func newTestConfig(simulator *Simulator, fast bool) *ExporterConfig {
 	pace := time.Duration(100) * time.Second
 	if fast {
@@ -216,7 +270,9 @@ func newTestConfig(simulator *Simulator, fast bool) *ExporterConfig {
 		// we need to set the epoch cleanup time to long enough such that we can test that the final state for the
 		// previous epoch is correct before cleaning it. Ideally I would like a better way of doing this than simply
 		// "waiting long enough", but this should do for now
-		EpochCleanupTime: 5 * time.Second,
+		EpochCleanupTime:       5 * time.Second,
+		MaxConcurrentRPC:       4,
+		VoteAccountsCommitment: rpc.CommitmentConfirmed,
 	}
 	return &config
 }
@@ -229,6 +285,7 @@ func TestSolanaCollector(t *testing.T) {
 	// Create and configure mock API client
 	mockAPIClient := api.NewMockClient()
 	mockAPIClient.SetMinRequiredVersion("2.2.14", "0.503.20214")
+	mockAPIClient.SetNextEpochMinRequiredVersion("2.2.15", "0.503.20215")
 	collector.apiClient = mockAPIClient
 
 	prometheus.NewPedanticRegistry().MustRegister(collector)
@@ -237,47 +294,97 @@ func TestSolanaCollector(t *testing.T) {
 
 	testCases := []collectionTest{
 		collector.ValidatorActiveStake.makeCollectionTest(
-			NewLV(stake, "aaa", "AAA"),
-			NewLV(stake, "bbb", "BBB"),
-			NewLV(stake, "ccc", "CCC"),
+			NewLV(stake, "", "aaa", "AAA"),
+			NewLV(stake, "", "bbb", "BBB"),
+			NewLV(stake, "", "ccc", "CCC"),
 		),
 		collector.ClusterActiveStake.makeCollectionTest(
 			NewLV(3 * stake),
 		),
 		collector.ValidatorLastVote.makeCollectionTest(
-			NewLV(33, "aaa", "AAA"),
-			NewLV(32, "bbb", "BBB"),
-			NewLV(31, "ccc", "CCC"),
+			NewLV(33, "", "aaa", "AAA"),
+			NewLV(32, "", "bbb", "BBB"),
+			NewLV(31, "", "ccc", "CCC"),
+		),
+		// slot 35 was skipped, so the last votes are those of slot 34 (1, 2 and 3 slots behind it):
+		collector.ValidatorLastVoteAge.makeCollectionTest(
+			NewLV(2, "", "aaa", "AAA"),
+			NewLV(3, "", "bbb", "BBB"),
+			NewLV(4, "", "ccc", "CCC"),
 		),
 		collector.ClusterLastVote.makeCollectionTest(
 			NewLV(33),
 		),
 		collector.ValidatorRootSlot.makeCollectionTest(
-			NewLV(30, "aaa", "AAA"),
-			NewLV(29, "bbb", "BBB"),
-			NewLV(28, "ccc", "CCC"),
+			NewLV(30, "", "aaa", "AAA"),
+			NewLV(29, "", "bbb", "BBB"),
+			NewLV(28, "", "ccc", "CCC"),
+		),
+		// likewise, the root slots are those of slot 34 (4, 5 and 6 slots behind it):
+		collector.ValidatorRootSlotLag.makeCollectionTest(
+			NewLV(5, "", "aaa", "AAA"),
+			NewLV(6, "", "bbb", "BBB"),
+			NewLV(7, "", "ccc", "CCC"),
 		),
 		collector.ClusterRootSlot.makeCollectionTest(
 			NewLV(30),
 		),
 		collector.ValidatorDelinquent.makeCollectionTest(
-			NewLV(0, "aaa", "AAA"),
-			NewLV(0, "bbb", "BBB"),
-			NewLV(0, "ccc", "CCC"),
+			NewLV(0, "", "aaa", "AAA"),
+			NewLV(0, "", "bbb", "BBB"),
+			NewLV(0, "", "ccc", "CCC"),
+		),
+		collector.ValidatorFound.makeCollectionTest(
+			NewLV(1, "", "aaa"),
+			NewLV(1, "", "bbb"),
+			NewLV(1, "", "ccc"),
+		),
+		collector.ValidatorIdentityVotePair.makeCollectionTest(
+			NewLV(1, "aaa", "AAA"),
+			NewLV(1, "bbb", "BBB"),
+			NewLV(1, "ccc", "CCC"),
+		),
+		collector.TrackedValidatorsDelinquent.makeCollectionTest(
+			NewLV(0),
 		),
 		collector.ClusterValidatorCount.makeCollectionTest(
 			NewLV(3, StateCurrent),
 			NewLV(0, StateDelinquent),
 		),
+		collector.ClusterDelinquentStake.makeCollectionTest(
+			NewLV(0),
+		),
+		collector.ValidatorIsSuperminority.makeCollectionTest(
+			NewLV(1, "", "aaa", "AAA"),
+			NewLV(1, "", "bbb", "BBB"),
+			NewLV(0, "", "ccc", "CCC"),
+		),
+		collector.ValidatorCreditsRank.makeCollectionTest(
+			NewLV(1, "", "aaa", "AAA"),
+			NewLV(1, "", "bbb", "BBB"),
+			NewLV(1, "", "ccc", "CCC"),
+		),
+		collector.ClusterDelinquentStakePercent.makeCollectionTest(
+			NewLV(0),
+		),
 		collector.NodeVersion.makeCollectionTest(
 			NewLV(1, "0", "v1.0.0"),
 		),
+		collector.NodeVersionNumeric.makeCollectionTest(
+			NewLV(1_00000_00000, ClientAgave),
+		),
+		collector.NodeFeatureSet.makeCollectionTest(
+			NewLV(3294202862),
+		),
 		collector.NodeIdentity.makeCollectionTest(
 			NewLV(1, "testIdentity"),
 		),
 		collector.NodeIsActive.makeCollectionTest(
 			NewLV(0, "testIdentity"),
 		),
+		collector.NodeIdentityMatchesConfigured.makeCollectionTest(
+			NewLV(0, "testIdentity"),
+		),
 		collector.NodeIsHealthy.makeCollectionTest(
 			NewLV(1),
 		),
@@ -285,12 +392,12 @@ func TestSolanaCollector(t *testing.T) {
 			NewLV(0),
 		),
 		collector.AccountBalances.makeCollectionTest(
-			NewLV(4, "AAA"),
-			NewLV(5, "BBB"),
-			NewLV(6, "CCC"),
-			NewLV(1, "aaa"),
-			NewLV(2, "bbb"),
-			NewLV(3, "ccc"),
+			NewLV(4, "AAA", ""),
+			NewLV(5, "BBB", ""),
+			NewLV(6, "CCC", ""),
+			NewLV(1, "aaa", ""),
+			NewLV(2, "bbb", ""),
+			NewLV(3, "ccc", ""),
 		),
 		collector.NodeMinimumLedgerSlot.makeCollectionTest(
 			NewLV(11),
@@ -298,9 +405,40 @@ func TestSolanaCollector(t *testing.T) {
 		collector.NodeFirstAvailableBlock.makeCollectionTest(
 			NewLV(11),
 		),
+		collector.NodeLedgerSpanSlots.makeCollectionTest(
+			NewLV(24),
+		),
 		collector.FoundationMinRequiredVersion.makeCollectionTest(
 			NewLV(1, "2.2.14", "mainnet-beta", "797", "0.503.20214"),
 		),
+		collector.FoundationMinRequiredVersionNumeric.makeCollectionTest(
+			NewLV(2_00002_00014, ClientAgave),
+			NewLV(503_20214, ClientFiredancer),
+		),
+		collector.FoundationVersionInherited.makeCollectionTest(
+			NewLV(0, "mainnet-beta", "797"),
+		),
+		collector.FoundationAPIUp.makeCollectionTest(
+			NewLV(1),
+		),
+		collector.NodeNextLeaderSlot.makeCollectionTest(
+			NewLV(1, "", "aaa"),
+			NewLV(5, "", "bbb"),
+			NewLV(0, "", "ccc"),
+		),
+		// slot 35 is the 12th slot of epoch 1, which leaves 13 slots of 0.1s:
+		collector.NodeEpochSecondsRemaining.makeCollectionTest(
+			NewLV(1.3),
+		),
+		collector.NodeEpochElapsedSlots.makeCollectionTest(
+			NewLV(11),
+		),
+		collector.NodeHighestFullSnapshotSlot.makeCollectionTest(
+			NewLV(20),
+		),
+		collector.NodeHighestIncrementalSnapshotSlot.makeCollectionTest(
+			NewLV(30),
+		),
 	}
 
 	for _, test := range testCases {
@@ -316,6 +454,10 @@ func TestSolanaCollector_collectHealth(t *testing.T) {
 	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getGenesisHash", rpc.MainnetGenesisHash)
 
 	collector := NewSolanaCollector(client, newTestConfig(simulator, false))
+	mockAPIClient := api.NewMockClient()
+	mockAPIClient.SetMinRequiredVersion("2.2.14", "0.503.20214")
+	mockAPIClient.SetNextEpochMinRequiredVersion("2.2.15", "0.503.20215")
+	collector.apiClient = mockAPIClient
 	prometheus.NewPedanticRegistry().MustRegister(collector)
 
 	t.Run("healthy", func(t *testing.T) {
@@ -387,6 +529,15 @@ solana_node_is_outdated{cluster="mainnet-beta",epoch="797",is_firedancer="1",req
 solana_node_is_outdated{cluster="mainnet-beta",epoch="797",is_firedancer="1",required_version="0.503.20214",version="1.2.0"} 0
 `,
 		},
+		{
+			// the node must not be reported as up-to-date without a required version to compare to:
+			name:           "firedancer without required version",
+			isFiredancer:   true,
+			version:        "0.9.0",
+			agaveVer:       "2.2.14",
+			firedancerVer:  "",
+			expectedOutput: "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -399,6 +550,23 @@ solana_node_is_outdated{cluster="mainnet-beta",epoch="797",is_firedancer="1",req
 					"getIdentity":            map[string]string{"identity": "testIdentity"},
 					"minimumLedgerSlot":      0,
 					"getFirstAvailableBlock": 0,
+					"getSlot":                0,
+					"getMaxShredInsertSlot":  0,
+					"getBlockTime":           0,
+					"getHighestSnapshotSlot": map[string]any{"full": 0, "incremental": nil},
+					"getEpochSchedule":       map[string]any{"slotsPerEpoch": 432000},
+					"getTransactionCount":    0,
+					"getStakeMinimumDelegation": map[string]any{
+						"context": map[string]int{"slot": 0}, "value": 0,
+					},
+					"getClusterNodes": []map[string]any{{"pubkey": "testIdentity"}},
+					"getBlockProduction": map[string]any{
+						"context": map[string]int{"slot": 0},
+						"value":   map[string]any{"byIdentity": map[string]any{}, "range": map[string]int{}},
+					},
+					"getRecentPerformanceSamples": []map[string]any{
+						{"numSlots": 150, "samplePeriodSecs": 60},
+					},
 					"getEpochInfo": map[string]int{
 						"epoch": 797,
 					},
@@ -414,12 +582,15 @@ solana_node_is_outdated{cluster="mainnet-beta",epoch="797",is_firedancer="1",req
 				nil,
 			)
 
-			collector := NewSolanaCollector(client, &ExporterConfig{})
-			collector.isFiredancer = tt.isFiredancer
+			newFiredancerMetricsServer(t, client).Store(tt.isFiredancer)
+			// the numeric minimum required version fails to parse an empty version, so only this metric is collected:
+			config := &ExporterConfig{MaxConcurrentRPC: 1, EnabledMetrics: []string{"solana_node_is_outdated"}}
+			collector := NewSolanaCollector(client, config)
 
 			// Create and configure mock API client
 			mockAPIClient := api.NewMockClient()
 			mockAPIClient.SetMinRequiredVersion(tt.agaveVer, tt.firedancerVer)
+			mockAPIClient.SetNextEpochMinRequiredVersion(tt.agaveVer, tt.firedancerVer)
 			collector.apiClient = mockAPIClient
 
 			if err := testutil.CollectAndCompare(collector, strings.NewReader(tt.expectedOutput), "solana_node_is_outdated"); err != nil {
@@ -496,6 +667,16 @@ solana_node_needs_update{cluster="mainnet-beta",epoch="798",is_firedancer="0",re
 solana_node_needs_update{cluster="mainnet-beta",epoch="798",is_firedancer="0",required_version="2.2.15",version="2.2.15"} 0
 `,
 		},
+		{
+			name:              "firedancer without next required version",
+			isFiredancer:      true,
+			version:           "0.9.0",
+			agaveVer:          "2.2.14",
+			firedancerVer:     "0.503.20214",
+			nextAgaveVer:      "2.2.15",
+			nextFiredancerVer: "",
+			expectedOutput:    "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -508,6 +689,23 @@ solana_node_needs_update{cluster="mainnet-beta",epoch="798",is_firedancer="0",re
 					"getIdentity":            map[string]string{"identity": "testIdentity"},
 					"minimumLedgerSlot":      0,
 					"getFirstAvailableBlock": 0,
+					"getSlot":                0,
+					"getMaxShredInsertSlot":  0,
+					"getBlockTime":           0,
+					"getHighestSnapshotSlot": map[string]any{"full": 0, "incremental": nil},
+					"getEpochSchedule":       map[string]any{"slotsPerEpoch": 432000},
+					"getTransactionCount":    0,
+					"getStakeMinimumDelegation": map[string]any{
+						"context": map[string]int{"slot": 0}, "value": 0,
+					},
+					"getClusterNodes": []map[string]any{{"pubkey": "testIdentity"}},
+					"getBlockProduction": map[string]any{
+						"context": map[string]int{"slot": 0},
+						"value":   map[string]any{"byIdentity": map[string]any{}, "range": map[string]int{}},
+					},
+					"getRecentPerformanceSamples": []map[string]any{
+						{"numSlots": 150, "samplePeriodSecs": 60},
+					},
 					"getEpochInfo": map[string]int{
 						"epoch": 797,
 					},
@@ -523,8 +721,8 @@ solana_node_needs_update{cluster="mainnet-beta",epoch="798",is_firedancer="0",re
 				nil,
 			)
 
-			collector := NewSolanaCollector(client, &ExporterConfig{})
-			collector.isFiredancer = tt.isFiredancer
+			newFiredancerMetricsServer(t, client).Store(tt.isFiredancer)
+			collector := NewSolanaCollector(client, &ExporterConfig{MaxConcurrentRPC: 1})
 
 			// Create and configure mock API client
 			mockAPIClient := api.NewMockClient()
@@ -538,3 +736,1698 @@ solana_node_needs_update{cluster="mainnet-beta",epoch="798",is_firedancer="0",re
 		})
 	}
 }
+
+func TestSolanaCollector_NodeNeedsUpdate_NullFiredancerVersion(t *testing.T) {
+	// the foundation API has no firedancer min version for the next epoch (2):
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data": [{
+			"cluster": "mainnet-beta", "epoch": 2, "agave_min_version": "2.2.16", "firedancer_min_version": null
+		}]}`))
+	}))
+	defer server.Close()
+
+	for _, isFiredancer := range []bool{false, true} {
+		simulator, client := NewSimulator(t, 35)
+		config := newTestConfig(simulator, false)
+		config.EnabledMetrics = []string{"solana_node_needs_update"}
+		config.RequiredVersionsAPIURL = server.URL
+		newFiredancerMetricsServer(t, client).Store(isFiredancer)
+		collector := NewSolanaCollector(client, config)
+
+		// agave nodes are still compared to the agave min version, whereas firedancer nodes are left out:
+		expected := ""
+		if !isFiredancer {
+			expected = `
+# HELP solana_node_needs_update Whether the node needs to be updated before the next epoch to remain compliant
+# TYPE solana_node_needs_update gauge
+solana_node_needs_update{cluster="mainnet-beta",epoch="2",is_firedancer="0",required_version="2.2.16",version="v1.0.0"} 1
+`
+		}
+		err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "solana_node_needs_update")
+		assert.NoError(t, err, "is_firedancer=%v", isFiredancer)
+	}
+}
+
+func TestSolanaCollector_DisabledMetrics(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+
+	tests := []struct {
+		name            string
+		disabledMetrics []string
+		enabledMetrics  []string
+		absent          []string
+		present         []string
+	}{
+		{
+			name:            "disabled metrics",
+			disabledMetrics: []string{"solana_account_balance", "solana_node_first_available_block"},
+			absent:          []string{"solana_account_balance", "solana_node_first_available_block"},
+			present:         []string{"solana_node_is_healthy", "solana_validator_active_stake"},
+		},
+		{
+			name:           "enabled metrics",
+			enabledMetrics: []string{"solana_node_is_healthy", "solana_validator_active_stake"},
+			absent:         []string{"solana_account_balance", "solana_cluster_active_stake", "solana_node_version"},
+			present:        []string{"solana_node_is_healthy", "solana_validator_active_stake"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newTestConfig(simulator, false)
+			config.DisabledMetrics = tt.disabledMetrics
+			config.EnabledMetrics = tt.enabledMetrics
+			collector := NewSolanaCollector(client, config)
+			mockAPIClient := api.NewMockClient()
+			mockAPIClient.SetMinRequiredVersion("2.2.14", "0.503.20214")
+			mockAPIClient.SetNextEpochMinRequiredVersion("2.2.15", "0.503.20215")
+			collector.apiClient = mockAPIClient
+
+			registry := prometheus.NewPedanticRegistry()
+			registry.MustRegister(collector)
+			families, err := registry.Gather()
+			assert.NoError(t, err)
+
+			var names []string
+			for _, family := range families {
+				names = append(names, family.GetName())
+			}
+			for _, name := range tt.absent {
+				assert.NotContains(t, names, name)
+			}
+			for _, name := range tt.present {
+				assert.Contains(t, names, name)
+			}
+		})
+	}
+}
+
+func TestSolanaCollector_IdentityLabels(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.IdentityLabels = map[string]string{"aaa": "alpha", "bbb": "bravo"}
+	collector := NewSolanaCollector(client, config)
+	mockAPIClient := api.NewMockClient()
+	mockAPIClient.SetMinRequiredVersion("2.2.14", "0.503.20214")
+	mockAPIClient.SetNextEpochMinRequiredVersion("2.2.15", "0.503.20215")
+	collector.apiClient = mockAPIClient
+	prometheus.NewPedanticRegistry().MustRegister(collector)
+
+	stake := float64(1_000_000) / rpc.LamportsInSol
+	test := collector.ValidatorActiveStake.makeCollectionTest(
+		NewLV(stake, "alpha", "aaa", "AAA"),
+		NewLV(stake, "bravo", "bbb", "BBB"),
+		NewLV(stake, "", "ccc", "CCC"),
+	)
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+}
+
+func TestSolanaCollector_Durations(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false))
+	mockAPIClient := api.NewMockClient()
+	mockAPIClient.SetMinRequiredVersion("2.2.14", "0.503.20214")
+	mockAPIClient.SetNextEpochMinRequiredVersion("2.2.15", "0.503.20215")
+	collector.apiClient = mockAPIClient
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+
+	var collectors []string
+	scrapeDurationFound := false
+	for _, family := range families {
+		switch family.GetName() {
+		case collector.CollectDuration.Name:
+			for _, metric := range family.GetMetric() {
+				collectors = append(collectors, metric.GetLabel()[0].GetValue())
+				assert.GreaterOrEqual(t, metric.GetGauge().GetValue(), 0.0)
+			}
+		case collector.ScrapeDuration.Name:
+			scrapeDurationFound = true
+		}
+	}
+	assert.True(t, scrapeDurationFound)
+	assert.ElementsMatch(t, Collectors, collectors)
+}
+
+func TestSolanaCollector_BuildInfo(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_exporter_build_info"}
+	collector := NewSolanaCollector(client, config)
+
+	test := collector.BuildInfo.makeCollectionTest(NewLV(1, "unknown", runtime.Version(), "dev"))
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoError(t, err)
+}
+
+func TestSolanaCollector_CommitmentConfig(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_exporter_commitment_config"}
+	config.VoteAccountsCommitment = rpc.CommitmentProcessed
+	collector := NewSolanaCollector(client, config)
+
+	// the slot watcher follows finalized slots, while the collector reads confirmed ones:
+	test := collector.CommitmentConfig.makeCollectionTest(
+		NewLV(1, "confirmed", "confirmed", "finalized", "processed"),
+	)
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoError(t, err)
+
+	// the default commitment applies to all the groups without an override:
+	config.DefaultCommitment = rpc.CommitmentProcessed
+	config.VoteAccountsCommitment = rpc.CommitmentFinalized
+	test = collector.CommitmentConfig.makeCollectionTest(NewLV(1, "processed", "processed", "processed", "finalized"))
+	err = testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoError(t, err)
+
+	// the slot metrics are read at the reported commitment:
+	config.EnabledMetrics = []string{"solana_node_ledger_span_slots"}
+	collector = NewSolanaCollector(client, config)
+	testutil.CollectAndCount(collector)
+	assert.Equal(t, "processed", lastCommitment(simulator, "getSlot"))
+}
+
+func TestSolanaCollector_MetricPrefix(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.MetricPrefix = "myorg_solana_"
+	config.EnabledMetrics = []string{"myorg_solana_node_is_healthy", "myorg_solana_node_num_slots_behind"}
+	collector := NewSolanaCollector(client, config)
+
+	descs := make(chan *prometheus.Desc, 100)
+	collector.Describe(descs)
+	close(descs)
+	var described []string
+	for desc := range descs {
+		described = append(described, desc.String())
+	}
+	assert.NotEmpty(t, described)
+	for _, desc := range described {
+		assert.Contains(t, desc, `fqName: "myorg_solana_`)
+	}
+
+	assert.Equal(t, "myorg_solana_node_is_healthy", collector.NodeIsHealthy.Name)
+	test := collector.NodeIsHealthy.makeCollectionTest(NewLV(1))
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoError(t, err)
+}
+
+func TestSolanaCollector_DisabledCollectors(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.DisabledCollectors = []string{CollectorVoteAccounts}
+	collector := NewSolanaCollector(client, config)
+	mockAPIClient := api.NewMockClient()
+	mockAPIClient.SetMinRequiredVersion("2.2.14", "0.503.20214")
+	mockAPIClient.SetNextEpochMinRequiredVersion("2.2.15", "0.503.20215")
+	collector.apiClient = mockAPIClient
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+
+	var names []string
+	for _, family := range families {
+		names = append(names, family.GetName())
+	}
+	// balances are collected, but not vote accounts:
+	assert.Contains(t, names, "solana_account_balance")
+	assert.NotContains(t, names, "solana_validator_active_stake")
+	assert.NotContains(t, names, "solana_cluster_validator_count")
+	assert.Equal(t, 0, simulator.Server.CallCount("getVoteAccounts"))
+}
+
+func TestSolanaCollector_DisableVersionCompliance(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.DisableVersionCompliance = true
+	collector := NewSolanaCollector(client, config)
+	mockAPIClient := api.NewMockClient()
+	mockAPIClient.SetMinRequiredVersion("2.2.14", "0.503.20214")
+	mockAPIClient.SetNextEpochMinRequiredVersion("2.2.15", "0.503.20215")
+	collector.apiClient = mockAPIClient
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+
+	var complianceMetrics []string
+	for _, name := range VersionComplianceCollectors {
+		for _, desc := range collector.collectorDescs[name] {
+			complianceMetrics = append(complianceMetrics, desc.Name)
+		}
+	}
+	var collectors []string
+	for _, family := range families {
+		assert.NotContains(t, complianceMetrics, family.GetName())
+		if family.GetName() == collector.CollectDuration.Name {
+			for _, metric := range family.GetMetric() {
+				collectors = append(collectors, metric.GetLabel()[0].GetValue())
+			}
+		}
+	}
+	for _, name := range VersionComplianceCollectors {
+		assert.NotContains(t, collectors, name)
+	}
+	assert.Contains(t, collectors, CollectorVersion)
+}
+
+func TestSolanaCollector_Concurrency(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	latency := 300 * time.Millisecond
+	slowMethods := []string{"getHealth", "getMinimumLedgerSlot", "getFirstAvailableBlock", "getIdentity"}
+	for _, method := range slowMethods {
+		simulator.Server.SetOpt(rpc.LatencyOpt, method, latency)
+	}
+
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false))
+	mockAPIClient := api.NewMockClient()
+	mockAPIClient.SetMinRequiredVersion("2.2.14", "0.503.20214")
+	mockAPIClient.SetNextEpochMinRequiredVersion("2.2.15", "0.503.20215")
+	collector.apiClient = mockAPIClient
+
+	start := time.Now()
+	testutil.CollectAndCount(collector)
+	elapsed := time.Since(start)
+
+	// run sequentially, the slow collectors would take at least len(slowMethods) * latency:
+	assert.GreaterOrEqual(t, elapsed, latency)
+	assert.Less(t, elapsed, time.Duration(len(slowMethods))*latency)
+}
+
+func TestSolanaCollector_CollectContext(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	latency := 5 * time.Second
+	for _, method := range []string{"getHealth", "getVoteAccounts", "getIdentity"} {
+		simulator.Server.SetOpt(rpc.LatencyOpt, method, latency)
+	}
+	config := newTestConfig(simulator, false)
+	config.DisableVersionCompliance = true
+	collector := NewSolanaCollector(client, config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		collector.CollectContext(ctx, ch)
+	}()
+	go func() {
+		for range ch {
+		}
+	}()
+
+	// cancelling the scrape mid-collection aborts the in-flight rpc calls:
+	time.Sleep(100 * time.Millisecond)
+	start := time.Now()
+	cancel()
+	select {
+	case <-done:
+		assert.Less(t, time.Since(start), time.Second)
+	case <-time.After(latency):
+		t.Fatal("collection was not aborted by the cancelled context")
+	}
+	close(ch)
+}
+
+func TestSolanaCollector_SingleGetVersion(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getGenesisHash", rpc.MainnetGenesisHash)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false))
+	mockAPIClient := api.NewMockClient()
+	mockAPIClient.SetMinRequiredVersion("2.2.14", "0.503.20214")
+	mockAPIClient.SetNextEpochMinRequiredVersion("2.2.15", "0.503.20215")
+	collector.apiClient = mockAPIClient
+
+	testutil.CollectAndCount(collector)
+	assert.Equal(t, 1, simulator.Server.CallCount("getVersion"))
+	assert.Equal(t, 1, simulator.Server.CallCount("getGenesisHash"))
+}
+
+func TestSolanaCollector_DetectFiredancer(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	isFiredancer := newFiredancerMetricsServer(t, client)
+	config := newTestConfig(simulator, false)
+	collector := NewSolanaCollector(client, config)
+	mockAPIClient := api.NewMockClient()
+	mockAPIClient.SetMinRequiredVersion("2.2.14", "0.503.20214")
+	mockAPIClient.SetNextEpochMinRequiredVersion("2.2.15", "0.503.20215")
+	collector.apiClient = mockAPIClient
+
+	assertIsFiredancer := func(expected float64) {
+		test := collector.NodeIsFiredancer.makeCollectionTest(NewLV(expected))
+		err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+		assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+	}
+
+	// without caching, the detection follows the node across scrapes:
+	isFiredancer.Store(true)
+	assertIsFiredancer(1)
+	isFiredancer.Store(false)
+	assertIsFiredancer(0)
+
+	// whereas with caching, the previous outcome is kept until the TTL expires:
+	config.FiredancerDetectionTTL = time.Hour
+	isFiredancer.Store(true)
+	assertIsFiredancer(0)
+	collector.firedancerDetectedAt = time.Now().Add(-2 * time.Hour)
+	assertIsFiredancer(1)
+	isFiredancer.Store(false)
+	assertIsFiredancer(1)
+}
+
+func TestSolanaCollector_VoteAccountsCommitment(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.VoteAccountsCommitment = rpc.CommitmentFinalized
+	config.EnabledMetrics = []string{"solana_validator_active_stake"}
+	collector := NewSolanaCollector(client, config)
+
+	testutil.CollectAndCount(collector)
+	assert.Equal(
+		t, []any{map[string]any{"commitment": string(rpc.CommitmentFinalized)}},
+		simulator.Server.LastParams("getVoteAccounts"),
+	)
+}
+
+// lastCommitment returns the commitment level of the last request the simulator received for method.
+func lastCommitment(simulator *Simulator, method string) any {
+	params := simulator.Server.LastParams(method)
+	if len(params) == 0 {
+		return nil
+	}
+	config, _ := params[len(params)-1].(map[string]any)
+	return config["commitment"]
+}
+
+func TestSolanaCollector_DefaultCommitment(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.DefaultCommitment = rpc.CommitmentFinalized
+	config.VoteAccountsCommitment = ""
+	config.DisableVersionCompliance = true
+	collector := NewSolanaCollector(client, config)
+
+	testutil.CollectAndCount(collector)
+	for _, method := range []string{"getVoteAccounts", "getMultipleAccounts", "getSlot"} {
+		assert.Equal(t, string(rpc.CommitmentFinalized), lastCommitment(simulator, method), method)
+	}
+
+	// per-metric commitment levels take precedence:
+	config.VoteAccountsCommitment = rpc.CommitmentProcessed
+	testutil.CollectAndCount(collector)
+	assert.Equal(t, string(rpc.CommitmentProcessed), lastCommitment(simulator, "getVoteAccounts"))
+	assert.Equal(t, string(rpc.CommitmentFinalized), lastCommitment(simulator, "getMultipleAccounts"))
+}
+
+func TestSolanaCollector_VoteAccountsFilter(t *testing.T) {
+	tests := []struct {
+		name           string
+		comprehensive  bool
+		enabledMetrics []string
+		filtered       bool
+	}{
+		{
+			name:           "tracked vote accounts only",
+			enabledMetrics: []string{"solana_validator_active_stake"},
+			filtered:       true,
+		},
+		{
+			name:           "comprehensive tracking",
+			comprehensive:  true,
+			enabledMetrics: []string{"solana_validator_active_stake"},
+		},
+		{
+			name:           "cluster metrics enabled",
+			enabledMetrics: []string{"solana_validator_active_stake", "solana_cluster_active_stake"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			simulator, client := NewSimulator(t, 35)
+			config := newTestConfig(simulator, false)
+			config.VoteKeys = simulator.Votekeys[:1]
+			config.NodeKeys = simulator.Nodekeys[:1]
+			config.ComprehensiveVoteAccountTracking = tt.comprehensive
+			config.EnabledMetrics = tt.enabledMetrics
+			collector := NewSolanaCollector(client, config)
+
+			testutil.CollectAndCount(collector)
+			params := simulator.Server.LastParams("getVoteAccounts")[0].(map[string]any)
+			assert.Equal(t, 1, simulator.Server.CallCount("getVoteAccounts"))
+			if tt.filtered {
+				assert.Equal(t, simulator.Votekeys[0], params["votePubkey"])
+			} else {
+				assert.NotContains(t, params, "votePubkey")
+			}
+		})
+	}
+}
+
+func TestSolanaCollector_DelinquentStake(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	info := simulator.Server.GetValidatorInfo("ccc")
+	info.Stake, info.Delinquent = 2_000_000, true
+	simulator.Server.SetOpt(rpc.ValidatorInfoOpt, "ccc", info)
+
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{
+		"solana_cluster_validator_count", "solana_cluster_delinquent_stake", "solana_cluster_delinquent_stake_percent",
+	}
+	collector := NewSolanaCollector(client, config)
+
+	stake := float64(1_000_000) / rpc.LamportsInSol
+	testCases := []collectionTest{
+		collector.ClusterValidatorCount.makeCollectionTest(NewLV(2, StateCurrent), NewLV(1, StateDelinquent)),
+		collector.ClusterDelinquentStake.makeCollectionTest(NewLV(2 * stake)),
+		collector.ClusterDelinquentStakePercent.makeCollectionTest(NewLV(50)),
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+			assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+		})
+	}
+}
+
+func TestSolanaCollector_ValidatorFound(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	// ddd is configured, but has no vote account:
+	config.NodeKeys = []string{"aaa", "ddd"}
+	config.ComprehensiveVoteAccountTracking = false
+	config.EnabledMetrics = []string{"solana_validator_found", "solana_validator_active_stake"}
+	collector := NewSolanaCollector(client, config)
+
+	stake := float64(1_000_000) / rpc.LamportsInSol
+	testCases := []collectionTest{
+		collector.ValidatorFound.makeCollectionTest(NewLV(1, "", "aaa"), NewLV(0, "", "ddd")),
+		collector.ValidatorActiveStake.makeCollectionTest(NewLV(stake, "", "aaa", "AAA")),
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+			assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+		})
+	}
+}
+
+func TestSolanaCollector_TrackedValidatorsDelinquent(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	info := simulator.Server.GetValidatorInfo("ccc")
+	info.Delinquent = true
+	simulator.Server.SetOpt(rpc.ValidatorInfoOpt, "ccc", info)
+
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_tracked_validators_delinquent", "solana_validator_delinquent"}
+	collector := NewSolanaCollector(client, config)
+
+	testCases := []collectionTest{
+		collector.TrackedValidatorsDelinquent.makeCollectionTest(NewLV(1)),
+		collector.ValidatorDelinquent.makeCollectionTest(
+			NewLV(0, "", "aaa", "AAA"), NewLV(0, "", "bbb", "BBB"), NewLV(1, "", "ccc", "CCC"),
+		),
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+			assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+		})
+	}
+}
+
+func TestSolanaCollector_LedgerSpan(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getFirstAvailableBlock", 1_000)
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getSlot", 433_000)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_node_ledger_span_slots"}
+	collector := NewSolanaCollector(client, config)
+
+	test := collector.NodeLedgerSpanSlots.makeCollectionTest(NewLV(432_000))
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+}
+
+func TestSolanaCollector_ValidatorIdentityVotePair(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.NodeKeys = []string{simulator.Nodekeys[0], simulator.Nodekeys[2]}
+	config.VoteKeys = []string{simulator.Votekeys[0], simulator.Votekeys[2]}
+	config.ComprehensiveVoteAccountTracking = false
+	config.EnabledMetrics = []string{"solana_validator_identity_vote_pair"}
+	collector := NewSolanaCollector(client, config)
+
+	// only the tracked validators are exported, each paired with its configured vote account:
+	var expected []LV
+	for i, nodekey := range config.NodeKeys {
+		expected = append(expected, NewLV(1, nodekey, config.VoteKeys[i]))
+	}
+	test := collector.ValidatorIdentityVotePair.makeCollectionTest(expected...)
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+}
+
+func TestSolanaCollector_VoteAccountsProcessed(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	info := simulator.Server.GetValidatorInfo("ccc")
+	info.Delinquent = true
+	simulator.Server.SetOpt(rpc.ValidatorInfoOpt, "ccc", info)
+
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_exporter_vote_accounts_processed"}
+	collector := NewSolanaCollector(client, config)
+
+	// the 3 mock validators, whether current or delinquent:
+	test := collector.VoteAccountsProcessed.makeCollectionTest(NewLV(3))
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoError(t, err)
+}
+
+func TestSolanaCollector_VoteParticipation(t *testing.T) {
+	simulator, client := NewSimulator(t, 48)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_validator_vote_participation"}
+	collector := NewSolanaCollector(client, config)
+
+	// advance moves the simulator to slot, with ccc not voting if stalled:
+	advance := func(slot int, stalled bool) {
+		stalledVote := simulator.Server.GetValidatorInfo("ccc").LastVote
+		for simulator.Slot < slot {
+			simulator.Slot++
+			simulator.PopulateSlot(simulator.Slot)
+			if stalled {
+				info := simulator.Server.GetValidatorInfo("ccc")
+				info.LastVote = stalledVote
+				simulator.Server.SetOpt(rpc.ValidatorInfoOpt, "ccc", info)
+			}
+		}
+	}
+	// aaa and bbb are healthy, and vote on every slot:
+	assertParticipation := func(ccc float64) {
+		test := collector.ValidatorVoteParticipation.makeCollectionTest(
+			NewLV(1, "", "aaa", "AAA"),
+			NewLV(1, "", "bbb", "BBB"),
+			NewLV(ccc, "", "ccc", "CCC"),
+		)
+		err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+		assert.NoErrorf(t, err, "unexpected collecting result at slot %d: \n%s", simulator.Slot, err)
+	}
+
+	// there is no estimate until the second scrape of the epoch:
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+
+	// ccc stalls, and does not vote on any of the next 4 slots:
+	advance(52, true)
+	assertParticipation(0)
+	// once it catches up, its participation moves back toward 1, without making up for the slots it missed:
+	advance(56, false)
+	assertParticipation(0.5)
+	advance(64, false)
+	assertParticipation(0.75)
+
+	// the estimate is reset in the next epoch:
+	advance(72, false)
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+	advance(76, false)
+	assertParticipation(1)
+}
+
+func TestSolanaCollector_CommissionChanged(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_validator_commission_changed"}
+	collector := NewSolanaCollector(client, config)
+
+	assertChanged := func(ccc float64) {
+		test := collector.ValidatorCommissionChanged.makeCollectionTest(
+			NewLV(0, "", "aaa", "AAA"),
+			NewLV(0, "", "bbb", "BBB"),
+			NewLV(ccc, "", "ccc", "CCC"),
+		)
+		err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+		assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+	}
+
+	// the first scrape has no previous commission to compare against:
+	assertChanged(0)
+	info := simulator.Server.GetValidatorInfo("ccc")
+	info.Commission = 100
+	simulator.Server.SetOpt(rpc.ValidatorInfoOpt, "ccc", info)
+	assertChanged(1)
+	// the change is only reported once:
+	assertChanged(0)
+}
+
+func TestSolanaCollector_NextLeaderSlot(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	// ddd never leads:
+	config.NodeKeys = []string{"aaa", "bbb", "ddd"}
+	config.EnabledMetrics = []string{"solana_node_next_leader_slot"}
+	collector := NewSolanaCollector(client, config)
+
+	// slot 35 is the 12th slot of epoch 1, aaa leads from the 13th and bbb from the 17th:
+	test := collector.NodeNextLeaderSlot.makeCollectionTest(
+		NewLV(1, "", "aaa"),
+		NewLV(5, "", "bbb"),
+		NewLV(nextLeaderSlotWindow, "", "ddd"),
+	)
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{float64(35), float64(nextLeaderSlotWindow)}, simulator.Server.LastParams("getSlotLeaders"))
+}
+
+func TestSolanaCollector_Superminority(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	for nodekey, stake := range map[string]int{"aaa": 1_000_000, "bbb": 6_000_000, "ccc": 3_000_000} {
+		info := simulator.Server.GetValidatorInfo(nodekey)
+		info.Stake = stake
+		simulator.Server.SetOpt(rpc.ValidatorInfoOpt, nodekey, info)
+	}
+
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_validator_is_superminority"}
+	collector := NewSolanaCollector(client, config)
+
+	test := collector.ValidatorIsSuperminority.makeCollectionTest(
+		NewLV(0, "", "aaa", "AAA"),
+		NewLV(1, "", "bbb", "BBB"),
+		NewLV(0, "", "ccc", "CCC"),
+	)
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoError(t, err)
+}
+
+func TestSolanaCollector_CreditsRank(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	for nodekey, credits := range map[string]int{"aaa": 6_000, "bbb": 8_000, "ccc": 7_000} {
+		info := simulator.Server.GetValidatorInfo(nodekey)
+		info.Credits = credits
+		simulator.Server.SetOpt(rpc.ValidatorInfoOpt, nodekey, info)
+	}
+
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_validator_credits_rank"}
+	collector := NewSolanaCollector(client, config)
+
+	test := collector.ValidatorCreditsRank.makeCollectionTest(
+		NewLV(3, "", "aaa", "AAA"),
+		NewLV(1, "", "bbb", "BBB"),
+		NewLV(2, "", "ccc", "CCC"),
+	)
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoError(t, err)
+}
+
+func TestSolanaCollector_StakeAccounts(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	simulator.Server.SetOpt(
+		rpc.StakeActivationOpt, "xxx",
+		rpc.StakeActivation{State: rpc.StakeStateActivating, Active: 1 * rpc.LamportsInSol, Inactive: 2 * rpc.LamportsInSol},
+	)
+	simulator.Server.SetOpt(
+		rpc.StakeActivationOpt, "yyy",
+		rpc.StakeActivation{State: rpc.StakeStateDeactivating, Active: 3 * rpc.LamportsInSol},
+	)
+	config := newTestConfig(simulator, false)
+	config.StakeAccounts = []string{"xxx", "yyy"}
+	collector := NewSolanaCollector(client, config)
+	mockAPIClient := api.NewMockClient()
+	mockAPIClient.SetMinRequiredVersion("2.2.14", "0.503.20214")
+	mockAPIClient.SetNextEpochMinRequiredVersion("2.2.15", "0.503.20215")
+	collector.apiClient = mockAPIClient
+
+	testCases := []collectionTest{
+		collector.StakeAccountActive.makeCollectionTest(
+			NewLV(1, "xxx", rpc.StakeStateActivating),
+			NewLV(3, "yyy", rpc.StakeStateDeactivating),
+		),
+		collector.StakeAccountActivating.makeCollectionTest(
+			NewLV(2, "xxx", rpc.StakeStateActivating),
+			NewLV(0, "yyy", rpc.StakeStateDeactivating),
+		),
+		collector.StakeAccountDeactivating.makeCollectionTest(
+			NewLV(0, "xxx", rpc.StakeStateActivating),
+			NewLV(3, "yyy", rpc.StakeStateDeactivating),
+		),
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+			assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+		})
+	}
+}
+
+func TestSolanaCollector_TokenAccounts(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	simulator.Server.SetOpt(rpc.TokenAccountOpt, "xxx", rpc.MockTokenAccount{Mint: "mint1", Amount: 1_500_000, Decimals: 6})
+	simulator.Server.SetOpt(rpc.TokenAccountOpt, "yyy", rpc.MockTokenAccount{Mint: "mint2", Amount: 42, Decimals: 0})
+	config := newTestConfig(simulator, false)
+	config.TokenAccounts = []string{"xxx", "yyy"}
+	config.EnabledMetrics = []string{"solana_token_account_balance"}
+	collector := NewSolanaCollector(client, config)
+
+	test := collector.TokenAccountBalance.makeCollectionTest(
+		NewLV(1.5, "xxx", "mint1"),
+		NewLV(42, "yyy", "mint2"),
+	)
+	for i := 0; i < 2; i++ {
+		err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+		assert.NoError(t, err)
+	}
+	// the mints are only fetched once:
+	assert.Equal(t, 2, simulator.Server.CallCount("getAccountInfo"))
+	assert.Equal(t, 4, simulator.Server.CallCount("getTokenAccountBalance"))
+}
+
+func TestSolanaCollector_NodeAboveMaxVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		isFiredancer  bool
+		version       string
+		agaveMax      string
+		firedancerMax string
+		expected      float64
+		expectedMax   string
+	}{
+		{name: "agave above max", version: "2.3.1", agaveMax: "2.3.0", expected: 1, expectedMax: "2.3.0"},
+		{name: "agave at max", version: "2.3.0", agaveMax: "2.3.0", expected: 0, expectedMax: "2.3.0"},
+		{name: "firedancer without max", isFiredancer: true, version: "0.505.20216", agaveMax: "2.3.0"},
+		{
+			name: "firedancer above max", isFiredancer: true, version: "0.505.20216", firedancerMax: "0.504.0",
+			expected: 1, expectedMax: "0.504.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client := rpc.NewMockClient(t,
+				map[string]any{
+					"getVersion":     map[string]string{"solana-core": tt.version},
+					"getGenesisHash": rpc.MainnetGenesisHash,
+				},
+				nil,
+				nil,
+				nil,
+				nil,
+				nil,
+			)
+			newFiredancerMetricsServer(t, client).Store(tt.isFiredancer)
+			collector := NewSolanaCollector(
+				client, &ExporterConfig{MaxConcurrentRPC: 1, EnabledMetrics: []string{"solana_node_above_max_version"}},
+			)
+			mockAPIClient := api.NewMockClient()
+			mockAPIClient.SetMinRequiredVersion("2.2.14", "0.503.20214")
+			mockAPIClient.SetMaxAllowedVersion(tt.agaveMax, tt.firedancerMax)
+			collector.apiClient = mockAPIClient
+
+			isFiredancer := "0"
+			if tt.isFiredancer {
+				isFiredancer = "1"
+			}
+			test := collector.NodeAboveMaxVersion.makeCollectionTest(
+				NewLV(tt.expected, "mainnet-beta", isFiredancer, tt.expectedMax, tt.version),
+			)
+			err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+			assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+		})
+	}
+}
+
+func TestSolanaCollector_BalanceChange(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_account_balance_change"}
+	config.NodeKeys, config.VoteKeys = []string{"aaa"}, []string{"AAA"}
+	collector := NewSolanaCollector(client, config)
+
+	// nothing is emitted on the first scrape, as there is no previous balance:
+	assert.Equal(t, 0, testutil.CollectAndCount(collector, "solana_account_balance_change"))
+
+	simulator.Server.SetOpt(rpc.BalanceOpt, "aaa", rpc.LamportsInSol/2)
+	simulator.Server.SetOpt(rpc.BalanceOpt, "AAA", 6*rpc.LamportsInSol)
+	test := collector.AccountBalanceChange.makeCollectionTest(NewLV(2, "AAA", ""), NewLV(-0.5, "aaa", ""))
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoError(t, err)
+}
+
+func TestSolanaCollector_BalanceAddressLabels(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_account_balance"}
+	config.NodeKeys, config.VoteKeys = []string{"aaa"}, []string{"AAA"}
+	config.BalanceAddresses = []string{"bbb"}
+	config.BalanceAddressLabels = map[string]string{"bbb": "treasury"}
+	collector := NewSolanaCollector(client, config)
+
+	test := collector.AccountBalances.makeCollectionTest(
+		NewLV(4, "AAA", ""), NewLV(1, "aaa", ""), NewLV(2, "bbb", "treasury"),
+	)
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoError(t, err)
+}
+
+func TestSolanaCollector_BlockTimeLag(t *testing.T) {
+	_, client := rpc.NewMockClient(t,
+		map[string]any{"getSlot": 100},
+		nil,
+		nil,
+		nil,
+		map[int]rpc.MockSlotInfo{
+			98:  {Leader: "aaa", Block: &rpc.MockBlockInfo{BlockTime: time.Now().Add(-30 * time.Second).Unix()}},
+			99:  {Leader: "aaa", Block: nil},
+			100: {Leader: "aaa", Block: nil},
+		},
+		nil,
+	)
+	collector := NewSolanaCollector(client, &ExporterConfig{MaxConcurrentRPC: 1})
+
+	// the latest two slots were skipped, so the block time of slot 98 is used:
+	ch := make(chan prometheus.Metric, 1)
+	collector.collectBlockTimeLag(context.Background(), ch)
+	var blockTimeLag dto.Metric
+	assert.NoError(t, (<-ch).Write(&blockTimeLag))
+	assert.InDelta(t, 30, blockTimeLag.GetGauge().GetValue(), 2)
+}
+
+func TestSolanaCollector_EpochProgress(t *testing.T) {
+	startedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	server, client := rpc.NewMockClient(t,
+		map[string]any{
+			"getSlot":          250,
+			"getEpochSchedule": map[string]any{"slotsPerEpoch": 100, "warmup": false},
+			// 0.1s per slot:
+			"getRecentPerformanceSamples": []map[string]any{{"numSlots": 600, "samplePeriodSecs": 60}},
+		},
+		nil,
+		nil,
+		nil,
+		map[int]rpc.MockSlotInfo{
+			200: {Leader: "aaa", Block: nil},
+			201: {Leader: "aaa", Block: &rpc.MockBlockInfo{BlockTime: startedAt}},
+		},
+		nil,
+	)
+	collector := NewSolanaCollector(client, &ExporterConfig{
+		MaxConcurrentRPC: 1,
+		EnabledMetrics: []string{
+			"solana_node_epoch_elapsed_slots",
+			"solana_node_epoch_started_timestamp",
+			"solana_node_epoch_seconds_remaining",
+		},
+	})
+
+	// slot 250 is the 51st slot of the epoch starting at slot 200, whose first slot was skipped:
+	testCases := []collectionTest{
+		collector.NodeEpochElapsedSlots.makeCollectionTest(NewLV(50)),
+		collector.NodeEpochStartedTimestamp.makeCollectionTest(NewLV(float64(startedAt))),
+		collector.NodeEpochSecondsRemaining.makeCollectionTest(NewLV(5)),
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+			assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+		})
+	}
+	// the start time is only fetched once per epoch:
+	assert.Equal(t, 2, server.CallCount("getBlockTime"))
+}
+
+func TestSolanaCollector_SkippedSlots(t *testing.T) {
+	// every 4th slot is skipped by the simulator, including slot 35:
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_node_block_time_lag_seconds"}
+	collector := NewSolanaCollector(client, config)
+
+	// the skipped slot is walked back over, rather than making the metric invalid (which would fail the gathering):
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+	assert.Len(t, families, 1)
+	assert.Equal(t, float64(34), simulator.Server.LastParams("getBlockTime")[0])
+}
+
+func TestSolanaCollector_NoSnapshot(t *testing.T) {
+	_, client := rpc.NewMockClient(t,
+		nil,
+		map[string]*rpc.Error{"getHighestSnapshotSlot": {Code: rpc.NoSnapshotCode, Message: "No snapshot"}},
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+	collector := NewSolanaCollector(
+		client,
+		&ExporterConfig{
+			MaxConcurrentRPC: 1,
+			EnabledMetrics: []string{
+				"solana_node_highest_full_snapshot_slot", "solana_node_highest_incremental_snapshot_slot",
+			},
+		},
+	)
+
+	testCases := []collectionTest{
+		collector.NodeHighestFullSnapshotSlot.makeCollectionTest(NewLV(0)),
+		collector.NodeHighestIncrementalSnapshotSlot.makeCollectionTest(NewLV(0)),
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSolanaCollector_IdentityMatchesConfigured(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_node_identity_matches_configured"}
+
+	t.Run("matching", func(t *testing.T) {
+		simulator.Server.SetOpt(rpc.EasyResultsOpt, "getIdentity", map[string]string{"identity": "bbb"})
+		collector := NewSolanaCollector(client, config)
+		test := collector.NodeIdentityMatchesConfigured.makeCollectionTest(NewLV(1, "bbb"))
+		err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+		assert.NoError(t, err)
+	})
+
+	t.Run("foreign node", func(t *testing.T) {
+		simulator.Server.SetOpt(rpc.EasyResultsOpt, "getIdentity", map[string]string{"identity": "xxx"})
+		collector := NewSolanaCollector(client, config)
+		test := collector.NodeIdentityMatchesConfigured.makeCollectionTest(NewLV(0, "xxx"))
+		err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+		assert.NoError(t, err)
+	})
+}
+
+func TestSolanaCollector_LargestAccounts(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	simulator.Server.SetOpt(
+		rpc.EasyResultsOpt,
+		"getLargestAccounts",
+		map[string]any{
+			"context": map[string]int{"slot": 35},
+			"value": []map[string]any{
+				{"address": "xxx", "lamports": 300 * rpc.LamportsInSol},
+				{"address": "yyy", "lamports": 200 * rpc.LamportsInSol},
+				{"address": "zzz", "lamports": 100 * rpc.LamportsInSol},
+			},
+		},
+	)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_cluster_largest_account_balance"}
+	config.MonitorLargestAccounts = true
+	config.LargestAccountsCount = 2
+	config.LargestAccountsInterval = time.Hour
+	collector := NewSolanaCollector(client, config)
+
+	// only the top 2 accounts are reported:
+	test := collector.ClusterLargestAccountBalance.makeCollectionTest(NewLV(300, "xxx"), NewLV(200, "yyy"))
+	for i := 0; i < 2; i++ {
+		err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+		assert.NoError(t, err)
+	}
+	// and they are cached across scrapes:
+	assert.Equal(t, 1, simulator.Server.CallCount("getLargestAccounts"))
+}
+
+func TestSolanaCollector_StakePool(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	stake1, stake2 := int64(100*rpc.LamportsInSol), int64(50*rpc.LamportsInSol)
+	simulator.Server.SetOpt(rpc.StakeAccountOpt, "xxx", rpc.MockStakeAccount{Withdrawer: "pool", DelegatedStake: &stake1})
+	simulator.Server.SetOpt(rpc.StakeAccountOpt, "yyy", rpc.MockStakeAccount{Withdrawer: "pool", DelegatedStake: &stake2})
+	// undelegated stake accounts count towards the pool, but hold no stake:
+	simulator.Server.SetOpt(rpc.StakeAccountOpt, "zzz", rpc.MockStakeAccount{Withdrawer: "pool"})
+	// and stake accounts of other withdraw authorities are ignored:
+	simulator.Server.SetOpt(rpc.StakeAccountOpt, "www", rpc.MockStakeAccount{Withdrawer: "other", DelegatedStake: &stake1})
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_stake_pool_total_stake", "solana_stake_pool_account_count"}
+	config.StakePoolWithdrawAuthority = "pool"
+	config.StakePoolInterval = time.Hour
+	collector := NewSolanaCollector(client, config)
+
+	testCases := []collectionTest{
+		collector.StakePoolTotalStake.makeCollectionTest(NewLV(150, "pool")),
+		collector.StakePoolAccountCount.makeCollectionTest(NewLV(3, "pool")),
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+			assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+		})
+	}
+	// the stake pool is cached across scrapes:
+	assert.Equal(t, 1, simulator.Server.CallCount("getProgramAccounts"))
+}
+
+func TestSolanaCollector_TransactionCount(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_node_transactions_total"}
+	collector := NewSolanaCollector(client, config)
+
+	assertCollected := func(expected float64) {
+		t.Helper()
+		test := collector.NodeTransactionsTotal.makeCounterCollectionTest(NewLV(expected))
+		err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+		assert.NoError(t, err)
+	}
+
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getTransactionCount", 100)
+	assertCollected(100)
+	// a lower count (e.g., from a lagging node) does not decrease the counter:
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getTransactionCount", 90)
+	assertCollected(100)
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getTransactionCount", 150)
+	assertCollected(150)
+	assert.Equal(t, "finalized", lastCommitment(simulator, "getTransactionCount"))
+}
+
+func TestSolanaCollector_TransactionCount_Dynamic(t *testing.T) {
+	simulator, client := NewSimulator(t, 23)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_node_transactions_total"}
+	collector := NewSolanaCollector(client, config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go simulator.Run(ctx)
+	time.Sleep(time.Second)
+
+	initial := testutil.ToFloat64(collector)
+	for i := 0; i < 3; i++ {
+		time.Sleep(time.Second)
+		final := testutil.ToFloat64(collector)
+		assert.Greaterf(t, final, initial, "Total transactions have not increased! (%v -> %v)", initial, final)
+		// sense check to make sure the exporter is not "ahead" of the client (due to double counting or whatever)
+		simulatorCount := simulator.TransactionCount()
+		assert.LessOrEqualf(t,
+			int(final),
+			simulatorCount,
+			"Exporter transaction count (%v) ahead of simulator transaction count (%v)!",
+			int(final), simulatorCount,
+		)
+		initial = final
+	}
+}
+
+func TestSolanaCollector_Reference(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	referenceServer, _ := rpc.NewMockClient(t, map[string]any{"getSlot": 40}, nil, nil, nil, nil, nil)
+	config := newTestConfig(simulator, false)
+	config.ReferenceRpcUrl = referenceServer.URL()
+	config.EnabledMetrics = []string{"solana_reference_slot", "solana_node_slots_behind_reference"}
+	collector := NewSolanaCollector(client, config)
+
+	testCases := []collectionTest{
+		collector.ReferenceSlot.makeCollectionTest(NewLV(40)),
+		collector.NodeSlotsBehindReference.makeCollectionTest(NewLV(5)),
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+			assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+		})
+	}
+
+	// nothing is collected without a reference node:
+	config.ReferenceRpcUrl = ""
+	collector = NewSolanaCollector(client, config)
+	assert.Equal(t, 0, testutil.CollectAndCount(collector, "solana_reference_slot"))
+}
+
+func TestSolanaCollector_HealthyButBehind(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	referenceServer, _ := rpc.NewMockClient(t, map[string]any{"getSlot": 38}, nil, nil, nil, nil, nil)
+	config := newTestConfig(simulator, false)
+	config.ReferenceRpcUrl = referenceServer.URL()
+	config.EnabledMetrics = []string{"solana_node_is_healthy", "solana_node_num_slots_behind"}
+	collector := NewSolanaCollector(client, config)
+
+	// the node reports itself as healthy, yet it is 3 slots behind the reference:
+	testCases := []collectionTest{
+		collector.NodeIsHealthy.makeCollectionTest(NewLV(1)),
+		collector.NodeNumSlotsBehind.makeCollectionTest(NewLV(3)),
+	}
+	for _, test := range testCases {
+		err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+		assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+	}
+}
+
+func TestSolanaCollector_HealthyButBehind_NoReference(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getMaxShredInsertSlot", 37)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_node_is_healthy", "solana_node_num_slots_behind"}
+	collector := NewSolanaCollector(client, config)
+
+	// without a reference node, the slot of the node is compared with the highest slot it has received shreds for:
+	testCases := []collectionTest{
+		collector.NodeIsHealthy.makeCollectionTest(NewLV(1)),
+		collector.NodeNumSlotsBehind.makeCollectionTest(NewLV(2)),
+	}
+	for _, test := range testCases {
+		err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+		assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+	}
+	assert.Equal(t, "processed", lastCommitment(simulator, "getSlot"))
+}
+
+func TestSolanaCollector_Gossip(t *testing.T) {
+	tests := []struct {
+		name    string
+		nodes   []map[string]any
+		visible float64
+		// ports are the expected advertised ports, which are not exported if the node is not visible:
+		ports []LV
+	}{
+		{
+			name: "visible",
+			nodes: []map[string]any{
+				{"pubkey": "aaa"},
+				{"pubkey": "testIdentity", "tpu": nil, "tpuQuic": "10.0.0.1:8009", "rpc": nil},
+				{"pubkey": "bbb"},
+			},
+			visible: 1,
+			ports:   []LV{NewLV(0, PortTypeTpu), NewLV(1, PortTypeTpuQuic), NewLV(0, PortTypeRpc)},
+		},
+		{
+			name:    "not visible",
+			nodes:   []map[string]any{{"pubkey": "aaa"}, {"pubkey": "bbb"}},
+			visible: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			simulator, client := NewSimulator(t, 35)
+			simulator.Server.SetOpt(rpc.EasyResultsOpt, "getClusterNodes", tt.nodes)
+			config := newTestConfig(simulator, false)
+			config.EnabledMetrics = []string{
+				"solana_cluster_gossip_peers", "solana_node_visible_in_gossip", "solana_node_advertised_port",
+			}
+			collector := NewSolanaCollector(client, config)
+
+			testCases := []collectionTest{
+				collector.ClusterGossipPeers.makeCollectionTest(NewLV(float64(len(tt.nodes)))),
+				collector.NodeVisibleInGossip.makeCollectionTest(NewLV(tt.visible, "testIdentity")),
+			}
+			if tt.ports != nil {
+				testCases = append(testCases, collector.NodeAdvertisedPort.makeCollectionTest(tt.ports...))
+			} else {
+				assert.Equal(t, 0, testutil.CollectAndCount(collector, "solana_node_advertised_port"))
+			}
+			for _, test := range testCases {
+				err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+				assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+			}
+		})
+	}
+}
+
+func TestSolanaCollector_FeatureSetMatchesCluster(t *testing.T) {
+	// the node runs feature set 3294202862, which is the minority one in the gossip table:
+	nodes := []map[string]any{
+		{"pubkey": "testIdentity", "featureSet": 3294202862},
+		{"pubkey": "aaa", "featureSet": 4140108451},
+		{"pubkey": "bbb", "featureSet": 4140108451},
+		{"pubkey": "ccc", "featureSet": nil},
+	}
+	tests := []struct {
+		name     string
+		expected int64
+		matches  float64
+	}{
+		{name: "cluster majority", matches: 0},
+		{name: "expected feature set", expected: 3294202862, matches: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			simulator, client := NewSimulator(t, 35)
+			simulator.Server.SetOpt(rpc.EasyResultsOpt, "getClusterNodes", nodes)
+			config := newTestConfig(simulator, false)
+			config.EnabledMetrics = []string{"solana_node_feature_set_matches_cluster"}
+			config.ExpectedFeatureSet = tt.expected
+			collector := NewSolanaCollector(client, config)
+
+			test := collector.NodeFeatureSetMatchesCluster.makeCollectionTest(NewLV(tt.matches))
+			err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+			assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+		})
+	}
+}
+
+func TestSolanaCollector_ConfirmationLatency(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getLatestBlockhash", map[string]any{
+		"context": map[string]int{"slot": 35},
+		"value":   map[string]any{"blockhash": testBlockhash, "lastValidBlockHeight": 185},
+	})
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "sendTransaction", "signature")
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getSignatureStatuses", map[string]any{
+		"context": map[string]int{"slot": 35},
+		"value":   []any{map[string]any{"slot": 35, "confirmations": 0, "confirmationStatus": "confirmed"}},
+	})
+	config := newTestConfig(simulator, false)
+	config.ProbeKeypair = writeKeypair(t, newTestKeypair(t))
+	config.ProbeInterval = time.Minute
+	config.EnabledMetrics = []string{"solana_node_confirmation_latency_seconds"}
+	collector := NewSolanaCollector(client, config)
+
+	assert.Equal(t, 1, testutil.CollectAndCount(collector, "solana_node_confirmation_latency_seconds"))
+	assert.Equal(t, 1, simulator.Server.CallCount("sendTransaction"))
+	// the latency is cached until the next probe is due, as every probe pays a fee:
+	assert.Equal(t, 1, testutil.CollectAndCount(collector, "solana_node_confirmation_latency_seconds"))
+	assert.Equal(t, 1, simulator.Server.CallCount("sendTransaction"))
+
+	// nothing is probed without a keypair:
+	config.ProbeKeypair = ""
+	collector = NewSolanaCollector(client, config)
+	assert.Equal(t, 0, testutil.CollectAndCount(collector, "solana_node_confirmation_latency_seconds"))
+	assert.Equal(t, 1, simulator.Server.CallCount("sendTransaction"))
+}
+
+func TestSolanaCollector_ConfirmationLatency_Failed(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getLatestBlockhash", map[string]any{
+		"context": map[string]int{"slot": 35},
+		"value":   map[string]any{"blockhash": testBlockhash, "lastValidBlockHeight": 185},
+	})
+	simulator.Server.SetOpt(
+		rpc.EasyErrorsOpt, "sendTransaction", rpc.Error{Code: -32002, Method: "sendTransaction", Message: "failed"},
+	)
+	config := newTestConfig(simulator, false)
+	config.ProbeKeypair = writeKeypair(t, newTestKeypair(t))
+	config.ProbeInterval = time.Minute
+	config.EnabledMetrics = []string{"solana_node_confirmation_latency_seconds"}
+	collector := NewSolanaCollector(client, config)
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+
+	// the error is cached until the next probe is due, rather than a new probe being sent on every scrape:
+	for i := 0; i < 2; i++ {
+		_, err := registry.Gather()
+		assert.ErrorContains(t, err, "failed")
+		assert.Equal(t, 1, simulator.Server.CallCount("sendTransaction"))
+	}
+}
+
+func TestSolanaCollector_RpcPortReachable(t *testing.T) {
+	reachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","result":"ok","id":1}`))
+	}))
+	defer reachable.Close()
+	// a closed server leaves an address at which nothing is listening:
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	tests := []struct {
+		mode     string
+		expected []LV
+	}{
+		{mode: RpcPortProbeSelf, expected: []LV{NewLV(1, "testIdentity")}},
+		{mode: RpcPortProbeAll, expected: []LV{NewLV(0, "aaa"), NewLV(1, "testIdentity")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			simulator, client := NewSimulator(t, 35)
+			simulator.Server.SetOpt(rpc.EasyResultsOpt, "getClusterNodes", []map[string]any{
+				{"pubkey": "testIdentity", "rpc": reachable.Listener.Addr().String()},
+				{"pubkey": "aaa", "rpc": unreachable.Listener.Addr().String()},
+				// nodes which do not advertise an rpc port are not probed:
+				{"pubkey": "bbb", "rpc": nil},
+			})
+			config := newTestConfig(simulator, false)
+			config.ProbeRpcPorts = tt.mode
+			config.EnabledMetrics = []string{"solana_node_rpc_port_reachable"}
+			collector := NewSolanaCollector(client, config)
+
+			test := collector.NodeRpcPortReachable.makeCollectionTest(tt.expected...)
+			err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+			assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+
+			// the ports are probed in the background, and the results served from the cache until the next probes:
+			err = testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, simulator.Server.CallCount("getClusterNodes"))
+		})
+	}
+
+	// nothing is probed unless -probe-rpc-ports is set:
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_node_rpc_port_reachable"}
+	collector := NewSolanaCollector(client, config)
+	assert.Equal(t, 0, testutil.CollectAndCount(collector, "solana_node_rpc_port_reachable"))
+}
+
+func TestSolanaCollector_CollectorErrors(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	simulator.Server.SetOpt(
+		rpc.EasyErrorsOpt,
+		"getVoteAccounts",
+		rpc.Error{Code: -32000, Method: "getVoteAccounts", Message: "failed"},
+	)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{
+		"solana_validator_active_stake", "solana_node_is_healthy", "solana_exporter_collector_errors_total",
+	}
+	collector := NewSolanaCollector(client, config)
+
+	for i := 1; i <= 2; i++ {
+		ch := make(chan prometheus.Metric, 100)
+		collector.Collect(ch)
+		close(ch)
+
+		// the failing collector is counted, whereas the healthy one is not:
+		errors := map[string]float64{}
+		for metric := range ch {
+			if metric.Desc() != collector.CollectorErrorsTotal.Desc {
+				continue
+			}
+			var m dto.Metric
+			assert.NoError(t, metric.Write(&m))
+			errors[m.GetLabel()[0].GetValue()] = m.GetCounter().GetValue()
+		}
+		assert.Equal(t, map[string]float64{CollectorVoteAccounts: float64(i), CollectorHealth: 0}, errors)
+	}
+}
+
+func TestSolanaCollector_CollectLogVerbosity(t *testing.T) {
+	// isCollectLog returns whether entry logs the progress of a collector:
+	isCollectLog := func(entry observer.LoggedEntry) bool {
+		return strings.HasPrefix(entry.Message, "Collecting ") || strings.HasSuffix(entry.Message, " collected.")
+	}
+
+	tests := []struct {
+		name      string
+		verbosity string
+		expected  zapcore.Level
+	}{
+		{name: "default", verbosity: DefaultExporterConfig().LogCollectVerbosity, expected: zapcore.DebugLevel},
+		{name: "info", verbosity: "info", expected: zapcore.InfoLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			simulator, client := NewSimulator(t, 35)
+			config := newTestConfig(simulator, false)
+			config.LogCollectVerbosity = tt.verbosity
+			config.EnabledMetrics = []string{"solana_validator_active_stake", "solana_node_is_healthy"}
+			collector := NewSolanaCollector(client, config)
+			core, logs := observer.New(zapcore.DebugLevel)
+			collector.logger = zap.New(core).Sugar()
+
+			testutil.CollectAndCount(collector)
+			collectLogs := logs.Filter(isCollectLog).All()
+			assert.NotEmpty(t, collectLogs)
+			for _, entry := range collectLogs {
+				assert.Equal(t, tt.expected, entry.Level, entry.Message)
+			}
+		})
+	}
+}
+
+func TestSolanaCollector_RPCErrorLogs(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false))
+	core, logs := observer.New(zapcore.DebugLevel)
+	collector.logger = zap.New(core).Sugar()
+
+	unhealthy := &rpc.Error{Method: "getHealth", Code: -32005, Message: "Node is unhealthy"}
+	for range 3 {
+		collector.recordRPCError(unhealthy)
+	}
+	// a different error is logged regardless:
+	collector.recordRPCError(&rpc.Error{Method: "getHealth", Code: -32005, Message: "Node is behind by 42 slots"})
+	assert.Equal(t, 2, logs.FilterMessage("rpc error").Len())
+
+	// once the interval has elapsed, the error is logged again, along with how many times it was repeated:
+	collector.rpcErrorLogs[rpcErrorKey{method: unhealthy.Method, code: unhealthy.Code, message: unhealthy.Message}].
+		loggedAt = time.Now().Add(-rpcErrorLogInterval)
+	collector.recordRPCError(unhealthy)
+	entries := logs.FilterMessage("rpc error").All()
+	assert.Len(t, entries, 3)
+	assert.Equal(t, int64(2), entries[2].ContextMap()["repeated"])
+}
+
+func TestSolanaCollector_CommitmentSlots(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_node_slot"}
+	collector := NewSolanaCollector(client, config)
+
+	test := collector.NodeSlot.makeCollectionTest(
+		NewLV(35, string(rpc.CommitmentProcessed)),
+		NewLV(35, string(rpc.CommitmentConfirmed)),
+		NewLV(35, string(rpc.CommitmentFinalized)),
+	)
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+	assert.Equal(t, 3, simulator.Server.CallCount("getSlot"))
+}
+
+func TestSolanaCollector_SlotAdvancing(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_node_slot_advancing", "solana_node_restart_suspected_total"}
+	collector := NewSolanaCollector(client, config)
+
+	// assertCollection scrapes the collector once, and compares both metrics (advancing is absent if nil):
+	assertCollection := func(advancing *float64, restarts float64) {
+		expected := collector.NodeRestartSuspectedTotal.makeCounterCollectionTest(NewLV(restarts)).ExpectedResponse
+		if advancing != nil {
+			expected += collector.NodeSlotAdvancing.makeCollectionTest(NewLV(*advancing)).ExpectedResponse
+		}
+		err := testutil.CollectAndCompare(
+			collector, bytes.NewBufferString(expected),
+			collector.NodeSlotAdvancing.Name, collector.NodeRestartSuspectedTotal.Name,
+		)
+		assert.NoErrorf(t, err, "unexpected collecting result at slot %d: \n%s", simulator.Slot, err)
+	}
+	advancing, stalled := 1.0, 0.0
+
+	// the first scrape has nothing to compare the slot against:
+	assertCollection(nil, 0)
+	simulator.Slot++
+	simulator.PopulateSlot(simulator.Slot)
+	assertCollection(&advancing, 0)
+	// the slot does not advance between the next scrapes:
+	assertCollection(&stalled, 0)
+
+	// the node restarts from an older snapshot, and its slot goes backwards:
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getSlot", 20)
+	assertCollection(&stalled, 1)
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getSlot", 21)
+	assertCollection(&advancing, 1)
+}
+
+func TestSolanaCollector_SlotSourceDisagreement(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	// a lagging replica reports an older slot through getEpochInfo:
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getEpochInfo", map[string]int{
+		"absoluteSlot": 30, "blockHeight": 30, "epoch": 1, "slotIndex": 6, "slotsInEpoch": 24, "transactionCount": 0,
+	})
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_node_slot_source_disagreement"}
+	collector := NewSolanaCollector(client, config)
+
+	test := collector.NodeSlotSourceDisagreement.makeCollectionTest(NewLV(5))
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+}
+
+func TestSolanaCollector_ClusterSkipRate(t *testing.T) {
+	// every 4th slot is skipped by the simulator:
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_cluster_skip_rate"}
+	collector := NewSolanaCollector(client, config)
+
+	test := collector.ClusterSkipRate.makeCollectionTest(NewLV(0.25))
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+	// the whole epoch so far is fetched:
+	assert.Equal(t, map[string]any{"firstSlot": float64(24), "lastSlot": float64(35)}, blockProductionRange(simulator))
+
+	// whereas only the new slots are fetched by the next scrape:
+	for simulator.Slot < 41 {
+		simulator.Slot++
+		simulator.PopulateSlot(simulator.Slot)
+	}
+	test = collector.ClusterSkipRate.makeCollectionTest(NewLV(4.0 / 18))
+	err = testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+	assert.Equal(t, map[string]any{"firstSlot": float64(36), "lastSlot": float64(41)}, blockProductionRange(simulator))
+}
+
+// blockProductionRange returns the slot range of the last getBlockProduction call made to the simulator.
+func blockProductionRange(simulator *Simulator) any {
+	params := simulator.Server.LastParams("getBlockProduction")
+	return params[0].(map[string]any)["range"]
+}
+
+func TestSolanaCollector_RentExempt(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_rent_exempt_minimum_lamports"}
+	collector := NewSolanaCollector(client, config)
+
+	// nothing is collected without data sizes:
+	assert.Equal(t, 0, testutil.CollectAndCount(collector, "solana_rent_exempt_minimum_lamports"))
+	assert.Equal(t, 0, simulator.Server.CallCount("getMinimumBalanceForRentExemption"))
+
+	config.RentExemptDataSizes = []int64{0, 165}
+	test := collector.RentExemptMinimum.makeCollectionTest(
+		NewLV(float64(rpc.MockRentExemptMinimum(0)), "0"),
+		NewLV(float64(rpc.MockRentExemptMinimum(165)), "165"),
+	)
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoError(t, err)
+}
+
+func TestSolanaCollector_StakeMinimumDelegation(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_cluster_stake_minimum_delegation"}
+	collector := NewSolanaCollector(client, config)
+
+	test := collector.ClusterStakeMinimumDelegation.makeCollectionTest(NewLV(1))
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoError(t, err)
+
+	// the cluster-wide minimum is not collected in light mode:
+	config.LightMode = true
+	collector = NewSolanaCollector(client, config)
+	assert.Equal(t, 0, testutil.CollectAndCount(collector, "solana_cluster_stake_minimum_delegation"))
+}
+
+func TestSolanaCollector_PerformanceSamples(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getRecentPerformanceSamples", []map[string]any{
+		{"slot": 400, "numSlots": 150, "numTransactions": 6000, "samplePeriodSecs": 60},
+		{"slot": 250, "numSlots": 150, "numTransactions": 3000, "samplePeriodSecs": 60},
+		{"slot": 100, "numSlots": 150, "numTransactions": 1200, "samplePeriodSecs": 60},
+		{"slot": 0, "numSlots": 100, "numTransactions": 600, "samplePeriodSecs": 60},
+	})
+	config := newTestConfig(simulator, false)
+	config.PerformanceSampleCount = 3
+	config.EnabledMetrics = []string{"solana_cluster_performance_sample_tps"}
+	collector := NewSolanaCollector(client, config)
+
+	// the newest sample is at index 0, and no more than the configured count are exported:
+	test := collector.ClusterPerformanceSampleTps.makeCollectionTest(
+		NewLV(100, "0"), NewLV(50, "1"), NewLV(20, "2"),
+	)
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{float64(3)}, simulator.Server.LastParams("getRecentPerformanceSamples"))
+
+	// nothing is collected with a count of 0:
+	config.PerformanceSampleCount = 0
+	collector = NewSolanaCollector(client, config)
+	assert.Equal(t, 0, testutil.CollectAndCount(collector, "solana_cluster_performance_sample_tps"))
+}
+
+func TestSolanaCollector_Cluster(t *testing.T) {
+	tests := []struct {
+		name        string
+		genesisHash string
+		clusterName string
+		expected    string
+	}{
+		{name: "mainnet", genesisHash: rpc.MainnetGenesisHash, expected: "mainnet-beta"},
+		{name: "devnet", genesisHash: rpc.DevnetGenesisHash, expected: "devnet"},
+		{name: "unknown", genesisHash: "11111111111111111111111111111111", expected: ClusterUnknown},
+		{
+			name:        "override",
+			genesisHash: "11111111111111111111111111111111",
+			clusterName: "localnet",
+			expected:    "localnet",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			simulator, client := NewSimulator(t, 35)
+			simulator.Server.SetOpt(rpc.EasyResultsOpt, "getGenesisHash", tt.genesisHash)
+			config := newTestConfig(simulator, false)
+			config.EnabledMetrics = []string{"solana_node_cluster"}
+			config.ClusterName = tt.clusterName
+			collector := NewSolanaCollector(client, config)
+
+			test := collector.NodeCluster.makeCollectionTest(NewLV(1, tt.expected, tt.genesisHash))
+			err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSolanaCollector_LastCluster(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getGenesisHash", rpc.TestnetGenesisHash)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_node_is_outdated"}
+	collector := NewSolanaCollector(client, config)
+	mockAPIClient := api.NewMockClient()
+	mockAPIClient.SetMinRequiredVersion("2.2.14", "0.503.20214")
+	collector.apiClient = mockAPIClient
+
+	test := collector.NodeIsOutdated.makeCollectionTest(NewLV(1, "testnet", "797", "0", "2.2.14", "v1.0.0"))
+	err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoError(t, err)
+
+	// the detected cluster is still used once the genesis hash cannot be fetched:
+	simulator.Server.SetOpt(
+		rpc.EasyErrorsOpt, "getGenesisHash", rpc.Error{Code: -32000, Method: "getGenesisHash", Message: "failed"},
+	)
+	err = testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoError(t, err)
+
+	// whereas mainnet-beta is assumed without any successful detection:
+	collector = NewSolanaCollector(client, config)
+	collector.apiClient = mockAPIClient
+	test = collector.NodeIsOutdated.makeCollectionTest(NewLV(1, "mainnet-beta", "797", "0", "2.2.14", "v1.0.0"))
+	err = testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+	assert.NoError(t, err)
+}
+
+func TestSolanaCollector_Programs(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	authority := "authority"
+	simulator.Server.SetOpt(
+		rpc.ProgramOpt, "upgradeable", rpc.MockProgram{ProgramData: "upgradeableData", Slot: 20, Authority: &authority},
+	)
+	simulator.Server.SetOpt(rpc.ProgramOpt, "immutable", rpc.MockProgram{ProgramData: "immutableData", Slot: 10})
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_program_upgradeable", "solana_program_last_deploy_slot"}
+	config.Programs = []string{"upgradeable", "immutable"}
+	collector := NewSolanaCollector(client, config)
+
+	testCases := []collectionTest{
+		collector.ProgramUpgradeable.makeCollectionTest(NewLV(1, "upgradeable"), NewLV(0, "immutable")),
+		collector.ProgramLastDeploySlot.makeCollectionTest(NewLV(20, "upgradeable"), NewLV(10, "immutable")),
+	}
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+			assert.NoErrorf(t, err, "unexpected collecting result for %s: \n%s", test.Name, err)
+		})
+	}
+	// the program data addresses are only fetched once, after which each scrape only fetches the program data:
+	assert.Equal(t, 2+2*len(testCases), simulator.Server.CallCount("getAccountInfo"))
+
+	// a missing program fails the collection:
+	config.Programs = []string{"missing"}
+	assert.Error(t, testutil.CollectAndCompare(collector, bytes.NewBufferString("")))
+}
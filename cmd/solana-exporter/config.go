@@ -2,246 +2,1391 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/asymmetric-research/solana-exporter/pkg/api"
 	"github.com/asymmetric-research/solana-exporter/pkg/rpc"
 	"github.com/asymmetric-research/solana-exporter/pkg/slog"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
 )
 
+// metricPrefixRegexp matches the valid -metric-prefix values, i.e., valid starts of Prometheus metric names.
+var metricPrefixRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
 type (
-	arrayFlags []string
+	// arrayFlags is a repeatable flag bound to a string slice. The first time it is set, it replaces the initial
+	// (default or config-file) value of the slice, after which it appends to it.
+	arrayFlags struct {
+		values *[]string
+		set    bool
+	}
+
+	// commaSeparatedFlag is a flag bound to a string slice, set from a comma-separated list.
+	commaSeparatedFlag struct {
+		values *[]string
+	}
+
+	// floatsFlag is a flag bound to a float64 slice, set from a comma-separated list.
+	floatsFlag struct {
+		values *[]float64
+	}
+
+	// intsFlag is a flag bound to an int64 slice, set from a comma-separated list.
+	intsFlag struct {
+		values *[]int64
+	}
+
+	// mapFlag is a flag bound to a string map, set from a comma-separated list of key=value pairs.
+	mapFlag struct {
+		values *map[string]string
+	}
+
+	// methodTimeoutFlag is a flag bound to the timeout of a single rpc method, within a map of method timeouts. It is
+	// set from a duration string, e.g., '10s'.
+	methodTimeoutFlag struct {
+		timeouts *map[string]time.Duration
+		method   string
+	}
+
+	// secondsFlag is a flag bound to a time.Duration, set from a whole number of seconds.
+	secondsFlag struct {
+		duration *time.Duration
+	}
 
 	ExporterConfig struct {
-		HttpTimeout                      time.Duration
-		RpcUrl                           string
-		ListenAddress                    string
-		NodeKeys                         []string
-		VoteKeys                         []string
-		BalanceAddresses                 []string
-		ComprehensiveSlotTracking        bool
-		ComprehensiveVoteAccountTracking bool
-		MonitorBlockSizes                bool
-		LightMode                        bool
-		SlotPace                         time.Duration
-		ActiveIdentity                   string
-		EpochCleanupTime                 time.Duration
-		FiredancerMetricsPort            int
+		HttpTimeout                      time.Duration            `yaml:"http_timeout"`
+		RpcUrl                           string                   `yaml:"rpc_url"`
+		ReferenceRpcUrl                  string                   `yaml:"reference_rpc_url"`
+		ListenAddress                    string                   `yaml:"listen_address"`
+		PprofAddr                        string                   `yaml:"pprof_addr,omitempty"`
+		TextfileOutput                   string                   `yaml:"textfile_output,omitempty"`
+		TextfileInterval                 time.Duration            `yaml:"textfile_interval"`
+		WebAuthUser                      string                   `yaml:"web_auth_user,omitempty"`
+		WebAuthPassword                  string                   `yaml:"web_auth_password,omitempty"`
+		WebAuthPasswordFile              string                   `yaml:"web_auth_password_file,omitempty"`
+		WebTLSCert                       string                   `yaml:"web_tls_cert,omitempty"`
+		WebTLSKey                        string                   `yaml:"web_tls_key,omitempty"`
+		NodeKeys                         []string                 `yaml:"node_keys,omitempty"`
+		VoteKeys                         []string                 `yaml:"-"`
+		BalanceAddresses                 []string                 `yaml:"balance_addresses,omitempty"`
+		BalanceAddressLabels             map[string]string        `yaml:"balance_address_labels,omitempty"`
+		BalanceFetchConcurrency          int                      `yaml:"balance_fetch_concurrency"`
+		ComprehensiveSlotTracking        bool                     `yaml:"comprehensive_slot_tracking"`
+		ComprehensiveVoteAccountTracking bool                     `yaml:"comprehensive_vote_account_tracking"`
+		MonitorBlockSizes                bool                     `yaml:"monitor_block_sizes"`
+		LightMode                        bool                     `yaml:"light_mode"`
+		SlotPace                         time.Duration            `yaml:"slot_pace"`
+		ActiveIdentity                   string                   `yaml:"active_identity"`
+		EpochCleanupTime                 time.Duration            `yaml:"epoch_cleanup_time"`
+		FiredancerMetricsPort            int                      `yaml:"firedancer_metrics_port"`
+		DisabledMetrics                  []string                 `yaml:"disabled_metrics,omitempty"`
+		DisabledCollectors               []string                 `yaml:"disabled_collectors,omitempty"`
+		EnabledCollectors                []string                 `yaml:"enabled_collectors,omitempty"`
+		EnabledMetrics                   []string                 `yaml:"enabled_metrics,omitempty"`
+		MetricPrefix                     string                   `yaml:"metric_prefix"`
+		IdentityLabels                   map[string]string        `yaml:"identity_labels,omitempty"`
+		HealthStaleness                  time.Duration            `yaml:"health_staleness"`
+		MaxConcurrentRPC                 int                      `yaml:"max_concurrent_rpc"`
+		FiredancerDetectionTTL           time.Duration            `yaml:"firedancer_detection_ttl"`
+		ScrapeFiredancerMetrics          bool                     `yaml:"scrape_firedancer_metrics"`
+		DefaultCommitment                rpc.Commitment           `yaml:"default_commitment"`
+		VoteAccountsCommitment           rpc.Commitment           `yaml:"vote_accounts_commitment"`
+		StakeAccounts                    []string                 `yaml:"stake_accounts,omitempty"`
+		TokenAccounts                    []string                 `yaml:"token_accounts,omitempty"`
+		Programs                         []string                 `yaml:"programs,omitempty"`
+		RequiredVersionsAPIURL           string                   `yaml:"required_versions_api_url"`
+		ClusterName                      string                   `yaml:"cluster_name,omitempty"`
+		DisableVersionCompliance         bool                     `yaml:"disable_version_compliance"`
+		MonitorLargestAccounts           bool                     `yaml:"monitor_largest_accounts"`
+		LargestAccountsCount             int                      `yaml:"largest_accounts_count"`
+		LargestAccountsInterval          time.Duration            `yaml:"largest_accounts_interval"`
+		StakePoolWithdrawAuthority       string                   `yaml:"stake_pool_withdraw_authority"`
+		StakePoolInterval                time.Duration            `yaml:"stake_pool_interval"`
+		ProbeKeypair                     string                   `yaml:"probe_keypair"`
+		ProbeInterval                    time.Duration            `yaml:"probe_interval"`
+		ProbeRpcPorts                    string                   `yaml:"probe_rpc_ports,omitempty"`
+		ExpectedFeatureSet               int64                    `yaml:"expected_feature_set,omitempty"`
+		RentExemptDataSizes              []int64                  `yaml:"rent_exempt_data_sizes,omitempty"`
+		PerformanceSampleCount           int                      `yaml:"performance_sample_count"`
+		RpcMethodTimeouts                map[string]time.Duration `yaml:"rpc_method_timeouts,omitempty"`
+		RpcLatencyBuckets                []float64                `yaml:"rpc_latency_buckets,omitempty"`
+		RpcMaxIdleConnsPerHost           int                      `yaml:"rpc_max_idle_conns_per_host"`
+		RpcMaxConnsPerHost               int                      `yaml:"rpc_max_conns_per_host"`
+		RpcIdleConnTimeout               time.Duration            `yaml:"rpc_idle_conn_timeout"`
+		RpcHttpHeaders                   []string                 `yaml:"rpc_http_headers,omitempty"`
+		RpcAuthToken                     string                   `yaml:"rpc_auth_token,omitempty"`
+		RpcGzip                          bool                     `yaml:"rpc_gzip"`
+		RpcClientCert                    string                   `yaml:"rpc_client_cert,omitempty"`
+		RpcClientKey                     string                   `yaml:"rpc_client_key,omitempty"`
+		RpcCaCert                        string                   `yaml:"rpc_ca_cert,omitempty"`
+		RpcInsecureSkipVerify            bool                     `yaml:"rpc_insecure_skip_verify"`
+		LogFormat                        string                   `yaml:"log_format"`
+		LogLevel                         string                   `yaml:"log_level,omitempty"`
+		LogCollectVerbosity              string                   `yaml:"log_collect_verbosity"`
+		Once                             bool                     `yaml:"-"`
+		ListMetrics                      bool                     `yaml:"-"`
 	}
 )
 
 func (i *arrayFlags) String() string {
-	return fmt.Sprint(*i)
+	if i.values == nil {
+		return "[]"
+	}
+	return fmt.Sprint(*i.values)
 }
 
 func (i *arrayFlags) Set(value string) error {
-	*i = append(*i, value)
+	if !i.set {
+		*i.values = nil
+		i.set = true
+	}
+	*i.values = append(*i.values, value)
 	return nil
 }
 
-func NewExporterConfig(
-	ctx context.Context,
-	httpTimeout time.Duration,
-	rpcUrl string,
-	listenAddress string,
-	nodeKeys []string,
-	balanceAddresses []string,
-	comprehensiveSlotTracking bool,
-	comprehensiveVoteAccountTracking bool,
-	monitorBlockSizes bool,
-	lightMode bool,
-	slotPace time.Duration,
-	activeIdentity string,
-	epochCleanupTime time.Duration,
-	firedancerMetricsPort int,
-) (*ExporterConfig, error) {
-	logger := slog.Get()
-	logger.Infow(
-		"Setting up export config with ",
-		"httpTimeout", httpTimeout.Seconds(),
-		"rpcUrl", rpcUrl,
-		"listenAddress", listenAddress,
-		"nodeKeys", nodeKeys,
-		"balanceAddresses", balanceAddresses,
-		"comprehensiveSlotTracking", comprehensiveSlotTracking,
-		"comprehensiveVoteAccountTracking", comprehensiveVoteAccountTracking,
-		"monitorBlockSizes", monitorBlockSizes,
-		"lightMode", lightMode,
-		"activeIdentity", activeIdentity,
-		"slotPace", slotPace,
-		"epochCleanupTime", epochCleanupTime,
-		"firedancerMetricsPort", firedancerMetricsPort,
-	)
-	if lightMode {
-		if comprehensiveSlotTracking {
-			return nil, fmt.Errorf("'-light-mode' is incompatible with `-comprehensive-slot-tracking`")
+func (c *commaSeparatedFlag) String() string {
+	if c.values == nil {
+		return ""
+	}
+	return strings.Join(*c.values, ",")
+}
+
+func (c *commaSeparatedFlag) Set(value string) error {
+	*c.values = parseCommaSeparated(value)
+	return nil
+}
+
+func (f *floatsFlag) String() string {
+	if f.values == nil {
+		return ""
+	}
+	items := make([]string, len(*f.values))
+	for i, value := range *f.values {
+		items[i] = strconv.FormatFloat(value, 'g', -1, 64)
+	}
+	return strings.Join(items, ",")
+}
+
+func (f *floatsFlag) Set(value string) error {
+	var values []float64
+	for _, item := range parseCommaSeparated(value) {
+		parsed, err := strconv.ParseFloat(item, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q: %w", item, err)
 		}
+		values = append(values, parsed)
+	}
+	*f.values = values
+	return nil
+}
 
-		if comprehensiveVoteAccountTracking {
-			return nil, fmt.Errorf("'-light-mode' is incompatible with '-comprehensive-vote-account-tracking'")
+func (f *intsFlag) String() string {
+	if f.values == nil {
+		return ""
+	}
+	items := make([]string, len(*f.values))
+	for i, value := range *f.values {
+		items[i] = strconv.FormatInt(value, 10)
+	}
+	return strings.Join(items, ",")
+}
+
+func (f *intsFlag) Set(value string) error {
+	var values []int64
+	for _, item := range parseCommaSeparated(value) {
+		parsed, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q: %w", item, err)
 		}
+		values = append(values, parsed)
+	}
+	*f.values = values
+	return nil
+}
+
+func (m *mapFlag) String() string {
+	if m.values == nil {
+		return ""
+	}
+	var pairs []string
+	for key, value := range *m.values {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, value))
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, ",")
+}
 
-		if monitorBlockSizes {
-			return nil, fmt.Errorf("'-light-mode' is incompatible with `-monitor-block-sizes`")
+func (m *mapFlag) Set(value string) error {
+	values := make(map[string]string)
+	for _, pair := range parseCommaSeparated(value) {
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid key=value pair %q", pair)
 		}
+		values[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	*m.values = values
+	return nil
+}
+
+func (m *methodTimeoutFlag) String() string {
+	if m.timeouts == nil {
+		return ""
+	}
+	if timeout, ok := (*m.timeouts)[m.method]; ok {
+		return timeout.String()
+	}
+	return ""
+}
+
+func (m *methodTimeoutFlag) Set(value string) error {
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid timeout %q: %w", value, err)
+	}
+	if *m.timeouts == nil {
+		*m.timeouts = make(map[string]time.Duration)
+	}
+	(*m.timeouts)[m.method] = timeout
+	return nil
+}
+
+func (s *secondsFlag) String() string {
+	if s.duration == nil {
+		return "0"
+	}
+	return strconv.Itoa(int(s.duration.Seconds()))
+}
+
+func (s *secondsFlag) Set(value string) error {
+	seconds, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid number of seconds %q: %w", value, err)
+	}
+	*s.duration = time.Duration(seconds) * time.Second
+	return nil
+}
+
+// DefaultExporterConfig returns the config used when neither a config file nor flags override a value.
+func DefaultExporterConfig() ExporterConfig {
+	return ExporterConfig{
+		HttpTimeout:             60 * time.Second,
+		RpcUrl:                  "http://localhost:8899",
+		ListenAddress:           ":8080",
+		TextfileInterval:        time.Minute,
+		SlotPace:                time.Second,
+		EpochCleanupTime:        60 * time.Second,
+		FiredancerMetricsPort:   7999,
+		HealthStaleness:         5 * time.Minute,
+		MaxConcurrentRPC:        4,
+		BalanceFetchConcurrency: 1,
+		FiredancerDetectionTTL:  time.Minute,
+		MetricPrefix:            rpc.DefaultMetricPrefix,
+		RequiredVersionsAPIURL:  api.SolanaEpochStatsAPI,
+		LargestAccountsCount:    20,
+		LargestAccountsInterval: time.Hour,
+		StakePoolInterval:       time.Hour,
+		ProbeInterval:           time.Minute,
+		PerformanceSampleCount:  10,
+		RpcLatencyBuckets:       rpc.DefaultLatencyBuckets,
+		RpcMaxIdleConnsPerHost:  rpc.DefaultMaxIdleConnsPerHost,
+		RpcIdleConnTimeout:      rpc.DefaultIdleConnTimeout,
+		LogFormat:               slog.FormatJSON,
+		LogCollectVerbosity:     "debug",
+	}
+}
 
-		if len(nodeKeys) > 0 {
-			return nil, fmt.Errorf("'-light-mode' is incompatible with `-nodekey`")
+// LoadExporterConfigFile reads the YAML config file at the provided path into config. Only the fields present in
+// the file are overwritten, and unknown fields are rejected.
+func LoadExporterConfigFile(path string, config *ExporterConfig) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to decode config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate checks that the required fields are set and that the configured options are compatible.
+func (c *ExporterConfig) Validate() error {
+	if c.RpcUrl == "" {
+		return fmt.Errorf("'-rpc-url' must be set")
+	}
+	if _, err := url.ParseRequestURI(c.RpcUrl); err != nil {
+		return fmt.Errorf("invalid '-rpc-url' %s: %w", c.RpcUrl, err)
+	}
+	if c.ReferenceRpcUrl != "" {
+		if _, err := url.ParseRequestURI(c.ReferenceRpcUrl); err != nil {
+			return fmt.Errorf("invalid '-reference-rpc-url' %s: %w", c.ReferenceRpcUrl, err)
 		}
+	}
+	if !c.DisableVersionCompliance {
+		if _, err := url.ParseRequestURI(c.RequiredVersionsAPIURL); err != nil {
+			return fmt.Errorf("invalid '-required-versions-api-url' %s: %w", c.RequiredVersionsAPIURL, err)
+		}
+	}
+	// the listen address is not used with -textfile-output, as the metrics are then not served over HTTP:
+	if c.ListenAddress == "" && c.TextfileOutput == "" {
+		return fmt.Errorf("'-listen-address' must be set")
+	}
+	if c.TextfileOutput != "" {
+		// node_exporter's textfile collector only reads the files with the .prom extension:
+		if filepath.Ext(c.TextfileOutput) != ".prom" {
+			return fmt.Errorf("invalid '-textfile-output' %s, must have the .prom extension", c.TextfileOutput)
+		}
+		if c.TextfileInterval <= 0 {
+			return fmt.Errorf("'-textfile-interval' must be positive")
+		}
+	}
+	if c.PprofAddr != "" && c.PprofAddr == c.ListenAddress {
+		return fmt.Errorf("'-pprof-addr' must differ from '-listen-address'")
+	}
+	if c.WebAuthPassword != "" && c.WebAuthPasswordFile != "" {
+		return fmt.Errorf("only one of '-web-auth-password' and '-web-auth-password-file' may be set")
+	}
+	if (c.WebAuthUser == "") != (c.WebAuthPassword == "" && c.WebAuthPasswordFile == "") {
+		return fmt.Errorf(
+			"'-web-auth-user' and '-web-auth-password' (or '-web-auth-password-file') must be set together",
+		)
+	}
+	if _, err := c.LoadWebAuthPassword(); err != nil {
+		return err
+	}
+	if _, err := c.WebTLSConfig(); err != nil {
+		return err
+	}
+	if c.HttpTimeout <= 0 {
+		return fmt.Errorf("'-http-timeout' must be positive")
+	}
+	if c.SlotPace <= 0 {
+		return fmt.Errorf("'-slot-pace' must be positive")
+	}
+	if c.EpochCleanupTime < 0 {
+		return fmt.Errorf("'-epoch-cleanup-time' must not be negative")
+	}
+	if c.HealthStaleness <= 0 {
+		return fmt.Errorf("'-health-staleness' must be positive")
+	}
+	if c.MaxConcurrentRPC <= 0 {
+		return fmt.Errorf("'-max-concurrent-rpc' must be positive")
+	}
+	if c.BalanceFetchConcurrency < 0 {
+		return fmt.Errorf("'-balance-fetch-concurrency' must not be negative")
+	}
+	if c.MetricPrefix != "" && !metricPrefixRegexp.MatchString(c.MetricPrefix) {
+		return fmt.Errorf("invalid '-metric-prefix' %q, must match %s", c.MetricPrefix, metricPrefixRegexp)
+	}
+	if c.FiredancerDetectionTTL < 0 {
+		return fmt.Errorf("'-firedancer-detection-ttl' must not be negative")
+	}
+	if c.DefaultCommitment != "" && !slices.Contains(rpc.Commitments, c.DefaultCommitment) {
+		return fmt.Errorf(
+			"invalid '-default-commitment' %s, must be one of %v", c.DefaultCommitment, rpc.Commitments,
+		)
+	}
+	if c.VoteAccountsCommitment != "" && !slices.Contains(rpc.Commitments, c.VoteAccountsCommitment) {
+		return fmt.Errorf(
+			"invalid '-vote-accounts-commitment' %s, must be one of %v", c.VoteAccountsCommitment, rpc.Commitments,
+		)
+	}
+	if c.LogFormat != "" && !slices.Contains(slog.Formats, c.LogFormat) {
+		return fmt.Errorf("invalid '-log-format' %s, must be one of %v", c.LogFormat, slog.Formats)
+	}
+	if c.LogLevel != "" {
+		if _, err := slog.ParseLevel(c.LogLevel); err != nil {
+			return fmt.Errorf("invalid '-log-level': %w", err)
+		}
+	}
+	if c.LogCollectVerbosity != "" {
+		if _, err := slog.ParseLevel(c.LogCollectVerbosity); err != nil {
+			return fmt.Errorf("invalid '-log-collect-verbosity': %w", err)
+		}
+	}
+	if err := c.validatePubkeys(); err != nil {
+		return err
+	}
+	allCollectors := append(slices.Clone(Collectors), SlotWatcherCollectors...)
+	for _, name := range c.DisabledCollectors {
+		if !slices.Contains(allCollectors, name) {
+			return fmt.Errorf("unknown collector %s in '-disable-collectors', must be one of %v", name, allCollectors)
+		}
+	}
+	for _, name := range c.EnabledCollectors {
+		if !slices.Contains(allCollectors, name) {
+			return fmt.Errorf("unknown collector %s in '-enable-collectors', must be one of %v", name, allCollectors)
+		}
+		if slices.Contains(c.DisabledCollectors, name) {
+			return fmt.Errorf("collector %s is in both '-enable-collectors' and '-disable-collectors'", name)
+		}
+	}
+	for method, timeout := range c.RpcMethodTimeouts {
+		if !slices.Contains(rpc.Methods, method) {
+			return fmt.Errorf("unknown rpc method %s in rpc method timeouts, must be one of %v", method, rpc.Methods)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("'-rpc-timeout-%s' must be positive", method)
+		}
+	}
+	for i, bucket := range c.RpcLatencyBuckets {
+		if bucket <= 0 || (i > 0 && bucket <= c.RpcLatencyBuckets[i-1]) {
+			return fmt.Errorf("'-rpc-latency-buckets' must be positive and strictly increasing")
+		}
+	}
+	if c.RpcMaxIdleConnsPerHost < 0 {
+		return fmt.Errorf("'-rpc-max-idle-conns-per-host' must not be negative")
+	}
+	if c.RpcMaxConnsPerHost < 0 {
+		return fmt.Errorf("'-rpc-max-conns-per-host' must not be negative")
+	}
+	if c.RpcIdleConnTimeout < 0 {
+		return fmt.Errorf("'-rpc-idle-conn-timeout' must not be negative")
+	}
+	if _, err := c.RpcHeaders(); err != nil {
+		return err
+	}
+	if _, err := c.RpcTLSConfig(); err != nil {
+		return fmt.Errorf("invalid '-rpc-client-cert', '-rpc-client-key' or '-rpc-ca-cert': %w", err)
+	}
+	if c.MonitorLargestAccounts {
+		if c.LargestAccountsCount <= 0 || c.LargestAccountsCount > 20 {
+			return fmt.Errorf("'-largest-accounts-count' must be between 1 and 20")
+		}
+		if c.LargestAccountsInterval < 0 {
+			return fmt.Errorf("'-largest-accounts-interval' must not be negative")
+		}
+	}
+
+	if c.StakePoolWithdrawAuthority != "" && c.StakePoolInterval < 0 {
+		return fmt.Errorf("'-stake-pool-interval' must not be negative")
+	}
+
+	if c.ProbeKeypair != "" {
+		if _, err := c.LoadProbeKeypair(); err != nil {
+			return fmt.Errorf("invalid '-probe-keypair': %w", err)
+		}
+	}
+	if c.ProbeInterval < 0 {
+		return fmt.Errorf("'-probe-interval' must not be negative")
+	}
+	if c.ProbeRpcPorts != "" && !slices.Contains(RpcPortProbeModes, c.ProbeRpcPorts) {
+		return fmt.Errorf("invalid '-probe-rpc-ports' %s, must be one of %v", c.ProbeRpcPorts, RpcPortProbeModes)
+	}
+	if c.ExpectedFeatureSet < 0 {
+		return fmt.Errorf("'-expected-feature-set' must not be negative")
+	}
+	// every sample is a series of its own:
+	if c.PerformanceSampleCount < 0 || c.PerformanceSampleCount > 60 {
+		return fmt.Errorf("'-performance-sample-count' must be between 0 and 60")
+	}
+	for _, dataSize := range c.RentExemptDataSizes {
+		if dataSize < 0 || dataSize > rpc.MaxAccountDataSize {
+			return fmt.Errorf(
+				"invalid '-rent-exempt-data-sizes': %d must be between 0 and %d bytes", dataSize, rpc.MaxAccountDataSize,
+			)
+		}
+	}
 
-		if len(balanceAddresses) > 0 {
-			return nil, fmt.Errorf("'-light-mode' is incompatible with `-balance-addresses`")
+	if c.LightMode {
+		if c.ComprehensiveSlotTracking {
+			return fmt.Errorf("'-light-mode' is incompatible with `-comprehensive-slot-tracking`")
 		}
+
+		if c.ComprehensiveVoteAccountTracking {
+			return fmt.Errorf("'-light-mode' is incompatible with '-comprehensive-vote-account-tracking'")
+		}
+
+		if c.MonitorBlockSizes {
+			return fmt.Errorf("'-light-mode' is incompatible with `-monitor-block-sizes`")
+		}
+
+		if len(c.NodeKeys) > 0 {
+			return fmt.Errorf("'-light-mode' is incompatible with `-nodekey`")
+		}
+
+		if len(c.BalanceAddresses) > 0 {
+			return fmt.Errorf("'-light-mode' is incompatible with `-balance-addresses`")
+		}
+
+		if c.MonitorLargestAccounts {
+			return fmt.Errorf("'-light-mode' is incompatible with `-monitor-largest-accounts`")
+		}
+
+		if c.StakePoolWithdrawAuthority != "" {
+			return fmt.Errorf("'-light-mode' is incompatible with `-stake-pool-withdraw-authority`")
+		}
+	}
+	return nil
+}
+
+// NewExporterConfig validates the provided config and fetches the vote accounts associated with its nodekeys.
+func NewExporterConfig(ctx context.Context, config ExporterConfig) (*ExporterConfig, error) {
+	logger := slog.Get()
+	logger.Infow(
+		"Setting up export config with ",
+		"httpTimeout", config.HttpTimeout.Seconds(),
+		"rpcUrl", config.RpcUrl,
+		"referenceRpcUrl", config.ReferenceRpcUrl,
+		"listenAddress", config.ListenAddress,
+		"pprofAddr", config.PprofAddr,
+		"textfileOutput", config.TextfileOutput,
+		"textfileInterval", config.TextfileInterval,
+		"webAuthUser", config.WebAuthUser,
+		"webAuthPassword", redact(config.WebAuthPassword),
+		"webAuthPasswordFile", config.WebAuthPasswordFile,
+		"webTlsCert", config.WebTLSCert,
+		"webTlsKey", config.WebTLSKey,
+		"nodeKeys", config.NodeKeys,
+		"balanceAddresses", config.BalanceAddresses,
+		"balanceAddressLabels", config.BalanceAddressLabels,
+		"balanceFetchConcurrency", config.BalanceFetchConcurrency,
+		"comprehensiveSlotTracking", config.ComprehensiveSlotTracking,
+		"comprehensiveVoteAccountTracking", config.ComprehensiveVoteAccountTracking,
+		"monitorBlockSizes", config.MonitorBlockSizes,
+		"lightMode", config.LightMode,
+		"activeIdentity", config.ActiveIdentity,
+		"slotPace", config.SlotPace,
+		"epochCleanupTime", config.EpochCleanupTime,
+		"firedancerMetricsPort", config.FiredancerMetricsPort,
+		"disabledMetrics", config.DisabledMetrics,
+		"disabledCollectors", config.DisabledCollectors,
+		"enabledCollectors", config.EnabledCollectors,
+		"enabledMetrics", config.EnabledMetrics,
+		"metricPrefix", config.MetricPrefix,
+		"identityLabels", config.IdentityLabels,
+		"healthStaleness", config.HealthStaleness,
+		"maxConcurrentRPC", config.MaxConcurrentRPC,
+		"firedancerDetectionTTL", config.FiredancerDetectionTTL,
+		"scrapeFiredancerMetrics", config.ScrapeFiredancerMetrics,
+		"defaultCommitment", config.DefaultCommitment,
+		"voteAccountsCommitment", config.VoteAccountsCommitment,
+		"stakeAccounts", config.StakeAccounts,
+		"tokenAccounts", config.TokenAccounts,
+		"programs", config.Programs,
+		"requiredVersionsAPIURL", config.RequiredVersionsAPIURL,
+		"clusterName", config.ClusterName,
+		"disableVersionCompliance", config.DisableVersionCompliance,
+		"monitorLargestAccounts", config.MonitorLargestAccounts,
+		"largestAccountsCount", config.LargestAccountsCount,
+		"largestAccountsInterval", config.LargestAccountsInterval,
+		"stakePoolWithdrawAuthority", config.StakePoolWithdrawAuthority,
+		"stakePoolInterval", config.StakePoolInterval,
+		"probeKeypair", config.ProbeKeypair,
+		"probeInterval", config.ProbeInterval,
+		"probeRpcPorts", config.ProbeRpcPorts,
+		"expectedFeatureSet", config.ExpectedFeatureSet,
+		"rentExemptDataSizes", config.RentExemptDataSizes,
+		"performanceSampleCount", config.PerformanceSampleCount,
+		"rpcMethodTimeouts", config.RpcMethodTimeouts,
+		"rpcLatencyBuckets", config.RpcLatencyBuckets,
+		"rpcMaxIdleConnsPerHost", config.RpcMaxIdleConnsPerHost,
+		"rpcMaxConnsPerHost", config.RpcMaxConnsPerHost,
+		"rpcIdleConnTimeout", config.RpcIdleConnTimeout,
+		"rpcHttpHeaders", redactHeaders(config.RpcHttpHeaders),
+		"rpcAuthToken", redact(config.RpcAuthToken),
+		"rpcGzip", config.RpcGzip,
+		"rpcClientCert", config.RpcClientCert,
+		"rpcClientKey", config.RpcClientKey,
+		"rpcCaCert", config.RpcCaCert,
+		"rpcInsecureSkipVerify", config.RpcInsecureSkipVerify,
+		"logFormat", config.LogFormat,
+		"logLevel", config.LogLevel,
+		"logCollectVerbosity", config.LogCollectVerbosity,
+		"once", config.Once,
+		"listMetrics", config.ListMetrics,
+	)
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	// the metrics are listed without connecting to the rpc:
+	if config.ListMetrics {
+		return &config, nil
 	}
 
 	// get votekeys from rpc:
-	ctx, cancel := context.WithTimeout(ctx, httpTimeout)
+	ctx, cancel := context.WithTimeout(ctx, config.HttpTimeout)
 	defer cancel()
-	client := rpc.NewRPCClient(rpcUrl, httpTimeout, firedancerMetricsPort)
-	voteKeys, err := GetAssociatedVoteAccounts(ctx, client, rpc.CommitmentFinalized, nodeKeys)
+	client := config.NewRPCClient()
+	// this client is only used at startup, so its connections are not kept alive:
+	defer client.HttpClient.CloseIdleConnections()
+	voteKeys, err := GetAssociatedVoteAccounts(
+		ctx, client, config.Commitment("", rpc.CommitmentFinalized), config.NodeKeys,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("error getting vote accounts: %w", err)
 	}
+	config.VoteKeys = voteKeys
 
-	config := ExporterConfig{
-		HttpTimeout:                      httpTimeout,
-		RpcUrl:                           rpcUrl,
-		ListenAddress:                    listenAddress,
-		NodeKeys:                         nodeKeys,
-		VoteKeys:                         voteKeys,
-		BalanceAddresses:                 balanceAddresses,
-		ComprehensiveSlotTracking:        comprehensiveSlotTracking,
-		ComprehensiveVoteAccountTracking: comprehensiveVoteAccountTracking,
-		MonitorBlockSizes:                monitorBlockSizes,
-		LightMode:                        lightMode,
-		SlotPace:                         slotPace,
-		ActiveIdentity:                   activeIdentity,
-		EpochCleanupTime:                 epochCleanupTime,
-		FiredancerMetricsPort:            firedancerMetricsPort,
-	}
 	return &config, nil
 }
 
-func NewExporterConfigFromCLI(ctx context.Context) (*ExporterConfig, error) {
-	var (
-		httpTimeout                      int
-		rpcUrl                           string
-		listenAddress                    string
-		nodekeys                         arrayFlags
-		balanceAddresses                 arrayFlags
-		comprehensiveSlotTracking        bool
-		comprehensiveVoteAccountTracking bool
-		monitorBlockSizes                bool
-		lightMode                        bool
-		slotPace                         int
-		activeIdentity                   string
-		epochCleanupTime                 int
-		firedancerMetricsPort            int
-	)
-	flag.IntVar(
-		&httpTimeout,
+// RpcHeaders returns the headers to add to every rpc request, as per the configured -rpc-http-header and
+// -rpc-auth-token.
+func (c *ExporterConfig) RpcHeaders() (http.Header, error) {
+	headers := make(http.Header)
+	for _, header := range c.RpcHttpHeaders {
+		key, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid '-rpc-http-header' %q, must be of the form 'Key: Value'", redact(header))
+		}
+		headers.Add(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+	if c.RpcAuthToken != "" {
+		headers.Set("Authorization", "Bearer "+c.RpcAuthToken)
+	}
+	return headers, nil
+}
+
+// RpcTLSConfig returns the config of the TLS connections to the rpc, as per the configured -rpc-client-cert,
+// -rpc-client-key, -rpc-ca-cert and -rpc-insecure-skip-verify, or nil for the defaults.
+func (c *ExporterConfig) RpcTLSConfig() (*tls.Config, error) {
+	tlsConfig, err := rpc.TLSFiles{CertFile: c.RpcClientCert, KeyFile: c.RpcClientKey, CAFile: c.RpcCaCert}.Load()
+	if err != nil {
+		return nil, err
+	}
+	if c.RpcInsecureSkipVerify {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		tlsConfig.InsecureSkipVerify = true
+	}
+	return tlsConfig, nil
+}
+
+// LoadWebAuthPassword returns the password of the -web-auth-user, which is either -web-auth-password or the contents
+// of -web-auth-password-file (without the trailing newline).
+func (c *ExporterConfig) LoadWebAuthPassword() (string, error) {
+	if c.WebAuthPasswordFile == "" {
+		return c.WebAuthPassword, nil
+	}
+	data, err := os.ReadFile(c.WebAuthPasswordFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read '-web-auth-password-file': %w", err)
+	}
+	password := strings.TrimRight(string(data), "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("'-web-auth-password-file' %s is empty", c.WebAuthPasswordFile)
+	}
+	return password, nil
+}
+
+// WebTLSConfig returns the config of the TLS connections to the exporter, as per the configured -web-tls-cert and
+// -web-tls-key, or nil if the exporter is served over plain HTTP.
+func (c *ExporterConfig) WebTLSConfig() (*tls.Config, error) {
+	if c.WebTLSCert == "" && c.WebTLSKey == "" {
+		return nil, nil
+	}
+	if c.WebTLSCert == "" || c.WebTLSKey == "" {
+		return nil, fmt.Errorf("'-web-tls-cert' and '-web-tls-key' must be set together")
+	}
+	cert, err := tls.LoadX509KeyPair(c.WebTLSCert, c.WebTLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load '-web-tls-cert': %w", err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, nil
+}
+
+// NewRPCClient creates an rpc client as per the config.
+func (c *ExporterConfig) NewRPCClient() *rpc.Client {
+	client := rpc.NewRPCClient(c.RpcUrl, c.HttpTimeout, c.FiredancerMetricsPort)
+	client.MethodTimeouts = c.RpcMethodTimeouts
+	// the TLS config is checked by Validate:
+	tlsConfig, _ := c.RpcTLSConfig()
+	client.HttpClient.Transport = rpc.NewTransport(
+		rpc.TransportConfig{
+			MaxIdleConnsPerHost: c.RpcMaxIdleConnsPerHost,
+			MaxConnsPerHost:     c.RpcMaxConnsPerHost,
+			IdleConnTimeout:     c.RpcIdleConnTimeout,
+			TLS:                 tlsConfig,
+		},
+	)
+	client.Gzip = c.RpcGzip
+	// the headers are checked by Validate:
+	client.Headers, _ = c.RpcHeaders()
+	return client
+}
+
+// LoadProbeKeypair loads the -probe-keypair, or returns nil if it is not set.
+func (c *ExporterConfig) LoadProbeKeypair() (ed25519.PrivateKey, error) {
+	if c.ProbeKeypair == "" {
+		return nil, nil
+	}
+	return LoadKeypair(c.ProbeKeypair)
+}
+
+// NewReferenceRPCClient creates an rpc client for the -reference-rpc-url, or returns nil if it is not set. The
+// -rpc-http-header and -rpc-auth-token headers, and the rpc TLS files, are meant for the node's own rpc, so they are not
+// used with it.
+func (c *ExporterConfig) NewReferenceRPCClient() *rpc.Client {
+	if c.ReferenceRpcUrl == "" {
+		return nil
+	}
+	client := rpc.NewRPCClient(c.ReferenceRpcUrl, c.HttpTimeout, c.FiredancerMetricsPort)
+	client.MethodTimeouts = c.RpcMethodTimeouts
+	client.HttpClient.Transport = rpc.NewTransport(
+		rpc.TransportConfig{
+			MaxIdleConnsPerHost: c.RpcMaxIdleConnsPerHost,
+			MaxConnsPerHost:     c.RpcMaxConnsPerHost,
+			IdleConnTimeout:     c.RpcIdleConnTimeout,
+		},
+	)
+	client.Gzip = c.RpcGzip
+	return client
+}
+
+// redact hides a secret value from the logs, only showing whether it is set.
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "<redacted>"
+}
+
+// redactHeaders hides the values of the provided 'Key: Value' headers from the logs.
+func redactHeaders(headers []string) []string {
+	redacted := make([]string, len(headers))
+	for i, header := range headers {
+		key, _, _ := strings.Cut(header, ":")
+		redacted[i] = fmt.Sprintf("%s: %s", key, redact("value"))
+	}
+	return redacted
+}
+
+// MetricEnabled returns whether the metric with the provided name should be exported, as per the configured
+// allowlist (-enable-metrics) and denylist (-disable-metrics). An empty allowlist allows all metrics.
+func (c *ExporterConfig) MetricEnabled(name string) bool {
+	if len(c.EnabledMetrics) > 0 && !slices.Contains(c.EnabledMetrics, name) {
+		return false
+	}
+	return !slices.Contains(c.DisabledMetrics, name)
+}
+
+// validatePubkeys checks that all the configured addresses are valid pubkeys, so that typos fail fast rather than
+// silently producing no metrics. Note that the tracked votekeys are not configured, but fetched from the rpc.
+func (c *ExporterConfig) validatePubkeys() error {
+	identityLabelKeys := make([]string, 0, len(c.IdentityLabels))
+	for nodekey := range c.IdentityLabels {
+		identityLabelKeys = append(identityLabelKeys, nodekey)
+	}
+	sort.Strings(identityLabelKeys)
+	balanceLabelKeys := make([]string, 0, len(c.BalanceAddressLabels))
+	for address := range c.BalanceAddressLabels {
+		balanceLabelKeys = append(balanceLabelKeys, address)
+	}
+	sort.Strings(balanceLabelKeys)
+	pubkeys := []struct {
+		flag    string
+		pubkeys []string
+	}{
+		{"-nodekey", c.NodeKeys},
+		{"-balance-address", c.BalanceAddresses},
+		{"-balance-address-labels", balanceLabelKeys},
+		{"-active-identity", []string{c.ActiveIdentity}},
+		{"-identity-labels", identityLabelKeys},
+		{"-stake-accounts", c.StakeAccounts},
+		{"-token-accounts", c.TokenAccounts},
+		{"-programs", c.Programs},
+		{"-stake-pool-withdraw-authority", []string{c.StakePoolWithdrawAuthority}},
+	}
+	for _, p := range pubkeys {
+		for _, pubkey := range p.pubkeys {
+			// optional pubkeys are empty if unset:
+			if pubkey == "" {
+				continue
+			}
+			if err := ValidatePubkey(pubkey); err != nil {
+				return fmt.Errorf("invalid '%s': %w", p.flag, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CollectorEnabled returns whether the collector with the provided name should run, as per the configured denylist
+// (-disable-collectors) and the presets which disable several collectors at once (-light-mode, which disables
+// LightModeCollectors, and -disable-version-compliance, which disables VersionComplianceCollectors). The collectors
+// of -enable-collectors run even if a preset disables them.
+func (c *ExporterConfig) CollectorEnabled(name string) bool {
+	switch {
+	case slices.Contains(c.DisabledCollectors, name):
+		return false
+	case slices.Contains(c.EnabledCollectors, name):
+		return true
+	case c.LightMode && slices.Contains(LightModeCollectors, name):
+		return false
+	case c.DisableVersionCompliance && slices.Contains(VersionComplianceCollectors, name):
+		return false
+	default:
+		return true
+	}
+}
+
+// CollectLogLevel returns the level at which the progress of each scrape is logged, as per -log-collect-verbosity.
+func (c *ExporterConfig) CollectLogLevel() zapcore.Level {
+	level, err := slog.ParseLevel(c.LogCollectVerbosity)
+	if err != nil {
+		return zapcore.DebugLevel
+	}
+	return level
+}
+
+// Commitment returns the commitment level to use for an RPC call: override if it is set (e.g.,
+// -vote-accounts-commitment), otherwise -default-commitment if it is set, and otherwise fallback, the call's own
+// default commitment level.
+func (c *ExporterConfig) Commitment(override, fallback rpc.Commitment) rpc.Commitment {
+	switch {
+	case override != "":
+		return override
+	case c.DefaultCommitment != "":
+		return c.DefaultCommitment
+	default:
+		return fallback
+	}
+}
+
+// SlotWatcherCommitment returns the commitment level at which the slot watcher follows the slots (e.g.,
+// solana_node_slot_height).
+func (c *ExporterConfig) SlotWatcherCommitment() rpc.Commitment {
+	return c.Commitment("", rpc.CommitmentFinalized)
+}
+
+// IdentityName returns the friendly name configured for the provided nodekey (through -identity-labels), or an
+// empty string if there is none.
+func (c *ExporterConfig) IdentityName(nodekey string) string {
+	return c.IdentityLabels[nodekey]
+}
+
+// BalanceAddressName returns the friendly name configured for the provided balance address (through
+// -balance-address-labels), or an empty string if there is none.
+func (c *ExporterConfig) BalanceAddressName(address string) string {
+	return c.BalanceAddressLabels[address]
+}
+
+// registerExporterConfigFlags binds all the command-line flags onto the fields of config, using the current
+// values of config as the flag defaults.
+func registerExporterConfigFlags(fs *flag.FlagSet, config *ExporterConfig, configFile *string) {
+	fs.StringVar(
+		configFile,
+		"config",
+		"",
+		"Path to a YAML config file. Flags that are explicitly set take precedence over the values in the file.",
+	)
+	fs.BoolVar(
+		&config.Once,
+		"once",
+		config.Once,
+		"Set this flag to run a single scrape, print the metrics to stdout and exit, e.g., to validate the config and "+
+			"rpc connectivity before deploying. Exits with a non-zero status if any metric could not be collected.",
+	)
+	fs.BoolVar(
+		&config.ListMetrics,
+		"list-metrics",
+		config.ListMetrics,
+		"Set this flag to print the name, labels and help of every metric and exit.",
+	)
+	fs.Var(
+		&secondsFlag{&config.HttpTimeout},
 		"http-timeout",
-		60,
 		"HTTP timeout to use, in seconds.",
 	)
-	flag.StringVar(
-		&rpcUrl,
+	fs.StringVar(
+		&config.RpcUrl,
 		"rpc-url",
-		"http://localhost:8899",
+		config.RpcUrl,
 		"Solana RPC URL (including protocol and path), "+
 			"e.g., 'http://localhost:8899' or 'https://api.mainnet-beta.solana.com'",
 	)
-	flag.StringVar(
-		&listenAddress,
+	fs.StringVar(
+		&config.ReferenceRpcUrl,
+		"reference-rpc-url",
+		config.ReferenceRpcUrl,
+		"Trusted Solana RPC URL to compare the slot of the node with (solana_reference_slot and "+
+			"solana_node_slots_behind_reference), e.g., 'https://api.mainnet-beta.solana.com'.",
+	)
+	fs.StringVar(
+		&config.ListenAddress,
 		"listen-address",
-		":8080",
+		config.ListenAddress,
 		"Listen address",
 	)
-	flag.Var(
-		&nodekeys,
+	fs.StringVar(
+		&config.PprofAddr,
+		"pprof-addr",
+		config.PprofAddr,
+		"Listen address of the pprof endpoints (under /debug/pprof/), which are only served if it is set.",
+	)
+	fs.StringVar(
+		&config.TextfileOutput,
+		"textfile-output",
+		config.TextfileOutput,
+		"Path of a .prom file to periodically write the metrics to, for node_exporter's textfile collector. The "+
+			"metrics are then not served over HTTP (i.e., on the -listen-address).",
+	)
+	fs.Var(
+		&secondsFlag{&config.TextfileInterval},
+		"textfile-interval",
+		"The time (in seconds) between writes of the -textfile-output, defaults to 60s.",
+	)
+	fs.StringVar(
+		&config.WebAuthUser,
+		"web-auth-user",
+		config.WebAuthUser,
+		"User of the HTTP basic authentication required on /metrics (requires -web-auth-password or "+
+			"-web-auth-password-file). By default, /metrics is served without authentication.",
+	)
+	fs.StringVar(
+		&config.WebAuthPassword,
+		"web-auth-password",
+		config.WebAuthPassword,
+		"Password of the -web-auth-user.",
+	)
+	fs.StringVar(
+		&config.WebAuthPasswordFile,
+		"web-auth-password-file",
+		config.WebAuthPasswordFile,
+		"Path to a file holding the password of the -web-auth-user, which keeps it out of the process arguments.",
+	)
+	fs.StringVar(
+		&config.WebTLSCert,
+		"web-tls-cert",
+		config.WebTLSCert,
+		"Path to the PEM certificate to serve /metrics and /healthz over HTTPS with (requires -web-tls-key). By "+
+			"default, they are served over plain HTTP.",
+	)
+	fs.StringVar(
+		&config.WebTLSKey,
+		"web-tls-key",
+		config.WebTLSKey,
+		"Path to the PEM private key of the -web-tls-cert.",
+	)
+	fs.Var(
+		&arrayFlags{values: &config.NodeKeys},
 		"nodekey",
 		"Solana nodekey (identity account) representing validator to monitor - can set multiple times.",
 	)
-	flag.Var(
-		&balanceAddresses,
+	fs.Var(
+		&arrayFlags{values: &config.BalanceAddresses},
 		"balance-address",
 		"Address to monitor SOL balances for, in addition to the identity and vote accounts of the "+
 			"provided nodekeys - can be set multiple times.",
 	)
-	flag.BoolVar(
-		&comprehensiveSlotTracking,
+	fs.Var(
+		&mapFlag{&config.BalanceAddressLabels},
+		"balance-address-labels",
+		"Comma-separated list of address=name pairs, attaching a friendly 'name' label to the balance metrics "+
+			"of the given addresses, e.g., '<TREASURY_ADDRESS>=treasury'.",
+	)
+	fs.IntVar(
+		&config.BalanceFetchConcurrency,
+		"balance-fetch-concurrency",
+		config.BalanceFetchConcurrency,
+		fmt.Sprintf(
+			"Maximum number of balance requests (of up to %d addresses each) in flight at once. At 1, all the "+
+				"balances are fetched in a single batch request, if the rpc supports it.",
+			rpc.MaxMultipleAccounts,
+		),
+	)
+	fs.BoolVar(
+		&config.ComprehensiveSlotTracking,
 		"comprehensive-slot-tracking",
-		false,
+		config.ComprehensiveSlotTracking,
 		"Set this flag to track solana_validator_leader_slots_by_epoch for all validators. "+
 			"Warning: this will lead to potentially thousands of new Prometheus metrics being created every epoch.",
 	)
-	flag.BoolVar(
-		&comprehensiveVoteAccountTracking,
+	fs.BoolVar(
+		&config.ComprehensiveVoteAccountTracking,
 		"comprehensive-vote-account-tracking",
-		false,
+		config.ComprehensiveVoteAccountTracking,
 		"Set this flag to track vote-account metrics such as solana_validator_active_stake for all validators. "+
 			"Warning: this will lead to potentially thousands of Prometheus metrics.",
 	)
-	flag.BoolVar(
-		&monitorBlockSizes,
+	fs.BoolVar(
+		&config.MonitorBlockSizes,
 		"monitor-block-sizes",
-		false,
+		config.MonitorBlockSizes,
 		"Set this flag to track block sizes (number of transactions) for the configured validators. "+
 			"Warning: this might grind the RPC node.",
 	)
-	flag.BoolVar(
-		&lightMode,
+	fs.BoolVar(
+		&config.LightMode,
 		"light-mode",
-		false,
+		config.LightMode,
 		"Set this flag to enable light-mode. In light mode, only metrics specific to the node being queried "+
 			"are reported (i.e., metrics such as inflation rewards which are visible from any RPC node, "+
 			"are not reported).",
 	)
-	flag.IntVar(
-		&slotPace,
+	fs.Var(
+		&secondsFlag{&config.SlotPace},
 		"slot-pace",
-		1,
 		"This is the time (in seconds) between slot-watching metric collections, defaults to 1s.",
 	)
-	flag.IntVar(
-		&epochCleanupTime,
+	fs.Var(
+		&secondsFlag{&config.EpochCleanupTime},
 		"epoch-cleanup-time",
-		60,
 		"The time (in seconds) to wait for end-of-epoch metrics to be scraped before cleaning, defaults to 60s",
 	)
-	flag.StringVar(
-		&activeIdentity,
+	fs.StringVar(
+		&config.ActiveIdentity,
 		"active-identity",
-		"",
+		config.ActiveIdentity,
 		"Validator identity public key that determines if the node is considered active in the 'solana_node_is_active' metric.",
 	)
-	flag.IntVar(
-		&firedancerMetricsPort,
+	fs.IntVar(
+		&config.FiredancerMetricsPort,
 		"firedancer-metrics-port",
-		7999,
+		config.FiredancerMetricsPort,
 		"Port number for Firedancer metrics endpoint",
 	)
-	flag.Parse()
-
-	config, err := NewExporterConfig(
-		ctx,
-		time.Duration(httpTimeout)*time.Second,
-		rpcUrl,
-		listenAddress,
-		nodekeys,
-		balanceAddresses,
-		comprehensiveSlotTracking,
-		comprehensiveVoteAccountTracking,
-		monitorBlockSizes,
-		lightMode,
-		time.Duration(slotPace)*time.Second,
-		activeIdentity,
-		time.Duration(epochCleanupTime)*time.Second,
-		firedancerMetricsPort,
+	fs.Var(
+		&commaSeparatedFlag{&config.DisabledMetrics},
+		"disable-metrics",
+		"Comma-separated list of metric names to not export, e.g., "+
+			"'solana_account_balance,solana_node_first_available_block'.",
+	)
+	fs.Var(
+		&commaSeparatedFlag{&config.EnabledMetrics},
+		"enable-metrics",
+		"Comma-separated list of metric names to export. If set, only these metrics are exported.",
+	)
+	fs.StringVar(
+		&config.MetricPrefix,
+		"metric-prefix",
+		config.MetricPrefix,
+		"Prefix of all the metric names, replacing the default 'solana_' (e.g., 'myorg_solana_').",
+	)
+	fs.Var(
+		&commaSeparatedFlag{&config.DisabledCollectors},
+		"disable-collectors",
+		"Comma-separated list of collectors to not run, e.g., 'vote_accounts,balances', for finer control than "+
+			"-light-mode.",
+	)
+	fs.Var(
+		&commaSeparatedFlag{&config.EnabledCollectors},
+		"enable-collectors",
+		"Comma-separated list of collectors to run even though -light-mode or -disable-version-compliance skips "+
+			"them, e.g., 'block_production,inflation_rewards'.",
+	)
+	fs.Var(
+		&mapFlag{&config.IdentityLabels},
+		"identity-labels",
+		"Comma-separated list of nodekey=name pairs, attaching a friendly 'name' label to the validator metrics "+
+			"of the given nodekeys, e.g., 'Certusm1sa411sMpV9FPqU5dXAYhmmhygvxJ23S6hJ24=certus'.",
+	)
+	fs.Var(
+		&secondsFlag{&config.HealthStaleness},
+		"health-staleness",
+		"The time (in seconds) after which /healthz reports the exporter as unhealthy if no scrape has "+
+			"completed without a fatal RPC failure, defaults to 300s.",
+	)
+	fs.IntVar(
+		&config.MaxConcurrentRPC,
+		"max-concurrent-rpc",
+		config.MaxConcurrentRPC,
+		"Maximum number of collectors (and so, RPC calls) to run concurrently during a scrape.",
+	)
+	fs.Var(
+		&secondsFlag{&config.FiredancerDetectionTTL},
+		"firedancer-detection-ttl",
+		"The time (in seconds) for which the outcome of Firedancer detection is cached, defaults to 60s. "+
+			"Set to 0 to detect Firedancer on every scrape.",
+	)
+	fs.BoolVar(
+		&config.ScrapeFiredancerMetrics,
+		"scrape-firedancer-metrics",
+		config.ScrapeFiredancerMetrics,
+		"Set this flag to re-export a curated subset of the Firedancer metrics (prefixed with 'solana_firedancer_'), "+
+			"when the node is running Firedancer.",
+	)
+	fs.StringVar(
+		(*string)(&config.DefaultCommitment),
+		"default-commitment",
+		string(config.DefaultCommitment),
+		"Commitment level used for all RPC calls, one of 'processed', 'confirmed' or 'finalized'. "+
+			"If unset, each call uses its own default commitment level. Per-metric commitment flags take precedence.",
+	)
+	fs.StringVar(
+		(*string)(&config.VoteAccountsCommitment),
+		"vote-accounts-commitment",
+		string(config.VoteAccountsCommitment),
+		"Commitment level used to fetch vote accounts, one of 'processed', 'confirmed' or 'finalized'. "+
+			"Defaults to '-default-commitment', or 'confirmed' if that is unset.",
+	)
+	fs.Var(
+		&commaSeparatedFlag{&config.StakeAccounts},
+		"stake-accounts",
+		"Comma-separated list of stake accounts to monitor the activation state of.",
+	)
+	fs.Var(
+		&commaSeparatedFlag{&config.TokenAccounts},
+		"token-accounts",
+		"Comma-separated list of SPL token accounts to monitor the balance of.",
+	)
+	fs.Var(
+		&commaSeparatedFlag{&config.Programs},
+		"programs",
+		"Comma-separated list of program IDs to monitor the upgrade authority and last deploy slot of.",
+	)
+	fs.StringVar(
+		&config.RequiredVersionsAPIURL,
+		"required-versions-api-url",
+		config.RequiredVersionsAPIURL,
+		"URL of the foundation required versions API, e.g., to use a mirror in air-gapped environments.",
+	)
+	fs.StringVar(
+		&config.ClusterName,
+		"cluster-name",
+		config.ClusterName,
+		"Name of the cluster of the node, which overrides its detection from the genesis hash, e.g., for private "+
+			"clusters whose genesis hash is unknown.",
+	)
+	fs.BoolVar(
+		&config.DisableVersionCompliance,
+		"disable-version-compliance",
+		config.DisableVersionCompliance,
+		"Set this flag to skip all version compliance metrics (which depend on the foundation required versions "+
+			"API), e.g., for private clusters.",
+	)
+	fs.BoolVar(
+		&config.MonitorLargestAccounts,
+		"monitor-largest-accounts",
+		config.MonitorLargestAccounts,
+		"Set this flag to track the balances of the largest accounts on the cluster "+
+			"(solana_cluster_largest_account_balance).",
+	)
+	fs.IntVar(
+		&config.LargestAccountsCount,
+		"largest-accounts-count",
+		config.LargestAccountsCount,
+		"Number of largest accounts to track (at most 20), if -monitor-largest-accounts is set.",
+	)
+	fs.Var(
+		&secondsFlag{&config.LargestAccountsInterval},
+		"largest-accounts-interval",
+		"The time (in seconds) for which the largest accounts are cached, as getLargestAccounts is expensive, "+
+			"defaults to 3600s.",
+	)
+	fs.StringVar(
+		&config.StakePoolWithdrawAuthority,
+		"stake-pool-withdraw-authority",
+		config.StakePoolWithdrawAuthority,
+		"Withdraw authority of a stake pool, to track the total stake and number of all the stake accounts it can "+
+			"withdraw from (solana_stake_pool_total_stake and solana_stake_pool_account_count).",
+	)
+	fs.Var(
+		&secondsFlag{&config.StakePoolInterval},
+		"stake-pool-interval",
+		"The time (in seconds) for which the stake pool is cached, as getProgramAccounts is expensive, "+
+			"defaults to 3600s.",
+	)
+	fs.StringVar(
+		&config.ProbeKeypair,
+		"probe-keypair",
+		config.ProbeKeypair,
+		"Path to a funded keypair file, to periodically submit a self-transfer through the node and measure the "+
+			"time it takes to be confirmed (solana_node_confirmation_latency_seconds). Every probe pays a fee.",
+	)
+	fs.Var(
+		&secondsFlag{&config.ProbeInterval},
+		"probe-interval",
+		"The time (in seconds) between confirmation latency probes (as every probe pays a fee), and between "+
+			"rpc port probes, defaults to 60s.",
+	)
+	fs.StringVar(
+		&config.ProbeRpcPorts,
+		"probe-rpc-ports",
+		config.ProbeRpcPorts,
+		fmt.Sprintf(
+			"Probe the rpc ports advertised in gossip with a getHealth request (solana_node_rpc_port_reachable), "+
+				"of the node only (%s) or of all the gossip nodes (%s), which adds one series per gossip node "+
+				"(thousands on mainnet-beta). Disabled if not set.",
+			RpcPortProbeSelf, RpcPortProbeAll,
+		),
 	)
+	fs.Int64Var(
+		&config.ExpectedFeatureSet,
+		"expected-feature-set",
+		config.ExpectedFeatureSet,
+		"Feature set the node is expected to run (solana_node_feature_set_matches_cluster), which defaults to "+
+			"the most common feature set in the gossip table of the node.",
+	)
+	fs.Var(
+		&intsFlag{&config.RentExemptDataSizes},
+		"rent-exempt-data-sizes",
+		"Comma-separated list of account data sizes (in bytes), e.g., 0,128,165, whose rent exempt minimum balance "+
+			"to report (solana_rent_exempt_minimum_lamports).",
+	)
+	fs.IntVar(
+		&config.PerformanceSampleCount,
+		"performance-sample-count",
+		config.PerformanceSampleCount,
+		"Number of the most recent (minutely) performance samples to report the TPS of (at most 60), in "+
+			"solana_cluster_performance_sample_tps. Set to 0 to not report any.",
+	)
+	fs.Var(
+		&arrayFlags{values: &config.RpcHttpHeaders},
+		"rpc-http-header",
+		"HTTP header to add to every rpc request, of the form 'Key: Value' (e.g., for rpc provider API keys) "+
+			"- can be set multiple times.",
+	)
+	fs.StringVar(
+		&config.RpcAuthToken,
+		"rpc-auth-token",
+		config.RpcAuthToken,
+		"Bearer token to authenticate every rpc request with (through the 'Authorization' header).",
+	)
+	fs.StringVar(
+		&config.RpcClientCert,
+		"rpc-client-cert",
+		config.RpcClientCert,
+		"Path to a PEM client certificate to present to an rpc requiring mutual TLS (requires -rpc-client-key).",
+	)
+	fs.StringVar(
+		&config.RpcClientKey,
+		"rpc-client-key",
+		config.RpcClientKey,
+		"Path to the PEM private key of the -rpc-client-cert.",
+	)
+	fs.StringVar(
+		&config.RpcCaCert,
+		"rpc-ca-cert",
+		config.RpcCaCert,
+		"Path to the PEM CA certificate(s) to verify the rpc with, instead of the system's (e.g., for private CAs).",
+	)
+	fs.BoolVar(
+		&config.RpcInsecureSkipVerify,
+		"rpc-insecure-skip-verify",
+		config.RpcInsecureSkipVerify,
+		"Set this flag to skip the verification of the rpc's TLS certificate, e.g., for self-signed certificates in "+
+			"lab environments. Warning: this exposes the connection to man-in-the-middle attacks.",
+	)
+	fs.Var(
+		&floatsFlag{&config.RpcLatencyBuckets},
+		"rpc-latency-buckets",
+		"Comma-separated list of the buckets (in seconds) of the 'solana_exporter_rpc_latency_seconds' histogram, "+
+			"defaults to 0.005 (5ms) up to 10.",
+	)
+	fs.IntVar(
+		&config.RpcMaxIdleConnsPerHost,
+		"rpc-max-idle-conns-per-host",
+		config.RpcMaxIdleConnsPerHost,
+		"Maximum number of idle (keep-alive) connections to keep open to the rpc node, for reuse across scrapes.",
+	)
+	fs.IntVar(
+		&config.RpcMaxConnsPerHost,
+		"rpc-max-conns-per-host",
+		config.RpcMaxConnsPerHost,
+		"Maximum number of connections to the rpc node, including those in use. Set to 0 for no limit.",
+	)
+	fs.Var(
+		&secondsFlag{&config.RpcIdleConnTimeout},
+		"rpc-idle-conn-timeout",
+		"The time (in seconds) after which idle connections to the rpc node are closed, defaults to 90s.",
+	)
+	fs.BoolVar(
+		&config.RpcGzip,
+		"rpc-gzip",
+		config.RpcGzip,
+		"Whether to request gzip-compressed rpc responses, which shrinks large responses (e.g., getVoteAccounts) "+
+			"on metered links.",
+	)
+	fs.StringVar(
+		&config.LogFormat,
+		"log-format",
+		config.LogFormat,
+		fmt.Sprintf("Format of the logs, one of %v.", slog.Formats),
+	)
+	fs.StringVar(
+		&config.LogLevel,
+		"log-level",
+		config.LogLevel,
+		"Level of the logs, one of debug, info, warn, error, panic or fatal. "+
+			"Defaults to the LOG_LEVEL environment variable, or info.",
+	)
+	fs.StringVar(
+		&config.LogCollectVerbosity,
+		"log-collect-verbosity",
+		config.LogCollectVerbosity,
+		"Level at which the progress of each scrape (e.g., 'Collecting vote accounts...') is logged, one of debug, "+
+			"info, warn, error, panic or fatal. Collection errors are always logged at the error level.",
+	)
+	for _, method := range rpc.Methods {
+		fs.Var(
+			&methodTimeoutFlag{timeouts: &config.RpcMethodTimeouts, method: method},
+			"rpc-timeout-"+method,
+			fmt.Sprintf("Timeout of %s rpc calls (e.g., '10s'), overriding -http-timeout.", method),
+		)
+	}
+}
+
+// ParseExporterConfigFlags parses the provided command-line arguments into an ExporterConfig. Flags that are
+// explicitly set take precedence over the values in the -config file, which in turn take precedence over defaults.
+func ParseExporterConfigFlags(fs *flag.FlagSet, args []string) (*ExporterConfig, error) {
+	// first, do a quiet pass just to find the config file, errors are reported by the second pass:
+	var (
+		configFile string
+		scratch    = DefaultExporterConfig()
+		firstPass  = flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	)
+	firstPass.SetOutput(io.Discard)
+	registerExporterConfigFlags(firstPass, &scratch, &configFile)
+	_ = firstPass.Parse(args)
+
+	config := DefaultExporterConfig()
+	if configFile != "" {
+		if err := LoadExporterConfigFile(configFile, &config); err != nil {
+			return nil, err
+		}
+	}
+
+	// now the flags are registered with the config-file values as their defaults:
+	registerExporterConfigFlags(fs, &config, &configFile)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func NewExporterConfigFromCLI(ctx context.Context) (*ExporterConfig, error) {
+	config, err := ParseExporterConfigFlags(flag.CommandLine, os.Args[1:])
 	if err != nil {
 		return nil, err
 	}
-	return config, nil
+	// the logger is reconfigured before anything is logged with the config:
+	if err := slog.Configure(config.LogFormat, config.LogLevel); err != nil {
+		return nil, err
+	}
+	return NewExporterConfig(ctx, *config)
+}
+
+// parseCommaSeparated splits a comma-separated flag value into its (trimmed, non-empty) items.
+func parseCommaSeparated(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -2,106 +2,503 @@ package main
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"flag"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/asymmetric-research/solana-exporter/pkg/api"
+	"github.com/asymmetric-research/solana-exporter/pkg/rpc"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
 )
 
 func TestNewExporterConfig(t *testing.T) {
 	simulator, _ := NewSimulator(t, 35)
+	// pubkeys are validated, so track a validator with real keys rather than the simulator's placeholders:
+	nodekey, votekey := "Certusm1sa411sMpV9FPqU5dXAYhmmhygvxJ23S6hJ24", "CertusDeBmqN8ZawdkxK5kFGMwBXdudvWHYwtNgNhvLu"
+	simulator.Server.SetOpt(rpc.ValidatorInfoOpt, nodekey, rpc.MockValidatorInfo{Votekey: votekey})
+	balanceAddresses := []string{
+		"3ZT31jkAGhUaw8jsy4bTknwBMP8i4Eueh52By4zXcsVw", "B97CCUW3AEZFGy6uUg6zUdnNYvnVq5VG8PUtb2HayTDD",
+	}
 	tests := []struct {
-		name                             string
-		httpTimeout                      time.Duration
-		rpcUrl                           string
-		listenAddress                    string
-		nodeKeys                         []string
-		balanceAddresses                 []string
-		comprehensiveSlotTracking        bool
-		comprehensiveVoteAccountTracking bool
-		monitorBlockSizes                bool
-		lightMode                        bool
-		slotPace                         time.Duration
-		epochCleanupTime                 time.Duration
-		wantErr                          bool
-		expectedVoteKeys                 []string
-		activeIdentity                   string
-		firedancerMetricsPort            int
+		name             string
+		config           ExporterConfig
+		wantErr          bool
+		expectedVoteKeys []string
 	}{
 		{
-			name:                             "valid configuration",
-			httpTimeout:                      60 * time.Second,
-			rpcUrl:                           simulator.Server.URL(),
-			listenAddress:                    ":8080",
-			nodeKeys:                         simulator.Nodekeys,
-			balanceAddresses:                 []string{"xxx", "yyy", "zzz"},
-			comprehensiveSlotTracking:        false,
-			comprehensiveVoteAccountTracking: false,
-			monitorBlockSizes:                false,
-			lightMode:                        false,
-			slotPace:                         time.Second,
-			epochCleanupTime:                 60 * time.Second,
-			wantErr:                          false,
-			expectedVoteKeys:                 simulator.Votekeys,
-			activeIdentity:                   simulator.Nodekeys[0],
-			firedancerMetricsPort:            7999,
-		},
-		{
-			name:                             "light mode with incompatible options",
-			httpTimeout:                      60 * time.Second,
-			rpcUrl:                           simulator.Server.URL(),
-			listenAddress:                    ":8080",
-			nodeKeys:                         simulator.Nodekeys,
-			balanceAddresses:                 []string{"xxx", "yyy", "zzz"},
-			comprehensiveSlotTracking:        false,
-			comprehensiveVoteAccountTracking: false,
-			monitorBlockSizes:                false,
-			lightMode:                        true,
-			slotPace:                         time.Second,
-			epochCleanupTime:                 60 * time.Second,
-			wantErr:                          true,
-			expectedVoteKeys:                 nil,
-			activeIdentity:                   simulator.Nodekeys[0],
-			firedancerMetricsPort:            7999,
-		},
-		{
-			name:                             "empty node keys",
-			httpTimeout:                      60 * time.Second,
-			rpcUrl:                           simulator.Server.URL(),
-			listenAddress:                    ":8080",
-			nodeKeys:                         []string{},
-			balanceAddresses:                 []string{"xxx", "yyy", "zzz"},
-			comprehensiveSlotTracking:        false,
-			comprehensiveVoteAccountTracking: false,
-			monitorBlockSizes:                false,
-			lightMode:                        false,
-			slotPace:                         time.Second,
-			epochCleanupTime:                 60 * time.Second,
-			wantErr:                          false,
-			expectedVoteKeys:                 []string{},
-			activeIdentity:                   simulator.Nodekeys[0],
-			firedancerMetricsPort:            7999,
+			name: "valid configuration",
+			config: ExporterConfig{
+				HttpTimeout:                      60 * time.Second,
+				RpcUrl:                           simulator.Server.URL(),
+				ListenAddress:                    ":8080",
+				NodeKeys:                         []string{nodekey},
+				BalanceAddresses:                 balanceAddresses,
+				ComprehensiveSlotTracking:        false,
+				ComprehensiveVoteAccountTracking: false,
+				MonitorBlockSizes:                false,
+				LightMode:                        false,
+				SlotPace:                         time.Second,
+				EpochCleanupTime:                 60 * time.Second,
+				ActiveIdentity:                   nodekey,
+				FiredancerMetricsPort:            7999,
+				DisabledMetrics:                  []string{"solana_account_balance"},
+				HealthStaleness:                  5 * time.Minute,
+				MaxConcurrentRPC:                 4,
+				VoteAccountsCommitment:           rpc.CommitmentConfirmed,
+				RequiredVersionsAPIURL:           api.SolanaEpochStatsAPI,
+			},
+			wantErr:          false,
+			expectedVoteKeys: []string{votekey},
+		},
+		{
+			name: "light mode with incompatible options",
+			config: ExporterConfig{
+				HttpTimeout:                      60 * time.Second,
+				RpcUrl:                           simulator.Server.URL(),
+				ListenAddress:                    ":8080",
+				NodeKeys:                         simulator.Nodekeys,
+				BalanceAddresses:                 []string{"xxx", "yyy", "zzz"},
+				ComprehensiveSlotTracking:        false,
+				ComprehensiveVoteAccountTracking: false,
+				MonitorBlockSizes:                false,
+				LightMode:                        true,
+				SlotPace:                         time.Second,
+				EpochCleanupTime:                 60 * time.Second,
+				ActiveIdentity:                   simulator.Nodekeys[0],
+				FiredancerMetricsPort:            7999,
+			},
+			wantErr:          true,
+			expectedVoteKeys: nil,
+		},
+		{
+			name: "empty node keys",
+			config: ExporterConfig{
+				HttpTimeout:                      60 * time.Second,
+				RpcUrl:                           simulator.Server.URL(),
+				ListenAddress:                    ":8080",
+				NodeKeys:                         []string{},
+				BalanceAddresses:                 balanceAddresses,
+				ComprehensiveSlotTracking:        false,
+				ComprehensiveVoteAccountTracking: false,
+				MonitorBlockSizes:                false,
+				LightMode:                        false,
+				SlotPace:                         time.Second,
+				EpochCleanupTime:                 60 * time.Second,
+				ActiveIdentity:                   nodekey,
+				FiredancerMetricsPort:            7999,
+				HealthStaleness:                  5 * time.Minute,
+				MaxConcurrentRPC:                 4,
+				VoteAccountsCommitment:           rpc.CommitmentConfirmed,
+				RequiredVersionsAPIURL:           api.SolanaEpochStatsAPI,
+			},
+			wantErr:          false,
+			expectedVoteKeys: []string{},
+		},
+		{
+			name: "invalid vote accounts commitment",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				VoteAccountsCommitment: "eventually",
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid default commitment",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				DefaultCommitment:      "eventually",
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative rpc max conns per host",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				RpcMaxConnsPerHost:     -1,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative balance fetch concurrency",
+			config: ExporterConfig{
+				HttpTimeout:             60 * time.Second,
+				RpcUrl:                  simulator.Server.URL(),
+				ListenAddress:           ":8080",
+				SlotPace:                time.Second,
+				HealthStaleness:         5 * time.Minute,
+				MaxConcurrentRPC:        4,
+				BalanceFetchConcurrency: -1,
+				RequiredVersionsAPIURL:  api.SolanaEpochStatsAPI,
+			},
+			wantErr: true,
+		},
+		{
+			name: "web auth user without password",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+				WebAuthUser:            "prometheus",
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing web auth password file",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+				WebAuthUser:            "prometheus",
+				WebAuthPasswordFile:    "testdata/missing-password",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid reference rpc url",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ReferenceRpcUrl:        "api.mainnet-beta.solana.com",
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+			},
+			wantErr: true,
+		},
+		{
+			name: "pprof on listen address",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				PprofAddr:              ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+			},
+			wantErr: true,
+		},
+		{
+			name: "rent exempt data size too large",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+				RentExemptDataSizes:    []int64{0, 11 * 1024 * 1024},
+			},
+			wantErr: true,
+		},
+		{
+			name: "rpc client cert without key",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+				RpcClientCert:          "client.crt",
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing probe keypair",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+				ProbeKeypair:           "missing.json",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid log format",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+				LogFormat:              "xml",
+			},
+			wantErr: true,
+		},
+		{
+			name: "textfile output without the .prom extension",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+				TextfileOutput:         "/var/lib/node_exporter/solana.txt",
+				TextfileInterval:       time.Minute,
+			},
+			wantErr: true,
+		},
+		{
+			name: "too many performance samples",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+				PerformanceSampleCount: 61,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid rpc port probe mode",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+				ProbeRpcPorts:          "peers",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid log level",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+				LogLevel:               "verbose",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid log collect verbosity",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+				LogCollectVerbosity:    "verbose",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid metric prefix",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				MetricPrefix:           "my-org_",
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+			},
+			wantErr: true,
+		},
+		{
+			name: "decreasing rpc latency buckets",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				RpcLatencyBuckets:      []float64{1, 0.5},
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid required versions api url",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				VoteAccountsCommitment: rpc.CommitmentConfirmed,
+				RequiredVersionsAPIURL: "not a url",
+			},
+			wantErr: true,
+		},
+		{
+			name: "disabled version compliance without api url",
+			config: ExporterConfig{
+				HttpTimeout:              60 * time.Second,
+				RpcUrl:                   simulator.Server.URL(),
+				ListenAddress:            ":8080",
+				SlotPace:                 time.Second,
+				HealthStaleness:          5 * time.Minute,
+				MaxConcurrentRPC:         4,
+				VoteAccountsCommitment:   rpc.CommitmentConfirmed,
+				DisableVersionCompliance: true,
+			},
+			wantErr:          false,
+			expectedVoteKeys: []string{},
+		},
+		{
+			name: "invalid largest accounts count",
+			config: ExporterConfig{
+				HttpTimeout:             60 * time.Second,
+				RpcUrl:                  simulator.Server.URL(),
+				ListenAddress:           ":8080",
+				SlotPace:                time.Second,
+				HealthStaleness:         5 * time.Minute,
+				MaxConcurrentRPC:        4,
+				VoteAccountsCommitment:  rpc.CommitmentConfirmed,
+				RequiredVersionsAPIURL:  api.SolanaEpochStatsAPI,
+				MonitorLargestAccounts:  true,
+				LargestAccountsCount:    50,
+				LargestAccountsInterval: time.Hour,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid balance address",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				VoteAccountsCommitment: rpc.CommitmentConfirmed,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+				// a typo'd address, with an 'l' (not in the base58 alphabet) instead of a '1':
+				BalanceAddresses: []string{"3ZT3ljkAGhUaw8jsy4bTknwBMP8i4Eueh52By4zXcsVw"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown nodekey",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				VoteAccountsCommitment: rpc.CommitmentConfirmed,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+				// a valid pubkey, but not that of any validator:
+				NodeKeys: balanceAddresses[:1],
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown disabled collector",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				VoteAccountsCommitment: rpc.CommitmentConfirmed,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+				DisabledCollectors:     []string{"vote_accounts", "unknown"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "collector both enabled and disabled",
+			config: ExporterConfig{
+				HttpTimeout:            60 * time.Second,
+				RpcUrl:                 simulator.Server.URL(),
+				ListenAddress:          ":8080",
+				SlotPace:               time.Second,
+				HealthStaleness:        5 * time.Minute,
+				MaxConcurrentRPC:       4,
+				VoteAccountsCommitment: rpc.CommitmentConfirmed,
+				RequiredVersionsAPIURL: api.SolanaEpochStatsAPI,
+				DisabledCollectors:     []string{CollectorBlockProduction},
+				EnabledCollectors:      []string{CollectorBlockProduction},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing rpc url",
+			config: ExporterConfig{
+				HttpTimeout:      60 * time.Second,
+				ListenAddress:    ":8080",
+				SlotPace:         time.Second,
+				EpochCleanupTime: 60 * time.Second,
+			},
+			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			config, err := NewExporterConfig(
-				context.Background(),
-				tt.httpTimeout,
-				tt.rpcUrl,
-				tt.listenAddress,
-				tt.nodeKeys,
-				tt.balanceAddresses,
-				tt.comprehensiveSlotTracking,
-				tt.comprehensiveVoteAccountTracking,
-				tt.monitorBlockSizes,
-				tt.lightMode,
-				tt.slotPace,
-				tt.activeIdentity,
-				tt.epochCleanupTime,
-				tt.firedancerMetricsPort,
-			)
+			config, err := NewExporterConfig(context.Background(), tt.config)
 
 			// Check error expectation
 			if tt.wantErr {
@@ -112,19 +509,453 @@ func TestNewExporterConfig(t *testing.T) {
 			assert.NoError(t, err)
 
 			// Verify config values
-			assert.Equal(t, tt.httpTimeout, config.HttpTimeout)
-			assert.Equal(t, tt.rpcUrl, config.RpcUrl)
-			assert.Equal(t, tt.listenAddress, config.ListenAddress)
-			assert.Equal(t, tt.nodeKeys, config.NodeKeys)
-			assert.Equal(t, tt.balanceAddresses, config.BalanceAddresses)
-			assert.Equal(t, tt.comprehensiveSlotTracking, config.ComprehensiveSlotTracking)
-			assert.Equal(t, tt.comprehensiveVoteAccountTracking, config.ComprehensiveVoteAccountTracking)
-			assert.Equal(t, tt.lightMode, config.LightMode)
-			assert.Equal(t, tt.slotPace, config.SlotPace)
-			assert.Equal(t, tt.epochCleanupTime, config.EpochCleanupTime)
-			assert.Equal(t, tt.monitorBlockSizes, config.MonitorBlockSizes)
-			assert.Equal(t, tt.expectedVoteKeys, config.VoteKeys)
-			assert.Equal(t, tt.firedancerMetricsPort, config.FiredancerMetricsPort)
+			expected := tt.config
+			expected.VoteKeys = tt.expectedVoteKeys
+			assert.Equal(t, expected, *config)
 		})
 	}
 }
+
+func TestLoadExporterConfigFile(t *testing.T) {
+	config := ExporterConfig{
+		HttpTimeout:               30 * time.Second,
+		RpcUrl:                    "http://localhost:8899",
+		ListenAddress:             ":9090",
+		NodeKeys:                  []string{"aaa", "bbb"},
+		BalanceAddresses:          []string{"xxx"},
+		ComprehensiveSlotTracking: true,
+		MonitorBlockSizes:         true,
+		SlotPace:                  2 * time.Second,
+		ActiveIdentity:            "aaa",
+		EpochCleanupTime:          time.Minute,
+		FiredancerMetricsPort:     7999,
+		DisabledMetrics:           []string{"solana_account_balance"},
+	}
+
+	t.Run("round-trip", func(t *testing.T) {
+		data, err := yaml.Marshal(&config)
+		assert.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		assert.NoError(t, os.WriteFile(path, data, 0o600))
+
+		var loaded ExporterConfig
+		assert.NoError(t, LoadExporterConfigFile(path, &loaded))
+		assert.Equal(t, config, loaded)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		assert.NoError(t, os.WriteFile(path, []byte("rpc_uri: http://localhost:8899\n"), 0o600))
+
+		var loaded ExporterConfig
+		assert.Error(t, LoadExporterConfigFile(path, &loaded))
+	})
+}
+
+func TestParseExporterConfigFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := []byte(`
+rpc_url: http://file:8899
+listen_address: ":9090"
+node_keys: [aaa, bbb]
+http_timeout: 30s
+`)
+	assert.NoError(t, os.WriteFile(path, data, 0o600))
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected func(config *ExporterConfig)
+	}{
+		{
+			name: "defaults",
+			args: []string{},
+			expected: func(config *ExporterConfig) {
+				*config = DefaultExporterConfig()
+			},
+		},
+		{
+			name: "file beats default",
+			args: []string{"-config", path},
+			expected: func(config *ExporterConfig) {
+				config.RpcUrl = "http://file:8899"
+				config.ListenAddress = ":9090"
+				config.NodeKeys = []string{"aaa", "bbb"}
+				config.HttpTimeout = 30 * time.Second
+			},
+		},
+		{
+			name: "rpc method timeouts",
+			args: []string{"-rpc-timeout-getVoteAccounts", "10s", "-rpc-timeout-getBlock=1m"},
+			expected: func(config *ExporterConfig) {
+				config.RpcMethodTimeouts = map[string]time.Duration{
+					"getVoteAccounts": 10 * time.Second,
+					"getBlock":        time.Minute,
+				}
+			},
+		},
+		{
+			name: "rpc connection pooling",
+			args: []string{
+				"-rpc-max-idle-conns-per-host", "32", "-rpc-max-conns-per-host=64", "-rpc-idle-conn-timeout", "30",
+			},
+			expected: func(config *ExporterConfig) {
+				config.RpcMaxIdleConnsPerHost = 32
+				config.RpcMaxConnsPerHost = 64
+				config.RpcIdleConnTimeout = 30 * time.Second
+			},
+		},
+		{
+			name: "balance fetch concurrency",
+			args: []string{"-balance-fetch-concurrency", "8"},
+			expected: func(config *ExporterConfig) {
+				config.BalanceFetchConcurrency = 8
+			},
+		},
+		{
+			name: "list metrics",
+			args: []string{"-list-metrics"},
+			expected: func(config *ExporterConfig) {
+				config.ListMetrics = true
+			},
+		},
+		{
+			name: "textfile output",
+			args: []string{"-textfile-output", "/var/lib/node_exporter/solana.prom", "-textfile-interval", "30"},
+			expected: func(config *ExporterConfig) {
+				config.TextfileOutput = "/var/lib/node_exporter/solana.prom"
+				config.TextfileInterval = 30 * time.Second
+			},
+		},
+		{
+			name: "performance sample count",
+			args: []string{"-performance-sample-count", "30"},
+			expected: func(config *ExporterConfig) {
+				config.PerformanceSampleCount = 30
+			},
+		},
+		{
+			name: "probe rpc ports",
+			args: []string{"-probe-rpc-ports", "all"},
+			expected: func(config *ExporterConfig) {
+				config.ProbeRpcPorts = RpcPortProbeAll
+			},
+		},
+		{
+			name: "balance address labels",
+			args: []string{"-balance-address-labels", "aaa=treasury,bbb=fees"},
+			expected: func(config *ExporterConfig) {
+				config.BalanceAddressLabels = map[string]string{"aaa": "treasury", "bbb": "fees"}
+			},
+		},
+		{
+			name: "rpc latency buckets",
+			args: []string{"-rpc-latency-buckets", "0.1, 1,10"},
+			expected: func(config *ExporterConfig) {
+				config.RpcLatencyBuckets = []float64{0.1, 1, 10}
+			},
+		},
+		{
+			name: "rent exempt data sizes",
+			args: []string{"-rent-exempt-data-sizes", "0, 128,165"},
+			expected: func(config *ExporterConfig) {
+				config.RentExemptDataSizes = []int64{0, 128, 165}
+			},
+		},
+		{
+			name: "programs",
+			args: []string{"-programs", "aaa, bbb"},
+			expected: func(config *ExporterConfig) {
+				config.Programs = []string{"aaa", "bbb"}
+			},
+		},
+		{
+			name: "cluster name",
+			args: []string{"-cluster-name", "localnet"},
+			expected: func(config *ExporterConfig) {
+				config.ClusterName = "localnet"
+			},
+		},
+		{
+			name: "rpc gzip",
+			args: []string{"-rpc-gzip"},
+			expected: func(config *ExporterConfig) {
+				config.RpcGzip = true
+			},
+		},
+		{
+			name: "log collect verbosity",
+			args: []string{"-log-collect-verbosity", "info"},
+			expected: func(config *ExporterConfig) {
+				config.LogCollectVerbosity = "info"
+			},
+		},
+		{
+			name: "flag beats file",
+			args: []string{"-rpc-url", "http://flag:8899", "-config", path, "-nodekey", "ccc", "-http-timeout", "5"},
+			expected: func(config *ExporterConfig) {
+				config.RpcUrl = "http://flag:8899"
+				config.ListenAddress = ":9090"
+				config.NodeKeys = []string{"ccc"}
+				config.HttpTimeout = 5 * time.Second
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := DefaultExporterConfig()
+			tt.expected(&expected)
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			config, err := ParseExporterConfigFlags(fs, tt.args)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, *config)
+		})
+	}
+}
+
+func TestExporterConfig_MetricEnabled(t *testing.T) {
+	tests := []struct {
+		name            string
+		disabledMetrics []string
+		enabledMetrics  []string
+		metric          string
+		want            bool
+	}{
+		{"no filters", nil, nil, "solana_account_balance", true},
+		{"disabled", []string{"solana_account_balance"}, nil, "solana_account_balance", false},
+		{"not disabled", []string{"solana_account_balance"}, nil, "solana_node_version", true},
+		{"in allowlist", nil, []string{"solana_node_version"}, "solana_node_version", true},
+		{"not in allowlist", nil, []string{"solana_node_version"}, "solana_account_balance", false},
+		{
+			"denylist beats allowlist",
+			[]string{"solana_node_version"},
+			[]string{"solana_node_version"},
+			"solana_node_version",
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := ExporterConfig{DisabledMetrics: tt.disabledMetrics, EnabledMetrics: tt.enabledMetrics}
+			assert.Equal(t, tt.want, config.MetricEnabled(tt.metric))
+		})
+	}
+}
+
+func TestExporterConfig_CollectorEnabled(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ExporterConfig
+		want   map[string]bool
+	}{
+		{
+			name:   "defaults",
+			config: ExporterConfig{},
+			want:   map[string]bool{CollectorVoteAccounts: true, CollectorBalances: true, CollectorHealth: true},
+		},
+		{
+			name:   "light mode",
+			config: ExporterConfig{LightMode: true},
+			want: map[string]bool{
+				CollectorVoteAccounts: false, CollectorBalances: false, CollectorHealth: true, CollectorVersion: true,
+			},
+		},
+		{
+			// the slot watcher collectors are part of the preset too:
+			name:   "light mode slot watcher",
+			config: ExporterConfig{LightMode: true},
+			want: map[string]bool{
+				CollectorBlockProduction: false, CollectorBlockInfos: false, CollectorInflationRewards: false,
+			},
+		},
+		{
+			name: "light mode with enabled collectors",
+			config: ExporterConfig{
+				LightMode: true, EnabledCollectors: []string{CollectorBlockProduction, CollectorBalances},
+			},
+			want: map[string]bool{
+				CollectorBlockProduction: true, CollectorBalances: true, CollectorInflationRewards: false,
+				CollectorVoteAccounts: false,
+			},
+		},
+		{
+			name:   "disabled slot watcher collector",
+			config: ExporterConfig{DisabledCollectors: []string{CollectorInflationRewards}},
+			want:   map[string]bool{CollectorInflationRewards: false, CollectorBlockProduction: true},
+		},
+		{
+			name:   "custom preset",
+			config: ExporterConfig{DisabledCollectors: []string{CollectorVoteAccounts}},
+			want:   map[string]bool{CollectorVoteAccounts: false, CollectorBalances: true, CollectorHealth: true},
+		},
+		{
+			name:   "disabled version compliance",
+			config: ExporterConfig{DisableVersionCompliance: true},
+			want:   map[string]bool{CollectorNodeIsOutdated: false, CollectorVersion: true, CollectorVoteAccounts: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, want := range tt.want {
+				assert.Equalf(t, want, tt.config.CollectorEnabled(name), "collector %s", name)
+			}
+		})
+	}
+}
+
+func TestParseCommaSeparated(t *testing.T) {
+	assert.Nil(t, parseCommaSeparated(""))
+	assert.Equal(t,
+		[]string{"solana_account_balance", "solana_node_first_available_block"},
+		parseCommaSeparated("solana_account_balance, solana_node_first_available_block,"),
+	)
+}
+
+func TestMapFlag(t *testing.T) {
+	var values map[string]string
+	flagValue := mapFlag{&values}
+
+	assert.NoError(t, flagValue.Set("aaa=alpha, bbb=bravo"))
+	assert.Equal(t, map[string]string{"aaa": "alpha", "bbb": "bravo"}, values)
+	assert.Equal(t, "aaa=alpha,bbb=bravo", flagValue.String())
+
+	assert.Error(t, flagValue.Set("aaa"))
+	assert.Error(t, flagValue.Set("=alpha"))
+}
+
+func TestExporterConfig_NewRPCClient(t *testing.T) {
+	server, _ := rpc.NewMockClient(t, map[string]any{"getHealth": "ok"}, nil, nil, nil, nil, nil)
+	config := DefaultExporterConfig()
+	config.RpcUrl = server.URL()
+	config.RpcHttpHeaders = []string{"X-Api-Key: secret", "X-Provider:acme"}
+	config.RpcAuthToken = "token"
+	assert.NoError(t, config.Validate())
+
+	client := config.NewRPCClient()
+	_, err := client.GetHealth(context.Background())
+	assert.NoError(t, err)
+	headers := server.LastHeaders("getHealth")
+	assert.Equal(t, "secret", headers.Get("X-Api-Key"))
+	assert.Equal(t, "acme", headers.Get("X-Provider"))
+	assert.Equal(t, "Bearer token", headers.Get("Authorization"))
+
+	config.RpcHttpHeaders = []string{"no-separator"}
+	assert.Error(t, config.Validate())
+	assert.Equal(t, []string{"X-Api-Key: <redacted>"}, redactHeaders([]string{"X-Api-Key: secret"}))
+}
+
+func TestExporterConfig_RpcInsecureSkipVerify(t *testing.T) {
+	// an rpc with a self-signed certificate:
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","result":"ok","id":1}`))
+	}))
+	defer server.Close()
+	config := DefaultExporterConfig()
+	config.RpcUrl = server.URL
+
+	// is rejected by default:
+	_, err := config.NewRPCClient().GetHealth(context.Background())
+	assert.Error(t, err)
+
+	// unless the verification is skipped:
+	config.RpcInsecureSkipVerify = true
+	assert.NoError(t, config.Validate())
+	health, err := config.NewRPCClient().GetHealth(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", health)
+}
+
+func TestExporterConfig_LoadWebAuthPassword(t *testing.T) {
+	config := DefaultExporterConfig()
+	config.WebAuthUser, config.WebAuthPassword = "prometheus", "secret"
+	assert.NoError(t, config.Validate())
+	password, err := config.LoadWebAuthPassword()
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", password)
+
+	// the trailing newline of the password file is trimmed:
+	path := filepath.Join(t.TempDir(), "password")
+	assert.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+	config.WebAuthPassword, config.WebAuthPasswordFile = "", path
+	assert.NoError(t, config.Validate())
+	password, err = config.LoadWebAuthPassword()
+	assert.NoError(t, err)
+	assert.Equal(t, "from-file", password)
+
+	// but both cannot be set:
+	config.WebAuthPassword = "secret"
+	assert.Error(t, config.Validate())
+}
+
+// writeSelfSignedCertificate writes a self-signed certificate for 127.0.0.1 and its key to dir, and returns their paths
+// along with a pool trusting the certificate.
+func writeSelfSignedCertificate(t *testing.T, dir string) (certFile, keyFile string, pool *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "solana-exporter"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certFile, keyFile = filepath.Join(dir, "exporter.crt"), filepath.Join(dir, "exporter.key")
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	assert.NoError(t, os.WriteFile(certFile, certPem, 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, keyPem, 0o600))
+	pool = x509.NewCertPool()
+	assert.True(t, pool.AppendCertsFromPEM(certPem))
+	return certFile, keyFile, pool
+}
+
+func TestExporterConfig_WebTLSConfig(t *testing.T) {
+	config := DefaultExporterConfig()
+	tlsConfig, err := config.WebTLSConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+
+	certFile, keyFile, pool := writeSelfSignedCertificate(t, t.TempDir())
+	config.WebTLSCert = certFile
+	assert.Error(t, config.Validate(), "the key is required")
+	config.WebTLSKey = keyFile
+	assert.NoError(t, config.Validate())
+	tlsConfig, err = config.WebTLSConfig()
+	assert.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("metrics"))
+	}))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	// the server presents the configured certificate:
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	response, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	//goland:noinspection GoUnhandledErrorResult
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NotNil(t, response.TLS)
+
+	// and plain HTTP is not served:
+	plainResponse, err := http.Get("http://" + server.Listener.Addr().String())
+	if err == nil {
+		//goland:noinspection GoUnhandledErrorResult
+		defer plainResponse.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, plainResponse.StatusCode)
+	}
+}
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"github.com/asymmetric-research/solana-exporter/pkg/rpc"
 	"github.com/asymmetric-research/solana-exporter/pkg/slog"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// invalidMetric is a metric which could not be collected, which is told apart from the valid metrics so that the
+// collectors emitting it are counted as having failed.
+type invalidMetric struct {
+	prometheus.Metric
+}
+
 type GaugeDesc struct {
 	Desc           *prometheus.Desc
 	Name           string
@@ -12,7 +19,10 @@ type GaugeDesc struct {
 	VariableLabels []string
 }
 
-func NewGaugeDesc(name string, description string, variableLabels ...string) *GaugeDesc {
+// NewGaugeDesc creates the descriptor of a gauge named name, whose rpc.DefaultMetricPrefix is replaced by prefix (see
+// -metric-prefix).
+func NewGaugeDesc(prefix string, name string, description string, variableLabels ...string) *GaugeDesc {
+	name = rpc.PrefixedName(prefix, name)
 	return &GaugeDesc{
 		Desc:           prometheus.NewDesc(name, description, variableLabels, nil),
 		Name:           name,
@@ -30,6 +40,16 @@ func (c *GaugeDesc) MustNewConstMetric(value float64, labels ...string) promethe
 	return prometheus.MustNewConstMetric(c.Desc, prometheus.GaugeValue, value, labels...)
 }
 
+// MustNewConstCounter is like MustNewConstMetric, but emits a counter, for values which never decrease.
+func (c *GaugeDesc) MustNewConstCounter(value float64, labels ...string) prometheus.Metric {
+	logger := slog.Get()
+	if len(labels) != len(c.VariableLabels) {
+		logger.Fatalf("Provided labels (%v) do not match %s labels (%v)", labels, c.Name, c.VariableLabels)
+	}
+	logger.Debugf("Emitting %v to %s(%v)", value, labels, c.Name)
+	return prometheus.MustNewConstMetric(c.Desc, prometheus.CounterValue, value, labels...)
+}
+
 func (c *GaugeDesc) NewInvalidMetric(err error) prometheus.Metric {
-	return prometheus.NewInvalidMetric(c.Desc, err)
+	return invalidMetric{prometheus.NewInvalidMetric(c.Desc, err)}
 }
@@ -21,8 +21,12 @@ func NewLV(value float64, labels ...string) LV {
 }
 
 func (c *GaugeDesc) expectedCollection(labeledValues ...LV) string {
+	return c.expectedCollectionOfType("gauge", labeledValues...)
+}
+
+func (c *GaugeDesc) expectedCollectionOfType(metricType string, labeledValues ...LV) string {
 	helpLine := fmt.Sprintf("# HELP %s %s", c.Name, c.Help)
-	typeLine := fmt.Sprintf("# TYPE %s gauge", c.Name)
+	typeLine := fmt.Sprintf("# TYPE %s %s", c.Name, metricType)
 	result := fmt.Sprintf("%s\n%s", helpLine, typeLine)
 
 	// we need to sort our variable labels:
@@ -47,3 +51,8 @@ func (c *GaugeDesc) expectedCollection(labeledValues ...LV) string {
 func (c *GaugeDesc) makeCollectionTest(labeledValues ...LV) collectionTest {
 	return collectionTest{Name: c.Name, ExpectedResponse: c.expectedCollection(labeledValues...)}
 }
+
+// makeCounterCollectionTest is like makeCollectionTest, for descriptors emitted through MustNewConstCounter.
+func (c *GaugeDesc) makeCounterCollectionTest(labeledValues ...LV) collectionTest {
+	return collectionTest{Name: c.Name, ExpectedResponse: c.expectedCollectionOfType("counter", labeledValues...)}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/asymmetric-research/solana-exporter/pkg/rpc"
+	"github.com/asymmetric-research/solana-exporter/pkg/slog"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"go.uber.org/zap"
+)
+
+const FiredancerMetricsPrefix = "solana_firedancer_"
+
+// FiredancerExportedMetrics lists the Firedancer metrics which are re-exported by the FiredancerCollector.
+var FiredancerExportedMetrics = []string{
+	"tile_status",
+	"tile_heartbeat",
+	"tile_in_backpressure",
+	"tile_backpressure_count",
+	"tile_regime_duration_nanos",
+	"tile_context_switch_involuntary_count",
+	"tile_context_switch_voluntary_count",
+}
+
+// FiredancerCollector re-exports a curated subset of the metrics served by a Firedancer node (see
+// FiredancerExportedMetrics), prefixed with FiredancerMetricsPrefix. As the exported metrics are only known once
+// scraped, it is an unchecked collector (i.e., it does not describe any metrics).
+type FiredancerCollector struct {
+	rpcClient *rpc.Client
+	logger    *zap.SugaredLogger
+	// metricsPrefix is FiredancerMetricsPrefix, with the configured -metric-prefix:
+	metricsPrefix string
+}
+
+func NewFiredancerCollector(rpcClient *rpc.Client, metricPrefix string) *FiredancerCollector {
+	return &FiredancerCollector{
+		rpcClient:     rpcClient,
+		logger:        slog.Get(),
+		metricsPrefix: rpc.PrefixedName(metricPrefix, FiredancerMetricsPrefix),
+	}
+}
+
+func (c *FiredancerCollector) Describe(_ chan<- *prometheus.Desc) {}
+
+// MetricNames returns the (prefixed) names of the re-exported metrics, which are only described once scraped.
+func (c *FiredancerCollector) MetricNames() []string {
+	names := make([]string, len(FiredancerExportedMetrics))
+	for i, name := range FiredancerExportedMetrics {
+		names[i] = c.metricsPrefix + name
+	}
+	return names
+}
+
+func (c *FiredancerCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.rpcClient.HttpTimeout)
+	defer cancel()
+
+	resp, err := c.rpcClient.GetFiredancerMetrics(ctx)
+	if err != nil {
+		// this is expected when the node is not running Firedancer:
+		c.logger.Debugf("failed to get Firedancer metrics: %v", err)
+		return
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		c.logger.Debugf("Firedancer metrics endpoint returned status %d", resp.StatusCode)
+		return
+	}
+
+	metrics, err := parseFiredancerMetrics(resp.Body, c.metricsPrefix)
+	if err != nil {
+		c.logger.Errorf("failed to parse Firedancer metrics: %v", err)
+		return
+	}
+	for _, metric := range metrics {
+		ch <- metric
+	}
+}
+
+// parseFiredancerMetrics parses Firedancer metrics in the Prometheus text exposition format, returning the
+// FiredancerExportedMetrics found as const metrics, prefixed with prefix.
+func parseFiredancerMetrics(r io.Reader, prefix string) ([]prometheus.Metric, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var metrics []prometheus.Metric
+	for _, name := range FiredancerExportedMetrics {
+		family, ok := families[name]
+		if !ok {
+			continue
+		}
+		var valueType prometheus.ValueType
+		switch family.GetType() {
+		case dto.MetricType_COUNTER:
+			valueType = prometheus.CounterValue
+		case dto.MetricType_GAUGE:
+			valueType = prometheus.GaugeValue
+		case dto.MetricType_UNTYPED:
+			valueType = prometheus.UntypedValue
+		default:
+			// histograms and summaries are not re-exported:
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			labelNames, labelValues := metricLabels(metric)
+			desc := prometheus.NewDesc(prefix+name, family.GetHelp(), labelNames, nil)
+			constMetric, err := prometheus.NewConstMetric(desc, valueType, metricValue(metric), labelValues...)
+			if err != nil {
+				return nil, fmt.Errorf("failed to re-export Firedancer metric %s: %w", name, err)
+			}
+			metrics = append(metrics, constMetric)
+		}
+	}
+	return metrics, nil
+}
+
+// metricLabels returns the label names and values of the provided metric, sorted by name.
+func metricLabels(metric *dto.Metric) ([]string, []string) {
+	labels := metric.GetLabel()
+	sort.Slice(labels, func(i, j int) bool { return labels[i].GetName() < labels[j].GetName() })
+	names, values := make([]string, len(labels)), make([]string, len(labels))
+	for i, label := range labels {
+		names[i], values[i] = label.GetName(), label.GetValue()
+	}
+	return names, values
+}
+
+// metricValue returns the value of the provided counter, gauge, or untyped metric.
+func metricValue(metric *dto.Metric) float64 {
+	switch {
+	case metric.Counter != nil:
+		return metric.GetCounter().GetValue()
+	case metric.Gauge != nil:
+		return metric.GetGauge().GetValue()
+	default:
+		return metric.GetUntyped().GetValue()
+	}
+}
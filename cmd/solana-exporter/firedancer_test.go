@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asymmetric-research/solana-exporter/pkg/rpc"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleFiredancerMetrics = `# HELP tile_status The current status of the tile. 0 is booting, 1 is running.
+# TYPE tile_status gauge
+tile_status{kind="net",kind_id="0"} 1
+tile_status{kind="quic",kind_id="0"} 1
+# HELP tile_regime_duration_nanos Mutually exclusive and exhaustive duration of time the tile spent in each of the regimes.
+# TYPE tile_regime_duration_nanos counter
+tile_regime_duration_nanos{kind="net",kind_id="0",tile_regime="caught_up_housekeeping"} 12345
+tile_regime_duration_nanos{kind="net",kind_id="0",tile_regime="processing_postfrag"} 678
+# HELP net_rx_pkt_cnt Packet receive count.
+# TYPE net_rx_pkt_cnt counter
+net_rx_pkt_cnt{kind="net",kind_id="0"} 42
+# HELP tile_backpressure_duration Time spent in backpressure.
+# TYPE tile_backpressure_duration histogram
+tile_backpressure_duration_bucket{kind="net",kind_id="0",le="1"} 1
+tile_backpressure_duration_bucket{kind="net",kind_id="0",le="+Inf"} 2
+tile_backpressure_duration_sum{kind="net",kind_id="0"} 3
+tile_backpressure_duration_count{kind="net",kind_id="0"} 2
+`
+
+const expectedFiredancerMetrics = `# HELP solana_firedancer_tile_regime_duration_nanos Mutually exclusive and exhaustive duration of time the tile spent in each of the regimes.
+# TYPE solana_firedancer_tile_regime_duration_nanos counter
+solana_firedancer_tile_regime_duration_nanos{kind="net",kind_id="0",tile_regime="caught_up_housekeeping"} 12345
+solana_firedancer_tile_regime_duration_nanos{kind="net",kind_id="0",tile_regime="processing_postfrag"} 678
+# HELP solana_firedancer_tile_status The current status of the tile. 0 is booting, 1 is running.
+# TYPE solana_firedancer_tile_status gauge
+solana_firedancer_tile_status{kind="net",kind_id="0"} 1
+solana_firedancer_tile_status{kind="quic",kind_id="0"} 1
+`
+
+func TestParseFiredancerMetrics(t *testing.T) {
+	metrics, err := parseFiredancerMetrics(strings.NewReader(sampleFiredancerMetrics), FiredancerMetricsPrefix)
+	assert.NoError(t, err)
+	// only the curated tile_status and tile_regime_duration_nanos metrics are re-exported:
+	assert.Len(t, metrics, 4)
+
+	_, err = parseFiredancerMetrics(strings.NewReader("not metrics{"), FiredancerMetricsPrefix)
+	assert.Error(t, err)
+}
+
+func TestFiredancerCollector(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			_, _ = w.Write([]byte(sampleFiredancerMetrics))
+		}
+	}))
+	defer server.Close()
+
+	client := rpc.NewRPCClient("", time.Second, server.Listener.Addr().(*net.TCPAddr).Port)
+	collector := NewFiredancerCollector(client, "")
+
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expectedFiredancerMetrics))
+	assert.NoError(t, err)
+
+	// non-Firedancer nodes are skipped silently:
+	status = http.StatusNotFound
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+}
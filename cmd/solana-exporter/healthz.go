@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// NewHealthzHandler returns a handler reporting whether the collector has completed a scrape without fatal rpc
+// failures within the staleness window, so that a wedged exporter can be detected by liveness probes.
+func NewHealthzHandler(collector *SolanaCollector, staleness time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastSuccess := collector.LastSuccessfulScrape()
+		if age := time.Since(lastSuccess); age > staleness {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = fmt.Fprintf(
+				w, "last successful scrape was %s ago (at %s), exceeding %s\n",
+				age.Round(time.Second), lastSuccess.UTC().Format(time.RFC3339), staleness,
+			)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintln(w, "ok")
+	})
+}
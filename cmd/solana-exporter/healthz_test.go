@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/asymmetric-research/solana-exporter/pkg/api"
+	"github.com/asymmetric-research/solana-exporter/pkg/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthzHandler(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false))
+	mockAPIClient := api.NewMockClient()
+	mockAPIClient.SetMinRequiredVersion("2.2.14", "0.503.20214")
+	mockAPIClient.SetNextEpochMinRequiredVersion("2.2.15", "0.503.20215")
+	collector.apiClient = mockAPIClient
+	handler := NewHealthzHandler(collector, time.Minute)
+
+	getStatus := func() int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		return recorder.Code
+	}
+
+	// freshly created collectors are given the staleness window to be scraped:
+	assert.Equal(t, http.StatusOK, getStatus())
+
+	// stale:
+	collector.lastSuccessfulScrape.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	assert.Equal(t, http.StatusServiceUnavailable, getStatus())
+
+	// a successful scrape makes it healthy again:
+	testutil.CollectAndCount(collector)
+	assert.Equal(t, http.StatusOK, getStatus())
+
+	// a scrape against an unreachable node does not:
+	collector.rpcClient = rpc.NewRPCClient("http://127.0.0.1:1", time.Second, 1)
+	collector.lastSuccessfulScrape.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	ch := make(chan prometheus.Metric)
+	go func() {
+		collector.Collect(ch)
+		close(ch)
+	}()
+	for range ch {
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, getStatus())
+}
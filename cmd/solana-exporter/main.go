@@ -3,11 +3,11 @@ package main
 import (
 	"context"
 	"net/http"
+	"os"
 
 	"github.com/asymmetric-research/solana-exporter/pkg/rpc"
 	"github.com/asymmetric-research/solana-exporter/pkg/slog"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
@@ -25,17 +25,81 @@ func main() {
 				"Prometheus metrics being created every epoch.",
 		)
 	}
+	if config.RpcInsecureSkipVerify {
+		logger.Warn(
+			"TLS certificate verification of the rpc is disabled (-rpc-insecure-skip-verify): the connection to the " +
+				"rpc is exposed to man-in-the-middle attacks. Never use this in production.",
+		)
+	}
 
-	rpcClient := rpc.NewRPCClient(config.RpcUrl, config.HttpTimeout, config.FiredancerMetricsPort)
+	rpc.SetupMetrics(config.MetricPrefix, config.RpcLatencyBuckets)
+	rpcClient := config.NewRPCClient()
 	collector := NewSolanaCollector(rpcClient, config)
+	var firedancer *FiredancerCollector
+	if config.ScrapeFiredancerMetrics {
+		firedancer = NewFiredancerCollector(rpcClient, config.MetricPrefix)
+	}
 	slotWatcher := NewSlotWatcher(rpcClient, config)
+	if config.ListMetrics {
+		others := append(rpc.Collectors(), slotWatcher.Collectors()...)
+		if err := ListMetrics(os.Stdout, collector, firedancer, others...); err != nil {
+			logger.Fatal(err)
+		}
+		return
+	}
+	if config.Once {
+		collectors := []prometheus.Collector{collector}
+		if firedancer != nil {
+			collectors = append(collectors, firedancer)
+		}
+		if err := ScrapeOnce(os.Stdout, collectors...); err != nil {
+			logger.Fatal(err)
+		}
+		return
+	}
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 	go slotWatcher.WatchSlots(ctx)
 
-	prometheus.MustRegister(collector)
-	http.Handle("/metrics", promhttp.Handler())
+	prometheus.MustRegister(rpc.Collectors()...)
+	if firedancer != nil {
+		prometheus.MustRegister(firedancer)
+	}
+	if config.PprofAddr != "" {
+		go func() {
+			logger.Infof("serving pprof on %s", config.PprofAddr)
+			logger.Errorf("pprof server stopped: %v", http.ListenAndServe(config.PprofAddr, NewPprofHandler()))
+		}()
+	}
+	if config.TextfileOutput != "" {
+		// the metrics are then not served over HTTP, so that the collector is only driven by WriteTextfiles:
+		logger.Infof("writing the metrics to %s every %s", config.TextfileOutput, config.TextfileInterval)
+		others := append(rpc.Collectors(), slotWatcher.Collectors()...)
+		if firedancer != nil {
+			others = append(others, firedancer)
+		}
+		WriteTextfiles(ctx, config.TextfileOutput, config.TextfileInterval, collector, others...)
+		return
+	}
+	// a dedicated mux is used as net/http/pprof registers its handlers on the default one:
+	mux := http.NewServeMux()
+	metricsHandler := NewMetricsHandler(collector)
+	if config.WebAuthUser != "" {
+		// the password is checked by Validate:
+		password, _ := config.LoadWebAuthPassword()
+		metricsHandler = NewBasicAuthHandler(metricsHandler, config.WebAuthUser, password)
+	}
+	mux.Handle("/metrics", metricsHandler)
+	mux.Handle("/healthz", NewHealthzHandler(collector, config.HealthStaleness))
 
+	// the TLS config is checked by Validate:
+	tlsConfig, _ := config.WebTLSConfig()
+	server := &http.Server{Addr: config.ListenAddress, Handler: mux, TLSConfig: tlsConfig}
+	if tlsConfig != nil {
+		logger.Infof("listening on %s (HTTPS)", config.ListenAddress)
+		// the certificate is already loaded in the TLS config:
+		logger.Fatal(server.ListenAndServeTLS("", ""))
+	}
 	logger.Infof("listening on %s", config.ListenAddress)
-	logger.Fatal(http.ListenAndServe(config.ListenAddress, nil))
+	logger.Fatal(server.ListenAndServe())
 }
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// scrapeTimeoutHeader is the header through which prometheus sends the timeout of each scrape.
+const scrapeTimeoutHeader = "X-Prometheus-Scrape-Timeout-Seconds"
+
+// scrapeCollector collects a SolanaCollector with the context of a single scrape.
+type scrapeCollector struct {
+	*SolanaCollector
+	ctx context.Context
+}
+
+func (c scrapeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(c.ctx, ch)
+}
+
+// NewMetricsHandler returns a handler serving the metrics of the default registry along with those of collector,
+// which is collected with the context of each request: the in-flight rpc and API calls of a scrape are aborted
+// when the request is cancelled, or once the scrape timeout sent by prometheus has elapsed.
+func NewMetricsHandler(collector *SolanaCollector) http.Handler {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if timeout, err := strconv.ParseFloat(r.Header.Get(scrapeTimeoutHeader), 64); err == nil && timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout*float64(time.Second)))
+			defer cancel()
+		}
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(scrapeCollector{SolanaCollector: collector, ctx: ctx})
+		gatherers := prometheus.Gatherers{prometheus.DefaultGatherer, registry}
+		promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	})
+	return promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, handler)
+}
+
+// NewBasicAuthHandler returns a handler which requires the HTTP basic authentication of user (with password) before
+// passing requests on to handler. The credentials are compared in constant time.
+func NewBasicAuthHandler(handler http.Handler, user, password string) http.Handler {
+	// the hashes are compared, so that the comparison does not leak the length of the credentials either:
+	userHash, passwordHash := sha256.Sum256([]byte(user)), sha256.Sum256([]byte(password))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestUser, requestPassword, ok := r.BasicAuth()
+		requestUserHash := sha256.Sum256([]byte(requestUser))
+		requestPasswordHash := sha256.Sum256([]byte(requestPassword))
+		userMatches := subtle.ConstantTimeCompare(userHash[:], requestUserHash[:]) == 1
+		passwordMatches := subtle.ConstantTimeCompare(passwordHash[:], requestPasswordHash[:]) == 1
+		if !ok || !userMatches || !passwordMatches {
+			w.Header().Set("WWW-Authenticate", `Basic realm="solana-exporter", charset="UTF-8"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/asymmetric-research/solana-exporter/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	simulator.Server.SetOpt(rpc.LatencyOpt, "getVoteAccounts", 5*time.Second)
+	config := newTestConfig(simulator, false)
+	config.DisableVersionCompliance = true
+	collector := NewSolanaCollector(client, config)
+	server := httptest.NewServer(NewMetricsHandler(collector))
+	defer server.Close()
+
+	request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+	request.Header.Set(scrapeTimeoutHeader, "0.5")
+	start := time.Now()
+	response, err := http.DefaultClient.Do(request)
+	assert.NoError(t, err)
+	//goland:noinspection GoUnhandledErrorResult
+	defer response.Body.Close()
+
+	// the slow rpc call is aborted once the scrape times out:
+	assert.Less(t, time.Since(start), 2*time.Second)
+	body, err := io.ReadAll(response.Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), "getVoteAccounts rpc call failed")
+	assert.Contains(t, string(body), context.DeadlineExceeded.Error())
+}
+
+func TestBasicAuthHandler(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "metrics")
+	})
+	server := httptest.NewServer(NewBasicAuthHandler(handler, "prometheus", "secret"))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		user     string
+		password string
+		expected int
+	}{
+		{name: "no credentials", expected: http.StatusUnauthorized},
+		{name: "wrong password", user: "prometheus", password: "guess", expected: http.StatusUnauthorized},
+		{name: "wrong user", user: "admin", password: "secret", expected: http.StatusUnauthorized},
+		{name: "valid credentials", user: "prometheus", password: "secret", expected: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			assert.NoError(t, err)
+			if tt.user != "" {
+				request.SetBasicAuth(tt.user, tt.password)
+			}
+			response, err := http.DefaultClient.Do(request)
+			assert.NoError(t, err)
+			//goland:noinspection GoUnhandledErrorResult
+			defer response.Body.Close()
+
+			assert.Equal(t, tt.expected, response.StatusCode)
+			if tt.expected == http.StatusUnauthorized {
+				assert.Contains(t, response.Header.Get("WWW-Authenticate"), "Basic")
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// ScrapeOnce gathers the metrics of the provided collectors once and writes them to w in the text exposition format,
+// so that the config and rpc connectivity can be validated (see -once) without serving anything. The metrics which
+// were collected are written even if others were invalid, in which case the collection errors are returned.
+func ScrapeOnce(w io.Writer, collectors ...prometheus.Collector) error {
+	registry := prometheus.NewRegistry()
+	for _, collector := range collectors {
+		if err := registry.Register(collector); err != nil {
+			return fmt.Errorf("failed to register collector: %w", err)
+		}
+	}
+	families, gatherErr := registry.Gather()
+	for _, family := range families {
+		if _, err := expfmt.MetricFamilyToText(w, family); err != nil {
+			return fmt.Errorf("failed to write %s: %w", family.GetName(), err)
+		}
+	}
+	if gatherErr != nil {
+		return fmt.Errorf("failed to collect all metrics: %w", gatherErr)
+	}
+	return nil
+}
+
+// descRegexp matches the string representation of a prometheus.Desc, as its name, help and labels are not exposed
+// otherwise:
+var descRegexp = regexp.MustCompile(
+	`^Desc\{fqName: ("(?:[^"\\]|\\.)*"), help: ("(?:[^"\\]|\\.)*"), constLabels: \{.*\}, variableLabels: \{(.*)\}\}$`,
+)
+
+// metricInfo is the name, labels and help of a metric, as listed by ListMetrics.
+type metricInfo struct {
+	name   string
+	labels []string
+	help   string
+}
+
+// describeMetrics returns the metricInfo of every metric described by collector.
+func describeMetrics(collector prometheus.Collector) ([]metricInfo, error) {
+	ch := make(chan *prometheus.Desc)
+	go func() {
+		collector.Describe(ch)
+		close(ch)
+	}()
+
+	var (
+		metrics []metricInfo
+		errs    []error
+	)
+	// the channel is drained even if a desc cannot be parsed, so that Describe returns:
+	for desc := range ch {
+		match := descRegexp.FindStringSubmatch(desc.String())
+		if match == nil {
+			errs = append(errs, fmt.Errorf("failed to parse %s", desc))
+			continue
+		}
+		name, nameErr := strconv.Unquote(match[1])
+		help, helpErr := strconv.Unquote(match[2])
+		if err := errors.Join(nameErr, helpErr); err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse %s: %w", desc, err))
+			continue
+		}
+		var labels []string
+		if match[3] != "" {
+			labels = strings.Split(match[3], ",")
+		}
+		metrics = append(metrics, metricInfo{name: name, labels: labels, help: help})
+	}
+	return metrics, errors.Join(errs...)
+}
+
+// ListMetrics writes the name, labels and help of every metric served by the exporter to w, sorted by name, so that
+// dashboards and alerts can be written without scraping a node first (see -list-metrics). These are the metrics of
+// the collector, whether they are enabled or not, those described by the other collectors (e.g., the slot watcher
+// and rpc metrics), and those re-exported by firedancer (unless it is nil), whose labels and help are the node's.
+func ListMetrics(
+	w io.Writer, collector *SolanaCollector, firedancer *FiredancerCollector, others ...prometheus.Collector,
+) error {
+	var metrics []metricInfo
+	for _, desc := range collector.descs() {
+		metrics = append(metrics, metricInfo{name: desc.Name, labels: desc.VariableLabels, help: desc.Help})
+	}
+	for _, other := range others {
+		described, err := describeMetrics(other)
+		if err != nil {
+			return err
+		}
+		metrics = append(metrics, described...)
+	}
+	if firedancer != nil {
+		for _, name := range firedancer.MetricNames() {
+			metrics = append(metrics, metricInfo{name: name, help: "Re-exported from the Firedancer metrics endpoint"})
+		}
+	}
+
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].name < metrics[j].name })
+	for _, metric := range metrics {
+		labels := strings.Join(metric.labels, ",")
+		if _, err := fmt.Fprintf(w, "%s{%s}\t%s\n", metric.name, labels, metric.help); err != nil {
+			return fmt.Errorf("failed to write %s: %w", metric.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/asymmetric-research/solana-exporter/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrapeOnce(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_node_is_healthy", "solana_node_transactions_total"}
+	collector := NewSolanaCollector(client, config)
+
+	var out bytes.Buffer
+	assert.NoError(t, ScrapeOnce(&out, collector))
+	assert.Contains(t, out.String(), "# TYPE solana_node_is_healthy gauge\nsolana_node_is_healthy 1\n")
+	assert.Contains(t, out.String(), "solana_node_transactions_total ")
+
+	// invalid metrics are reported, but the others are still written:
+	simulator.Server.SetOpt(
+		rpc.EasyErrorsOpt,
+		"getTransactionCount",
+		rpc.Error{Code: -32000, Method: "getTransactionCount", Message: "failed"},
+	)
+	out.Reset()
+	assert.Error(t, ScrapeOnce(&out, collector))
+	assert.Contains(t, out.String(), "solana_node_is_healthy 1\n")
+	assert.NotContains(t, out.String(), "solana_node_transactions_total ")
+}
+
+func TestListMetrics(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	// disabled metrics are listed too:
+	config.EnabledMetrics = []string{"solana_node_is_healthy"}
+	collector := NewSolanaCollector(client, config)
+	watcher := NewSlotWatcher(client, config)
+	firedancer := NewFiredancerCollector(client, "")
+
+	var out bytes.Buffer
+	others := append(rpc.Collectors(), watcher.Collectors()...)
+	assert.NoError(t, ListMetrics(&out, collector, firedancer, others...))
+	assert.Contains(t, out.String(), "solana_node_is_healthy{}\tWhether the node is healthy\n")
+	// the slot watcher, rpc and firedancer metrics are listed too:
+	assert.Contains(t, out.String(), "solana_node_slot_height{}\tThe current slot number\n")
+	assert.Contains(
+		t,
+		out.String(),
+		"solana_exporter_rpc_requests_total{method}\tTotal number of rpc requests made by the exporter, "+
+			"grouped by method\n",
+	)
+	assert.Contains(
+		t, out.String(), "solana_firedancer_tile_status{}\tRe-exported from the Firedancer metrics endpoint\n",
+	)
+	assert.Contains(
+		t,
+		out.String(),
+		"solana_validator_active_stake{votekey,nodekey,name}\tActive stake (in SOL) per validator "+
+			"(represented by votekey and nodekey)\n",
+	)
+	assert.Equal(t, 0, simulator.Server.CallCount("getVoteAccounts"))
+}
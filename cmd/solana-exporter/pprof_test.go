@@ -0,0 +1,31 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPprofHandler(t *testing.T) {
+	server := httptest.NewServer(NewPprofHandler())
+	defer server.Close()
+
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap?debug=1", "/debug/pprof/cmdline"} {
+		response, err := http.Get(server.URL + path)
+		assert.NoError(t, err)
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		_ = response.Body.Close()
+		assert.Equalf(t, http.StatusOK, response.StatusCode, "unexpected status for %s", path)
+		assert.NotEmptyf(t, body, "empty response for %s", path)
+	}
+
+	// nothing else is served:
+	response, err := http.Get(server.URL + "/metrics")
+	assert.NoError(t, err)
+	_ = response.Body.Close()
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
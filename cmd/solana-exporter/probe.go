@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asymmetric-research/solana-exporter/pkg/rpc"
+)
+
+const (
+	// probePollInterval is the interval at which the status of a probe transaction is polled:
+	probePollInterval = 100 * time.Millisecond
+	// probeTimeout is the time after which a probe transaction which has not been confirmed is given up on:
+	probeTimeout = 30 * time.Second
+	// systemTransferInstruction is the index of the transfer instruction of the system program:
+	systemTransferInstruction = 2
+	// rpcPortProbeTimeout is the time after which an advertised rpc port which has not answered is deemed unreachable:
+	rpcPortProbeTimeout = 2 * time.Second
+	// rpcPortProbeConcurrency is the maximum number of advertised rpc ports probed concurrently:
+	rpcPortProbeConcurrency = 16
+
+	// RpcPortProbeSelf and RpcPortProbeAll are the -probe-rpc-ports modes, which probe the rpc port of the node only,
+	// or of all the nodes in its gossip table:
+	RpcPortProbeSelf = "self"
+	RpcPortProbeAll  = "all"
+)
+
+// RpcPortProbeModes are the supported -probe-rpc-ports modes.
+var RpcPortProbeModes = []string{RpcPortProbeSelf, RpcPortProbeAll}
+
+// LoadKeypair reads the keypair file at the provided path, in the format of solana-keygen, i.e., a JSON array of the
+// 64 bytes of the private key (the 32 bytes of the seed, followed by the 32 bytes of the public key).
+func LoadKeypair(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keypair file: %w", err)
+	}
+	var key []byte
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("failed to decode keypair file %s: %w", path, err)
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid keypair file %s: %d bytes, expected %d", path, len(key), ed25519.PrivateKeySize)
+	}
+	privateKey := ed25519.NewKeyFromSeed(key[:ed25519.SeedSize])
+	if !slices.Equal(privateKey[ed25519.SeedSize:], key[ed25519.SeedSize:]) {
+		return nil, fmt.Errorf("invalid keypair file %s: the public key does not match the private key", path)
+	}
+	return privateKey, nil
+}
+
+// appendCompactU16 appends value to b in the compact-u16 encoding of the Solana wire format.
+func appendCompactU16(b []byte, value int) []byte {
+	for value >= 0x80 {
+		b = append(b, byte(value&0x7f)|0x80)
+		value >>= 7
+	}
+	return append(b, byte(value))
+}
+
+// newSelfTransfer builds a (legacy) transaction transferring 0 lamports from the account of key to itself, such that
+// only the fee is paid, and returns it in the wire format along with its (base58 encoded) signature.
+func newSelfTransfer(key ed25519.PrivateKey, blockhash []byte) ([]byte, string) {
+	payer := key.Public().(ed25519.PublicKey)
+	systemProgram := make([]byte, pubkeySize)
+
+	// header: 1 required signature (the payer), of which none is read-only, and 1 read-only unsigned account:
+	message := []byte{1, 0, 1}
+	message = appendCompactU16(message, 2)
+	message = append(message, payer...)
+	message = append(message, systemProgram...)
+	message = append(message, blockhash...)
+	// a single transfer instruction, from and to the payer (account 0), by the system program (account 1):
+	message = appendCompactU16(message, 1)
+	message = append(message, 1)
+	message = appendCompactU16(message, 2)
+	message = append(message, 0, 0)
+	data := binary.LittleEndian.AppendUint32(nil, systemTransferInstruction)
+	data = binary.LittleEndian.AppendUint64(data, 0)
+	message = appendCompactU16(message, len(data))
+	message = append(message, data...)
+
+	signature := ed25519.Sign(key, message)
+	transaction := appendCompactU16(nil, 1)
+	transaction = append(transaction, signature...)
+	transaction = append(transaction, message...)
+	return transaction, encodeBase58(signature)
+}
+
+// ProbeConfirmationLatency submits a self-transfer signed by key through the node, and returns the time it took for
+// the node to report the transaction as confirmed.
+func ProbeConfirmationLatency(ctx context.Context, client *rpc.Client, key ed25519.PrivateKey) (time.Duration, error) {
+	blockhash, err := client.GetLatestBlockhash(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		return 0, err
+	}
+	decodedBlockhash, err := decodeBase58(blockhash)
+	if err != nil || len(decodedBlockhash) != pubkeySize {
+		return 0, fmt.Errorf("invalid blockhash %q", blockhash)
+	}
+	transaction, signature := newSelfTransfer(key, decodedBlockhash)
+
+	start := time.Now()
+	if _, err := client.SendTransaction(ctx, rpc.CommitmentConfirmed, transaction); err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	ticker := time.NewTicker(probePollInterval)
+	defer ticker.Stop()
+	for {
+		statuses, err := client.GetSignatureStatuses(ctx, []string{signature})
+		if err != nil {
+			return 0, err
+		}
+		if len(statuses) > 0 && statuses[0] != nil {
+			status := statuses[0]
+			if status.Err != nil {
+				return 0, fmt.Errorf("probe transaction %s failed: %v", signature, status.Err)
+			}
+			if status.ConfirmationStatus == rpc.CommitmentConfirmed ||
+				status.ConfirmationStatus == rpc.CommitmentFinalized {
+				return time.Since(start), nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return 0, fmt.Errorf("probe transaction %s was not confirmed: %w", signature, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProbeRpcPort sends a getHealth request to the rpc port advertised in gossip at address (i.e., host:port), and returns
+// an error if it does not answer with a JSON-RPC response. An unhealthy node still counts as reachable, as its rpc
+// port is open.
+func ProbeRpcPort(ctx context.Context, client *http.Client, address string) error {
+	body := `{"jsonrpc":"2.0","id":1,"method":"getHealth"}`
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+address, strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("content-type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	var response struct {
+		Jsonrpc string `json:"jsonrpc"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if response.Jsonrpc != "2.0" {
+		return fmt.Errorf("not a JSON-RPC response")
+	}
+	return nil
+}
+
+// probeCache runs a probe in the background at most once per interval (as measured from the start of the latest
+// attempt, whatever its outcome), and caches its latest result or error, so that the probes (which can take up to
+// probeTimeout, or much longer for all the rpc ports of the gossip table) do not hold up the scrapes.
+type probeCache[T any] struct {
+	probe    func(context.Context) (T, error)
+	interval time.Duration
+
+	result T
+	err    error
+	// probedAt is the start time of the latest attempt, and done is closed once the first one has completed:
+	probedAt time.Time
+	probing  bool
+	done     chan struct{}
+	mu       sync.Mutex
+	// tracks the background probes, so that tests can wait for them:
+	probes sync.WaitGroup
+}
+
+func newProbeCache[T any](interval time.Duration, probe func(context.Context) (T, error)) *probeCache[T] {
+	return &probeCache[T]{probe: probe, interval: interval, done: make(chan struct{})}
+}
+
+// Get starts a probe in the background if the interval has elapsed since the latest attempt (and none is running),
+// and returns the result (or error) of the latest completed probe. As there is nothing to return before the first
+// probe completes, it is waited for as long as ctx allows, after which ok is false.
+func (p *probeCache[T]) Get(ctx context.Context) (result T, ok bool, err error) {
+	p.mu.Lock()
+	if !p.probing && (p.probedAt.IsZero() || time.Since(p.probedAt) >= p.interval) {
+		p.probing, p.probedAt = true, time.Now()
+		p.probes.Add(1)
+		// the probe outlives ctx (i.e., the scrape), and is bounded by its own timeouts instead:
+		go p.run(context.WithoutCancel(ctx))
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+	default:
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+			return result, false, nil
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.result, true, p.err
+}
+
+func (p *probeCache[T]) run(ctx context.Context) {
+	defer p.probes.Done()
+	result, err := p.probe(ctx)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.result, p.err, p.probing = result, err, false
+	select {
+	case <-p.done:
+	default:
+		close(p.done)
+	}
+}
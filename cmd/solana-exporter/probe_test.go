@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/asymmetric-research/solana-exporter/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+const testBlockhash = "EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N"
+
+// writeKeypair writes the provided key to a keypair file in the format of solana-keygen, and returns its path.
+func writeKeypair(t *testing.T, key []byte) string {
+	t.Helper()
+	values := make([]int, len(key))
+	for i, b := range key {
+		values[i] = int(b)
+	}
+	data, err := json.Marshal(values)
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "keypair.json")
+	assert.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func newTestKeypair(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	return key
+}
+
+func TestLoadKeypair(t *testing.T) {
+	key := newTestKeypair(t)
+	loaded, err := LoadKeypair(writeKeypair(t, key))
+	assert.NoError(t, err)
+	assert.Equal(t, key, loaded)
+
+	// too short:
+	_, err = LoadKeypair(writeKeypair(t, key[:ed25519.SeedSize]))
+	assert.Error(t, err)
+	// the public key does not match:
+	mismatched := append(append([]byte{}, key[:ed25519.SeedSize]...), newTestKeypair(t)[ed25519.SeedSize:]...)
+	_, err = LoadKeypair(writeKeypair(t, mismatched))
+	assert.Error(t, err)
+	// missing:
+	_, err = LoadKeypair(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestNewSelfTransfer(t *testing.T) {
+	key := newTestKeypair(t)
+	blockhash, err := decodeBase58(testBlockhash)
+	assert.NoError(t, err)
+	transaction, signature := newSelfTransfer(key, blockhash)
+
+	// a single signature, over the message:
+	assert.Equal(t, byte(1), transaction[0])
+	rawSignature, message := transaction[1:1+ed25519.SignatureSize], transaction[1+ed25519.SignatureSize:]
+	assert.Equal(t, encodeBase58(rawSignature), signature)
+	payer := key.Public().(ed25519.PublicKey)
+	assert.True(t, ed25519.Verify(payer, message, rawSignature))
+
+	// header, account keys (the payer and the system program) and blockhash:
+	assert.Equal(t, []byte{1, 0, 1, 2}, message[:4])
+	assert.Equal(t, []byte(payer), message[4:36])
+	assert.Equal(t, make([]byte, 32), message[36:68])
+	assert.Equal(t, blockhash, message[68:100])
+	// the transfer instruction, of 0 lamports from and to the payer:
+	instruction := message[100:]
+	assert.Equal(t, []byte{1, 1, 2, 0, 0, 12}, instruction[:6])
+	assert.Equal(t, uint32(systemTransferInstruction), binary.LittleEndian.Uint32(instruction[6:10]))
+	assert.Equal(t, uint64(0), binary.LittleEndian.Uint64(instruction[10:18]))
+	assert.Len(t, instruction, 18)
+}
+
+func TestProbeConfirmationLatency(t *testing.T) {
+	key := newTestKeypair(t)
+	server, client := rpc.NewMockClient(t,
+		map[string]any{
+			"getLatestBlockhash": map[string]any{
+				"context": map[string]int{"slot": 10},
+				"value":   map[string]any{"blockhash": testBlockhash, "lastValidBlockHeight": 160},
+			},
+			"sendTransaction": "signature",
+			"getSignatureStatuses": map[string]any{
+				"context": map[string]int{"slot": 10},
+				"value":   []any{nil},
+			},
+		},
+		nil, nil, nil, nil, nil,
+	)
+	setStatus := func(status map[string]any) {
+		server.SetOpt(
+			rpc.EasyResultsOpt,
+			"getSignatureStatuses",
+			map[string]any{"context": map[string]int{"slot": 12}, "value": []any{status}},
+		)
+	}
+
+	t.Run("confirmed", func(t *testing.T) {
+		delay := 300 * time.Millisecond
+		go func() {
+			time.Sleep(delay)
+			setStatus(map[string]any{"slot": 11, "confirmations": 1, "err": nil, "confirmationStatus": "confirmed"})
+		}()
+		latency, err := ProbeConfirmationLatency(context.Background(), client, key)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, latency, delay)
+		assert.Less(t, latency, delay+time.Second)
+
+		// the submitted transaction is signed by the keypair:
+		params := server.LastParams("sendTransaction")
+		transaction, err := base64.StdEncoding.DecodeString(params[0].(string))
+		assert.NoError(t, err)
+		rawSignature, message := transaction[1:1+ed25519.SignatureSize], transaction[1+ed25519.SignatureSize:]
+		assert.True(t, ed25519.Verify(key.Public().(ed25519.PublicKey), message, rawSignature))
+		assert.Equal(t, []any{encodeBase58(rawSignature)}, server.LastParams("getSignatureStatuses")[0])
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		setStatus(map[string]any{
+			"slot": 11, "confirmations": 1, "err": map[string]any{"InstructionError": []any{0, "Custom"}},
+			"confirmationStatus": "confirmed",
+		})
+		_, err := ProbeConfirmationLatency(context.Background(), client, key)
+		assert.Error(t, err)
+	})
+}
+
+func TestProbeCache(t *testing.T) {
+	var (
+		probes  int
+		release = make(chan struct{})
+	)
+	cache := newProbeCache(time.Hour, func(context.Context) (int, error) {
+		<-release
+		probes++
+		return probes, nil
+	})
+
+	// nothing is returned before the first probe completes, once ctx is done:
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, ok, err := cache.Get(ctx)
+	assert.False(t, ok)
+	assert.NoError(t, err)
+
+	// the first probe is still running, so no other one is started:
+	close(release)
+	result, ok, err := cache.Get(context.Background())
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result)
+	cache.probes.Wait()
+	assert.Equal(t, 1, probes)
+
+	// the result is served from the cache until the interval has elapsed since the latest attempt:
+	cache.mu.Lock()
+	cache.probedAt = time.Now().Add(-time.Hour)
+	cache.mu.Unlock()
+	_, _, _ = cache.Get(context.Background())
+	cache.probes.Wait()
+	result, _, _ = cache.Get(context.Background())
+	assert.Equal(t, 2, result)
+}
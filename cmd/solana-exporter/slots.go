@@ -30,12 +30,13 @@ type SlotWatcher struct {
 	slotWatermark int64
 
 	leaderSchedule map[string][]int64
+	// epochStakes are the activated stakes (in lamports) of the tracked votekeys at the start of the current epoch:
+	epochStakes map[string]int64
 
 	// for tracking which metrics we have and deleting them accordingly:
 	nodekeyTracker *EpochTrackedValidators
 
 	// prometheus:
-	TotalTransactionsMetric   prometheus.Gauge
 	SlotHeightMetric          prometheus.Gauge
 	EpochNumberMetric         prometheus.Gauge
 	EpochFirstSlotMetric      prometheus.Gauge
@@ -47,6 +48,9 @@ type SlotWatcher struct {
 	FeeRewardsMetric          *prometheus.CounterVec
 	BlockSizeMetric           *prometheus.GaugeVec
 	BlockHeightMetric         prometheus.Gauge
+	TrackedEpochsMetric       prometheus.Gauge
+	StakeChangeMetric         *prometheus.GaugeVec
+	LeaderScheduleEpochMetric prometheus.Gauge
 }
 
 func NewSlotWatcher(client *rpc.Client, config *ExporterConfig) *SlotWatcher {
@@ -57,31 +61,25 @@ func NewSlotWatcher(client *rpc.Client, config *ExporterConfig) *SlotWatcher {
 		config:         config,
 		nodekeyTracker: NewEpochTrackedValidators(),
 		// metrics:
-		TotalTransactionsMetric: prometheus.NewGauge(prometheus.GaugeOpts{
-			// even though this isn't a counter, it is supposed to act as one,
-			// and so we name it with the _total suffix
-			Name: "solana_node_transactions_total",
-			Help: "Total number of transactions processed without error since genesis.",
-		}),
 		SlotHeightMetric: prometheus.NewGauge(prometheus.GaugeOpts{
-			Name: "solana_node_slot_height",
+			Name: rpc.PrefixedName(config.MetricPrefix, "solana_node_slot_height"),
 			Help: "The current slot number",
 		}),
 		EpochNumberMetric: prometheus.NewGauge(prometheus.GaugeOpts{
-			Name: "solana_node_epoch_number",
+			Name: rpc.PrefixedName(config.MetricPrefix, "solana_node_epoch_number"),
 			Help: "The current epoch number.",
 		}),
 		EpochFirstSlotMetric: prometheus.NewGauge(prometheus.GaugeOpts{
-			Name: "solana_node_epoch_first_slot",
+			Name: rpc.PrefixedName(config.MetricPrefix, "solana_node_epoch_first_slot"),
 			Help: "Current epoch's first slot [inclusive].",
 		}),
 		EpochLastSlotMetric: prometheus.NewGauge(prometheus.GaugeOpts{
-			Name: "solana_node_epoch_last_slot",
+			Name: rpc.PrefixedName(config.MetricPrefix, "solana_node_epoch_last_slot"),
 			Help: "Current epoch's last slot [inclusive].",
 		}),
 		LeaderSlotsMetric: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
-				Name: "solana_validator_leader_slots_total",
+				Name: rpc.PrefixedName(config.MetricPrefix, "solana_validator_leader_slots_total"),
 				Help: fmt.Sprintf(
 					"Number of slots processed, grouped by %s, and %s ('%s' or '%s')",
 					NodekeyLabel, SkipStatusLabel, StatusValid, StatusSkipped,
@@ -91,7 +89,7 @@ func NewSlotWatcher(client *rpc.Client, config *ExporterConfig) *SlotWatcher {
 		),
 		LeaderSlotsByEpochMetric: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
-				Name: "solana_validator_leader_slots_by_epoch_total",
+				Name: rpc.PrefixedName(config.MetricPrefix, "solana_validator_leader_slots_by_epoch_total"),
 				Help: fmt.Sprintf(
 					"Number of slots processed, grouped by %s, %s ('%s' or '%s'), and %s",
 					NodekeyLabel, SkipStatusLabel, StatusValid, StatusSkipped, EpochLabel,
@@ -101,7 +99,7 @@ func NewSlotWatcher(client *rpc.Client, config *ExporterConfig) *SlotWatcher {
 		),
 		ClusterSlotsByEpochMetric: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
-				Name: "solana_cluster_slots_by_epoch_total",
+				Name: rpc.PrefixedName(config.MetricPrefix, "solana_cluster_slots_by_epoch_total"),
 				Help: fmt.Sprintf(
 					"Number of slots processed by the cluster, grouped by %s ('%s' or '%s'), and %s",
 					SkipStatusLabel, StatusValid, StatusSkipped, EpochLabel,
@@ -111,46 +109,51 @@ func NewSlotWatcher(client *rpc.Client, config *ExporterConfig) *SlotWatcher {
 		),
 		InflationRewardsMetric: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
-				Name: "solana_validator_inflation_rewards_total",
+				Name: rpc.PrefixedName(config.MetricPrefix, "solana_validator_inflation_rewards_total"),
 				Help: fmt.Sprintf("Inflation reward earned, grouped by %s and %s", VotekeyLabel, EpochLabel),
 			},
 			[]string{VotekeyLabel, EpochLabel},
 		),
 		FeeRewardsMetric: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
-				Name: "solana_validator_fee_rewards_total",
+				Name: rpc.PrefixedName(config.MetricPrefix, "solana_validator_fee_rewards_total"),
 				Help: fmt.Sprintf("Transaction fee rewards earned, grouped by %s and %s", NodekeyLabel, EpochLabel),
 			},
 			[]string{NodekeyLabel, EpochLabel},
 		),
 		BlockSizeMetric: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
-				Name: "solana_validator_block_size",
+				Name: rpc.PrefixedName(config.MetricPrefix, "solana_validator_block_size"),
 				Help: fmt.Sprintf("Number of transactions per block, grouped by %s", NodekeyLabel),
 			},
 			[]string{NodekeyLabel, TransactionTypeLabel},
 		),
 		BlockHeightMetric: prometheus.NewGauge(prometheus.GaugeOpts{
-			Name: "solana_node_block_height",
+			Name: rpc.PrefixedName(config.MetricPrefix, "solana_node_block_height"),
 			Help: "The current block height of the node",
 		}),
+		TrackedEpochsMetric: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: rpc.PrefixedName(config.MetricPrefix, "solana_exporter_tracked_epochs"),
+			Help: "Number of epochs whose per-epoch metrics are still tracked (see -epoch-cleanup-time)",
+		}),
+		StakeChangeMetric: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: rpc.PrefixedName(config.MetricPrefix, "solana_validator_stake_change_epoch"),
+				Help: fmt.Sprintf(
+					"Change in activated stake (in SOL) at the start of the current epoch, grouped by %s",
+					VotekeyLabel,
+				),
+			},
+			[]string{VotekeyLabel},
+		),
+		LeaderScheduleEpochMetric: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: rpc.PrefixedName(config.MetricPrefix, "solana_exporter_leader_schedule_cache_epoch"),
+			Help: "Epoch of the cached leader schedule, which is only fetched once per epoch",
+		}),
 	}
 	// register
 	logger.Info("Registering slot watcher metrics:")
-	for _, collector := range []prometheus.Collector{
-		watcher.TotalTransactionsMetric,
-		watcher.SlotHeightMetric,
-		watcher.EpochNumberMetric,
-		watcher.EpochFirstSlotMetric,
-		watcher.EpochLastSlotMetric,
-		watcher.LeaderSlotsMetric,
-		watcher.LeaderSlotsByEpochMetric,
-		watcher.ClusterSlotsByEpochMetric,
-		watcher.InflationRewardsMetric,
-		watcher.FeeRewardsMetric,
-		watcher.BlockSizeMetric,
-		watcher.BlockHeightMetric,
-	} {
+	for _, collector := range watcher.Collectors() {
 		if err := prometheus.Register(collector); err != nil {
 			var (
 				alreadyRegisteredErr *prometheus.AlreadyRegisteredError
@@ -166,6 +169,26 @@ func NewSlotWatcher(client *rpc.Client, config *ExporterConfig) *SlotWatcher {
 	return &watcher
 }
 
+// Collectors returns the metrics of the slot watcher.
+func (c *SlotWatcher) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.SlotHeightMetric,
+		c.EpochNumberMetric,
+		c.EpochFirstSlotMetric,
+		c.EpochLastSlotMetric,
+		c.LeaderSlotsMetric,
+		c.LeaderSlotsByEpochMetric,
+		c.ClusterSlotsByEpochMetric,
+		c.InflationRewardsMetric,
+		c.FeeRewardsMetric,
+		c.BlockSizeMetric,
+		c.BlockHeightMetric,
+		c.TrackedEpochsMetric,
+		c.StakeChangeMetric,
+		c.LeaderScheduleEpochMetric,
+	}
+}
+
 func (c *SlotWatcher) WatchSlots(ctx context.Context) {
 	ticker := time.NewTicker(c.config.SlotPace)
 	defer ticker.Stop()
@@ -180,7 +203,7 @@ func (c *SlotWatcher) WatchSlots(ctx context.Context) {
 		default:
 			<-ticker.C
 			// TODO: separate fee-rewards watching from general slot watching, such that general slot watching commitment level can be dropped to confirmed
-			commitment := rpc.CommitmentFinalized
+			commitment := c.config.SlotWatcherCommitment()
 			epochInfo, err := c.client.GetEpochInfo(ctx, commitment)
 			if err != nil {
 				c.logger.Errorf("Failed to get epoch info, bailing out: %v", err)
@@ -193,7 +216,6 @@ func (c *SlotWatcher) WatchSlots(ctx context.Context) {
 			}
 
 			c.logger.Infof("Current slot: %v", epochInfo.AbsoluteSlot)
-			c.TotalTransactionsMetric.Set(float64(epochInfo.TransactionCount))
 			c.SlotHeightMetric.Set(float64(epochInfo.AbsoluteSlot))
 			c.BlockHeightMetric.Set(float64(epochInfo.BlockHeight))
 
@@ -259,13 +281,28 @@ func (c *SlotWatcher) trackEpoch(ctx context.Context, epoch *rpc.EpochInfo) {
 	c.EpochFirstSlotMetric.Set(float64(c.firstSlot))
 	c.EpochLastSlotMetric.Set(float64(c.lastSlot))
 
-	// update leader schedule:
+	// update leader schedule, which is stable within an epoch and so only fetched once per epoch, here:
 	c.logger.Infof("Updating leader schedule for epoch %v ...", c.currentEpoch)
-	leaderSchedule, err := GetTrimmedLeaderSchedule(ctx, c.client, c.config.NodeKeys, epoch.AbsoluteSlot, c.firstSlot)
+	leaderSchedule, err := GetTrimmedLeaderSchedule(
+		ctx,
+		c.client,
+		c.config.Commitment("", rpc.CommitmentConfirmed),
+		c.config.NodeKeys,
+		epoch.AbsoluteSlot,
+		c.firstSlot,
+	)
 	if err != nil {
 		c.logger.Errorf("Failed to get trimmed leader schedule, bailing out: %v", err)
+	} else {
+		c.LeaderScheduleEpochMetric.Set(float64(c.currentEpoch))
 	}
 	c.leaderSchedule = leaderSchedule
+
+	if len(c.config.VoteKeys) > 0 {
+		if err := c.fetchAndEmitStakeChanges(ctx); err != nil {
+			c.logger.Errorf("Failed to emit stake changes, bailing out: %v", err)
+		}
+	}
 }
 
 // cleanEpoch deletes old epoch-labelled metrics which are no longer being updated due to an epoch change.
@@ -293,6 +330,7 @@ func (c *SlotWatcher) cleanEpoch(ctx context.Context, epoch int64) {
 	if err != nil {
 		c.logger.Errorf("Failed to get tracked validators, bailing out: %v", err)
 	}
+	c.TrackedEpochsMetric.Set(float64(c.nodekeyTracker.Len()))
 	for _, status := range []string{StatusValid, StatusSkipped} {
 		c.deleteMetricLabelValues(c.ClusterSlotsByEpochMetric, "cluster-slots-by-epoch", epochStr, status)
 		for _, nodekey := range trackedNodekeys {
@@ -345,8 +383,8 @@ func (c *SlotWatcher) moveSlotWatermark(ctx context.Context, to int64) {
 // fetchAndEmitBlockProduction fetches block production from startSlot up to the provided endSlot [inclusive],
 // and emits the prometheus metrics,
 func (c *SlotWatcher) fetchAndEmitBlockProduction(ctx context.Context, startSlot, endSlot int64) {
-	if c.config.LightMode {
-		c.logger.Debug("Skipping block-production fetching in light mode.")
+	if !c.config.CollectorEnabled(CollectorBlockProduction) {
+		c.logger.Debug("Skipping block-production fetching, as its collector is disabled.")
 		return
 	}
 	c.logger.Debugf("Fetching block production in [%v -> %v]", startSlot, endSlot)
@@ -357,7 +395,7 @@ func (c *SlotWatcher) fetchAndEmitBlockProduction(ctx context.Context, startSlot
 	}
 
 	// fetch block production:
-	blockProduction, err := c.client.GetBlockProduction(ctx, rpc.CommitmentFinalized, startSlot, endSlot)
+	blockProduction, err := c.client.GetBlockProduction(ctx, c.config.Commitment("", rpc.CommitmentFinalized), startSlot, endSlot)
 	if err != nil {
 		c.logger.Errorf("Failed to get block production, bailing out: %v", err)
 		return
@@ -388,6 +426,7 @@ func (c *SlotWatcher) fetchAndEmitBlockProduction(ctx context.Context, startSlot
 
 	// update tracked nodekeys:
 	c.nodekeyTracker.AddTrackedNodekeys(c.currentEpoch, nodekeys)
+	c.TrackedEpochsMetric.Set(float64(c.nodekeyTracker.Len()))
 
 	c.logger.Debugf("Fetched block production in [%v -> %v]", startSlot, endSlot)
 }
@@ -395,8 +434,8 @@ func (c *SlotWatcher) fetchAndEmitBlockProduction(ctx context.Context, startSlot
 // fetchAndEmitBlockInfos fetches and emits all the fee rewards (+ block sizes) for the tracked addresses between the
 // startSlot and endSlot [inclusive]
 func (c *SlotWatcher) fetchAndEmitBlockInfos(ctx context.Context, startSlot, endSlot int64) {
-	if c.config.LightMode {
-		c.logger.Debug("Skipping block-infos fetching in light mode.")
+	if !c.config.CollectorEnabled(CollectorBlockInfos) {
+		c.logger.Debug("Skipping block-infos fetching, as its collector is disabled.")
 		return
 	}
 	c.logger.Debugf("Fetching fee rewards in [%v -> %v]", startSlot, endSlot)
@@ -422,6 +461,16 @@ func (c *SlotWatcher) fetchAndEmitBlockInfos(ctx context.Context, startSlot, end
 	c.logger.Debugf("Fetched fee rewards in [%v -> %v]", startSlot, endSlot)
 }
 
+// blockCommitment returns the commitment level used to fetch blocks and inflation rewards, which cannot be fetched
+// at the processed commitment level, so it is raised to confirmed.
+func (c *SlotWatcher) blockCommitment() rpc.Commitment {
+	commitment := c.config.Commitment("", rpc.CommitmentConfirmed)
+	if commitment == rpc.CommitmentProcessed {
+		return rpc.CommitmentConfirmed
+	}
+	return commitment
+}
+
 // fetchAndEmitSingleBlockInfo fetches and emits the fee reward + block size for a single block.
 func (c *SlotWatcher) fetchAndEmitSingleBlockInfo(
 	ctx context.Context, nodekey string, epoch int64, slot int64,
@@ -430,15 +479,11 @@ func (c *SlotWatcher) fetchAndEmitSingleBlockInfo(
 	if c.config.MonitorBlockSizes {
 		transactionDetails = "full"
 	}
-	block, err := c.client.GetBlock(ctx, rpc.CommitmentConfirmed, slot, transactionDetails)
+	block, err := c.client.GetBlock(ctx, c.blockCommitment(), slot, transactionDetails)
 	if err != nil {
-		var rpcError *rpc.Error
-		if errors.As(err, &rpcError) {
-			// this is the error code for slot was skipped:
-			if rpcError.Code == rpc.SlotSkippedCode && strings.Contains(rpcError.Message, "skipped") {
-				c.logger.Infof("slot %v was skipped, no fee rewards.", slot)
-				return nil
-			}
+		if rpc.IsSkippedSlotError(err) {
+			c.logger.Debugf("slot %v was skipped, no fee rewards.", slot)
+			return nil
 		}
 		return err
 	}
@@ -480,12 +525,12 @@ func (c *SlotWatcher) fetchAndEmitSingleBlockInfo(
 // fetchAndEmitInflationRewards fetches and emits the inflation rewards for the configured inflationRewardAddresses
 // at the provided epoch
 func (c *SlotWatcher) fetchAndEmitInflationRewards(ctx context.Context, epoch int64) error {
-	if c.config.LightMode {
-		c.logger.Debug("Skipping inflation-rewards fetching in light mode.")
+	if !c.config.CollectorEnabled(CollectorInflationRewards) {
+		c.logger.Debug("Skipping inflation-rewards fetching, as its collector is disabled.")
 		return nil
 	}
 	c.logger.Infof("Fetching inflation reward for epoch %v ...", toString(epoch))
-	rewardInfos, err := c.client.GetInflationReward(ctx, rpc.CommitmentConfirmed, c.config.VoteKeys, epoch)
+	rewardInfos, err := c.client.GetInflationReward(ctx, c.blockCommitment(), c.config.VoteKeys, epoch)
 	if err != nil {
 		return fmt.Errorf("error fetching inflation rewards: %w", err)
 	}
@@ -499,6 +544,31 @@ func (c *SlotWatcher) fetchAndEmitInflationRewards(ctx context.Context, epoch in
 	return nil
 }
 
+// fetchAndEmitStakeChanges snapshots the activated stakes of the tracked votekeys at the start of the current epoch,
+// and emits how much they changed since the start of the previous one. Nothing is emitted for the first epoch tracked.
+func (c *SlotWatcher) fetchAndEmitStakeChanges(ctx context.Context) error {
+	voteAccounts, err := c.client.GetVoteAccounts(ctx, c.config.Commitment("", rpc.CommitmentConfirmed), "")
+	if err != nil {
+		return err
+	}
+	stakes := make(map[string]int64)
+	for _, account := range append(voteAccounts.Current, voteAccounts.Delinquent...) {
+		if slices.Contains(c.config.VoteKeys, account.VotePubkey) {
+			stakes[account.VotePubkey] = account.ActivatedStake
+		}
+	}
+
+	if c.epochStakes != nil {
+		for _, votekey := range c.config.VoteKeys {
+			// a votekey missing from either snapshot has no stake:
+			change := float64(stakes[votekey]-c.epochStakes[votekey]) / rpc.LamportsInSol
+			c.StakeChangeMetric.WithLabelValues(votekey).Set(change)
+		}
+	}
+	c.epochStakes = stakes
+	return nil
+}
+
 func (c *SlotWatcher) deleteMetricLabelValues(metric *prometheus.CounterVec, name string, lvs ...string) {
 	c.logger.Debugf("deleting %v with lv %v", name, lvs)
 	if ok := metric.DeleteLabelValues(lvs...); !ok {
@@ -12,22 +12,20 @@ import (
 )
 
 type slotMetricValues struct {
-	SlotHeight        float64
-	TotalTransactions float64
-	EpochNumber       float64
-	EpochFirstSlot    float64
-	EpochLastSlot     float64
-	BlockHeight       float64
+	SlotHeight     float64
+	EpochNumber    float64
+	EpochFirstSlot float64
+	EpochLastSlot  float64
+	BlockHeight    float64
 }
 
 func getSlotMetricValues(watcher *SlotWatcher) slotMetricValues {
 	return slotMetricValues{
-		SlotHeight:        testutil.ToFloat64(watcher.SlotHeightMetric),
-		TotalTransactions: testutil.ToFloat64(watcher.TotalTransactionsMetric),
-		EpochNumber:       testutil.ToFloat64(watcher.EpochNumberMetric),
-		EpochFirstSlot:    testutil.ToFloat64(watcher.EpochFirstSlotMetric),
-		EpochLastSlot:     testutil.ToFloat64(watcher.EpochLastSlotMetric),
-		BlockHeight:       testutil.ToFloat64(watcher.BlockHeightMetric),
+		SlotHeight:     testutil.ToFloat64(watcher.SlotHeightMetric),
+		EpochNumber:    testutil.ToFloat64(watcher.EpochNumberMetric),
+		EpochFirstSlot: testutil.ToFloat64(watcher.EpochFirstSlotMetric),
+		EpochLastSlot:  testutil.ToFloat64(watcher.EpochLastSlotMetric),
+		BlockHeight:    testutil.ToFloat64(watcher.BlockHeightMetric),
 	}
 }
 
@@ -39,12 +37,6 @@ func assertSlotMetricsChangeCorrectly(t *testing.T, initial slotMetricValues, fi
 		"Slot has not increased! (%v -> %v)",
 		initial.SlotHeight, final.SlotHeight,
 	)
-	assert.Greaterf(t,
-		final.TotalTransactions,
-		initial.TotalTransactions,
-		"Total transactions have not increased! (%v -> %v)",
-		initial.TotalTransactions, final.TotalTransactions,
-	)
 	assert.GreaterOrEqualf(t,
 		final.EpochNumber,
 		initial.EpochNumber,
@@ -101,7 +93,6 @@ func TestSlotWatcher_WatchSlots_Static(t *testing.T) {
 	firstSlot, lastSlot := GetEpochBounds(epochInfo)
 	tests := []testCase{
 		{"slot_height", float64(epochInfo.AbsoluteSlot), watcher.SlotHeightMetric},
-		{"total_transactions", float64(epochInfo.TransactionCount), watcher.TotalTransactionsMetric},
 		{"epoch_number", float64(epochInfo.Epoch), watcher.EpochNumberMetric},
 		{"epoch_first_slot", float64(firstSlot), watcher.EpochFirstSlotMetric},
 		{"epoch_last_slot", float64(lastSlot), watcher.EpochLastSlotMetric},
@@ -167,12 +158,6 @@ func TestSlotWatcher_WatchSlots_Dynamic(t *testing.T) {
 			"Exporter slot (%v) ahead of simulator slot (%v)!",
 			int(final.SlotHeight), simulator.Slot,
 		)
-		assert.LessOrEqualf(t,
-			int(final.TotalTransactions),
-			simulator.TransactionCount,
-			"Exporter transaction count (%v) ahead of simulator transaction count (%v)!",
-			int(final.TotalTransactions), simulator.TransactionCount,
-		)
 		assert.LessOrEqualf(t,
 			int(final.EpochNumber),
 			simulator.Epoch,
@@ -321,4 +306,111 @@ func TestSlotWatcher_cleanUpEpoch(t *testing.T) {
 	for _, counter := range counters {
 		assert.Equal(t, expected, testutil.ToFloat64(counter))
 	}
+	// and the old epoch is no longer tracked:
+	_, err := watcher.nodekeyTracker.GetTrackedValidators(int64(currentEpoch - 1))
+	assert.Error(t, err)
+	assert.Equal(t, float64(watcher.nodekeyTracker.Len()), testutil.ToFloat64(watcher.TrackedEpochsMetric))
+}
+
+func TestSlotWatcher_StakeChange(t *testing.T) {
+	simulator, client := NewSimulator(t, 23)
+	config := newTestConfig(simulator, true)
+	watcher := NewSlotWatcher(client, config)
+	watcher.StakeChangeMetric.Reset()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.WatchSlots(ctx)
+	time.Sleep(time.Second)
+	// nothing is emitted for the first epoch:
+	assert.Equal(t, 0, testutil.CollectAndCount(watcher.StakeChangeMetric))
+
+	// change the stakes before the next epoch starts:
+	for nodekey, stake := range map[string]int{"aaa": 501_000_000, "bbb": 0} {
+		info := simulator.Server.GetValidatorInfo(nodekey)
+		info.Stake = stake
+		simulator.Server.SetOpt(rpc.ValidatorInfoOpt, nodekey, info)
+	}
+	go simulator.Run(ctx)
+	// the changes are emitted once the next epoch is tracked:
+	assert.Eventually(
+		t,
+		func() bool { return testutil.CollectAndCount(watcher.StakeChangeMetric) == 3 },
+		5*time.Second,
+		10*time.Millisecond,
+	)
+
+	for votekey, change := range map[string]float64{"AAA": 0.5, "BBB": -0.001, "CCC": 0} {
+		assert.Equal(t, change, testutil.ToFloat64(watcher.StakeChangeMetric.WithLabelValues(votekey)), votekey)
+	}
+}
+
+func TestSlotWatcher_DefaultCommitment(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, true)
+	config.DefaultCommitment = rpc.CommitmentProcessed
+	watcher := NewSlotWatcher(client, config)
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	go watcher.WatchSlots(watchCtx)
+	time.Sleep(time.Second)
+	cancel()
+
+	ctx := context.Background()
+	watcher.fetchAndEmitBlockProduction(ctx, 24, 30)
+	assert.NoError(t, watcher.fetchAndEmitSingleBlockInfo(ctx, simulator.Nodekeys[0], 1, 24))
+	assert.NoError(t, watcher.fetchAndEmitInflationRewards(ctx, 1))
+
+	for _, method := range []string{"getEpochInfo", "getLeaderSchedule", "getBlockProduction"} {
+		assert.Equal(t, string(rpc.CommitmentProcessed), lastCommitment(simulator, method), method)
+	}
+	// blocks and inflation rewards cannot be fetched at the processed commitment level:
+	for _, method := range []string{"getBlock", "getInflationReward"} {
+		assert.Equal(t, string(rpc.CommitmentConfirmed), lastCommitment(simulator, method), method)
+	}
+}
+
+func TestSlotWatcher_LeaderScheduleCache(t *testing.T) {
+	simulator, client := NewSimulator(t, 25)
+	watcher := NewSlotWatcher(client, newTestConfig(simulator, true))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.WatchSlots(ctx)
+
+	// move through several slots of the same epoch:
+	for _, slot := range []int{28, 31, 34} {
+		for simulator.Slot < slot {
+			simulator.Slot++
+			simulator.PopulateSlot(simulator.Slot)
+		}
+		assert.Eventually(
+			t,
+			func() bool { return testutil.ToFloat64(watcher.SlotHeightMetric) == float64(slot) },
+			5*time.Second,
+			10*time.Millisecond,
+		)
+	}
+
+	assert.Equal(t, 1, simulator.Server.CallCount("getLeaderSchedule"))
+	assert.Equal(t, float64(1), testutil.ToFloat64(watcher.LeaderScheduleEpochMetric))
+}
+
+func TestSlotWatcher_DisabledCollectors(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, true)
+	config.DisabledCollectors = []string{CollectorBlockProduction, CollectorBlockInfos, CollectorInflationRewards}
+	watcher := NewSlotWatcher(client, config)
+	watchCtx, cancel := context.WithCancel(context.Background())
+	go watcher.WatchSlots(watchCtx)
+	time.Sleep(time.Second)
+	cancel()
+
+	ctx := context.Background()
+	watcher.fetchAndEmitBlockProduction(ctx, 24, 30)
+	watcher.fetchAndEmitBlockInfos(ctx, 24, 30)
+	assert.NoError(t, watcher.fetchAndEmitInflationRewards(ctx, 1))
+	for _, method := range []string{"getBlockProduction", "getBlock", "getInflationReward"} {
+		assert.Equal(t, 0, simulator.Server.CallCount(method), method)
+	}
 }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/asymmetric-research/solana-exporter/pkg/slog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// WriteTextfile gathers the metrics of gatherer and writes them to path in the text exposition format, through a
+// temporary file which is then renamed, so that node_exporter's textfile collector never reads a partial file. As with
+// ScrapeOnce, the metrics which were collected are written even if others were invalid.
+func WriteTextfile(path string, gatherer prometheus.Gatherer) error {
+	families, gatherErr := gatherer.Gather()
+
+	// the temporary file is created next to path, as a rename is only atomic within a filesystem:
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer os.Remove(file.Name())
+	for _, family := range families {
+		if _, err := expfmt.MetricFamilyToText(file, family); err != nil {
+			_ = file.Close()
+			return fmt.Errorf("failed to write %s: %w", family.GetName(), err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file.Name(), err)
+	}
+	// temporary files are only readable by their owner, whereas node_exporter may run as another user:
+	if err := os.Chmod(file.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to chmod %s: %w", file.Name(), err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to rename %s: %w", file.Name(), err)
+	}
+	if gatherErr != nil {
+		return fmt.Errorf("failed to collect all metrics: %w", gatherErr)
+	}
+	return nil
+}
+
+// WriteTextfiles writes the metrics of collector along with those of the others (e.g., the slot watcher and rpc
+// metrics) to path (see -textfile-output) every interval, until ctx is cancelled. Each collection is bounded by the
+// interval, just as a scrape is bounded by the scrape timeout of prometheus. As the metrics are not served over HTTP
+// meanwhile, this is the only caller of collector.CollectContext, whose state (e.g., the counters) is thus not
+// updated by concurrent scrapes. The default registry is not written, so that the go_, process_ and promhttp_ metrics
+// of the exporter do not end up among those of node_exporter.
+func WriteTextfiles(
+	ctx context.Context,
+	path string,
+	interval time.Duration,
+	collector *SolanaCollector,
+	others ...prometheus.Collector,
+) {
+	logger := slog.Get()
+	static := prometheus.NewRegistry()
+	static.MustRegister(others...)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		collectCtx, cancel := context.WithTimeout(ctx, interval)
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(scrapeCollector{SolanaCollector: collector, ctx: collectCtx})
+		// the collector is gathered first, so that the rpc metrics include the requests of this collection:
+		err := WriteTextfile(path, prometheus.Gatherers{registry, static})
+		cancel()
+		if err != nil {
+			logger.Errorf("failed to write the metrics to %s: %v", path, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asymmetric-research/solana-exporter/pkg/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteTextfiles(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.EnabledMetrics = []string{"solana_node_is_healthy", "solana_node_transactions_total"}
+	collector := NewSolanaCollector(client, config)
+	watcher := NewSlotWatcher(client, config)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "solana.prom")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		WriteTextfiles(ctx, path, time.Minute, collector, append(rpc.Collectors(), watcher.Collectors()...)...)
+	}()
+	// the file is written right away, rather than after the first interval:
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	out, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(out))
+	assert.NoError(t, err)
+	assert.Contains(t, families, "solana_node_is_healthy")
+	assert.Contains(t, families, "solana_node_transactions_total")
+	// the slot watcher and rpc metrics are written too, but not those of the default registry:
+	assert.Contains(t, families, "solana_node_slot_height")
+	assert.Contains(t, families, "solana_exporter_rpc_requests_total")
+	for name := range families {
+		assert.False(t, strings.HasPrefix(name, "go_") || strings.HasPrefix(name, "process_"), name)
+	}
+
+	// node_exporter may run as another user, and no temporary file is left behind:
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestWriteTextfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "solana.prom")
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_gauge"})
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(gauge)
+
+	gauge.Set(1)
+	assert.NoError(t, WriteTextfile(path, registry))
+	// an existing file is replaced:
+	gauge.Set(2)
+	assert.NoError(t, WriteTextfile(path, registry))
+	out, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "# HELP test_gauge \n# TYPE test_gauge gauge\ntest_gauge 2\n", string(out))
+}
@@ -1,17 +1,27 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/asymmetric-research/solana-exporter/pkg/rpc"
 	"github.com/asymmetric-research/solana-exporter/pkg/slog"
+	"math/big"
 	"slices"
+	"strconv"
+	"strings"
 	"sync"
 )
 
-const VoteProgram = "Vote111111111111111111111111111111111111111"
+const (
+	VoteProgram = "Vote111111111111111111111111111111111111111"
+
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	// pubkeySize is the size (in bytes) of a decoded Solana pubkey
+	pubkeySize = 32
+)
 
 type EpochTrackedValidators struct {
 	trackedNodekeys map[int64]map[string]struct{}
@@ -55,6 +65,13 @@ func (c *EpochTrackedValidators) AddTrackedNodekeys(epoch int64, nodekeys []stri
 	c.trackedNodekeys[epoch] = epochNodekeys
 }
 
+// Len returns the number of epochs which are tracked, i.e., which have not been cleaned up yet.
+func (c *EpochTrackedValidators) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.trackedNodekeys)
+}
+
 func assertf(condition bool, format string, args ...any) {
 	logger := slog.Get()
 	if !condition {
@@ -86,10 +103,14 @@ func SelectFromSchedule(schedule map[string][]int64, startSlot, endSlot int64) m
 // GetTrimmedLeaderSchedule fetches the leader schedule, but only for the validators we are interested in.
 // Additionally, it adjusts the leader schedule to the current epoch offset.
 func GetTrimmedLeaderSchedule(
-	ctx context.Context, client *rpc.Client, identities []string, slot, epochFirstSlot int64,
+	ctx context.Context,
+	client *rpc.Client,
+	commitment rpc.Commitment,
+	identities []string,
+	slot, epochFirstSlot int64,
 ) (map[string][]int64, error) {
 	logger := slog.Get()
-	leaderSchedule, err := client.GetLeaderSchedule(ctx, rpc.CommitmentConfirmed, slot)
+	leaderSchedule, err := client.GetLeaderSchedule(ctx, commitment, slot)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get leader schedule: %w", err)
 	}
@@ -115,7 +136,7 @@ func GetTrimmedLeaderSchedule(
 func GetAssociatedVoteAccounts(
 	ctx context.Context, client *rpc.Client, commitment rpc.Commitment, nodekeys []string,
 ) ([]string, error) {
-	voteAccounts, err := client.GetVoteAccounts(ctx, commitment)
+	voteAccounts, err := client.GetVoteAccounts(ctx, commitment, "")
 	if err != nil {
 		return nil, err
 	}
@@ -137,19 +158,64 @@ func GetAssociatedVoteAccounts(
 	return votekeys, nil
 }
 
-// FetchBalances fetches SOL balances for a list of addresses
-func FetchBalances(ctx context.Context, client *rpc.Client, addresses []string) (map[string]float64, error) {
-	balances := make(map[string]float64)
-	for _, address := range addresses {
-		balance, err := client.GetBalance(ctx, rpc.CommitmentConfirmed, address)
-		if err != nil {
-			return nil, err
-		}
-		balances[address] = balance
+// FetchBalances fetches SOL balances for a list of addresses. With a concurrency of 1, they are all fetched in a single
+// batch request if the rpc supports it. Otherwise, the addresses are split into chunks of rpc.MaxMultipleAccounts,
+// which are fetched with up to concurrency requests in flight at once, and the errors of all the chunks are joined.
+func FetchBalances(
+	ctx context.Context, client *rpc.Client, commitment rpc.Commitment, addresses []string, concurrency int,
+) (map[string]float64, error) {
+	if concurrency <= 1 || len(addresses) <= rpc.MaxMultipleAccounts {
+		return client.GetBalances(ctx, commitment, addresses)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		errs     []error
+		balances = make(map[string]float64, len(addresses))
+		slots    = make(chan struct{}, concurrency)
+	)
+	for start := 0; start < len(addresses); start += rpc.MaxMultipleAccounts {
+		chunk := addresses[start:min(start+rpc.MaxMultipleAccounts, len(addresses))]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			chunkBalances, err := client.GetBalances(ctx, commitment, chunk)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			for address, balance := range chunkBalances {
+				balances[address] = balance
+			}
+		}()
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
 	}
 	return balances, nil
 }
 
+// EstimateSecondsRemaining estimates the time (in seconds) for slotsRemaining slots to pass, at the average slot
+// time of the provided performance samples. The estimate is clamped to zero, e.g., right at an epoch boundary.
+func EstimateSecondsRemaining(slotsRemaining int64, samples []rpc.PerformanceSample) (float64, error) {
+	var slots, seconds int64
+	for _, sample := range samples {
+		slots += sample.NumSlots
+		seconds += sample.SamplePeriodSecs
+	}
+	if slots == 0 {
+		return 0, fmt.Errorf("no slots in %d performance samples", len(samples))
+	}
+	return max(0, float64(slotsRemaining)*float64(seconds)/float64(slots)), nil
+}
+
 // CombineUnique combines unique items from multiple arrays to a single array.
 func CombineUnique[T comparable](args ...[]T) []T {
 	var uniqueItems []T
@@ -188,6 +254,117 @@ func CountVoteTransactions(block *rpc.Block) (int, error) {
 	return voteCount, nil
 }
 
+// GetSuperminority returns the votekeys of the superminority, i.e., the smallest set of the highest staked validators
+// which together hold more than a third of the total active stake.
+func GetSuperminority(accounts []rpc.VoteAccount) map[string]struct{} {
+	sorted := slices.Clone(accounts)
+	slices.SortFunc(sorted, func(a, b rpc.VoteAccount) int {
+		if a.ActivatedStake != b.ActivatedStake {
+			return cmp.Compare(b.ActivatedStake, a.ActivatedStake)
+		}
+		return strings.Compare(a.VotePubkey, b.VotePubkey)
+	})
+
+	var totalStake int64
+	for _, account := range sorted {
+		totalStake += account.ActivatedStake
+	}
+
+	superminority := make(map[string]struct{})
+	var cumulativeStake int64
+	for _, account := range sorted {
+		// (compare in lamports to avoid rounding errors right on the threshold)
+		if 3*cumulativeStake > totalStake {
+			break
+		}
+		superminority[account.VotePubkey] = struct{}{}
+		cumulativeStake += account.ActivatedStake
+	}
+	return superminority
+}
+
+// GetCreditsRanks returns the rank of each votekey by the credits earned in the latest epoch, in descending order.
+// Vote accounts with the same credits share the same rank.
+func GetCreditsRanks(accounts []rpc.VoteAccount) map[string]int {
+	sorted := slices.Clone(accounts)
+	slices.SortFunc(sorted, func(a, b rpc.VoteAccount) int {
+		return cmp.Compare(b.LatestEpochCredits(), a.LatestEpochCredits())
+	})
+
+	ranks := make(map[string]int)
+	for i, account := range sorted {
+		if i > 0 && account.LatestEpochCredits() == sorted[i-1].LatestEpochCredits() {
+			ranks[account.VotePubkey] = ranks[sorted[i-1].VotePubkey]
+			continue
+		}
+		ranks[account.VotePubkey] = i + 1
+	}
+	return ranks
+}
+
+// GetMajorityFeatureSet returns the feature set advertised by the most nodes (the highest one on a tie), or 0 if none
+// of the nodes advertise one.
+func GetMajorityFeatureSet(nodes []rpc.ContactInfo) int64 {
+	counts := make(map[int64]int)
+	for _, node := range nodes {
+		if node.FeatureSet != nil {
+			counts[*node.FeatureSet]++
+		}
+	}
+	var majority int64
+	for featureSet, count := range counts {
+		if count > counts[majority] || count == counts[majority] && featureSet > majority {
+			majority = featureSet
+		}
+	}
+	return majority
+}
+
+// decodeBase58 decodes the provided base58 string, as used for Solana addresses.
+func decodeBase58(s string) ([]byte, error) {
+	n, radix := new(big.Int), big.NewInt(58)
+	for _, r := range s {
+		digit := strings.IndexRune(base58Alphabet, r)
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", r)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(digit)))
+	}
+	// every leading '1' encodes a leading zero byte:
+	zeros := len(s) - len(strings.TrimLeft(s, "1"))
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+// encodeBase58 encodes the provided bytes in base58, as used for Solana addresses and signatures.
+func encodeBase58(b []byte) string {
+	n, radix, digit := new(big.Int).SetBytes(b), big.NewInt(58), new(big.Int)
+	var encoded []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, digit)
+		encoded = append(encoded, base58Alphabet[digit.Int64()])
+	}
+	// every leading zero byte is encoded as a leading '1':
+	for i := 0; i < len(b) && b[i] == 0; i++ {
+		encoded = append(encoded, '1')
+	}
+	slices.Reverse(encoded)
+	return string(encoded)
+}
+
+// ValidatePubkey checks that the provided address is a syntactically valid Solana pubkey, i.e., 32 bytes encoded
+// in base58.
+func ValidatePubkey(address string) error {
+	decoded, err := decodeBase58(address)
+	if err != nil {
+		return fmt.Errorf("invalid pubkey %q: %w", address, err)
+	}
+	if len(decoded) != pubkeySize {
+		return fmt.Errorf("invalid pubkey %q: decodes to %d bytes, expected %d", address, len(decoded), pubkeySize)
+	}
+	return nil
+}
+
 // BoolToFloat64 converts a boolean to either 1.0 or 0.0
 func BoolToFloat64(b bool) float64 {
 	if b {
@@ -251,3 +428,32 @@ func ExtractHealthAndNumSlotsBehind(health string, getHealthErr error) (
 	return true, nil, 0, nil
 
 }
+
+// parseVersionToNumber encodes a major.minor.patch version as major*1e10 + minor*1e5 + patch, such that versions can
+// be compared numerically (e.g., in PromQL). A leading 'v' and any pre-release or build suffix are ignored.
+func parseVersionToNumber(version string) (float64, error) {
+	trimmed := strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(trimmed, "-+ "); i >= 0 {
+		trimmed = trimmed[:i]
+	}
+	parts := strings.Split(trimmed, ".")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("version %s has more than 3 parts", version)
+	}
+
+	var number float64
+	for i := 0; i < 3; i++ {
+		var part int64
+		if i < len(parts) {
+			var err error
+			if part, err = strconv.ParseInt(parts[i], 10, 64); err != nil {
+				return 0, fmt.Errorf("invalid version %s: %w", version, err)
+			}
+			if part < 0 || part >= 1e5 {
+				return 0, fmt.Errorf("invalid version %s: part %d out of range", version, part)
+			}
+		}
+		number = number*1e5 + float64(part)
+	}
+	return number, nil
+}
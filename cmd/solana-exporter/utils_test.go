@@ -4,10 +4,13 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"fmt"
 	"github.com/asymmetric-research/solana-exporter/pkg/rpc"
 	"github.com/stretchr/testify/assert"
 	"sort"
+	"strconv"
 	"testing"
+	"time"
 )
 
 func TestSelectFromSchedule(t *testing.T) {
@@ -40,7 +43,7 @@ func TestGetTrimmedLeaderSchedule(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	schedule, err := GetTrimmedLeaderSchedule(ctx, client, []string{"aaa", "bbb"}, 10, 10)
+	schedule, err := GetTrimmedLeaderSchedule(ctx, client, rpc.CommitmentConfirmed, []string{"aaa", "bbb"}, 10, 10)
 	assert.NoError(t, err)
 	assert.Equal(t, map[string][]int64{"aaa": {10, 13, 16, 19, 22}, "bbb": {11, 14, 17, 20, 23}}, schedule)
 }
@@ -64,7 +67,9 @@ func TestFetchBalances(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	fetchedBalances, err := FetchBalances(ctx, client, CombineUnique(simulator.Nodekeys, simulator.Votekeys))
+	fetchedBalances, err := FetchBalances(
+		ctx, client, rpc.CommitmentConfirmed, CombineUnique(simulator.Nodekeys, simulator.Votekeys), 1,
+	)
 	assert.NoError(t, err)
 	assert.Equal(t,
 		map[string]float64{"aaa": 1, "bbb": 2, "ccc": 3, "AAA": 4, "BBB": 5, "CCC": 6},
@@ -72,6 +77,59 @@ func TestFetchBalances(t *testing.T) {
 	)
 }
 
+// balanceAddresses returns the nodekeys and votekeys of the simulator, followed by count addresses without a balance.
+func balanceAddresses(simulator *Simulator, count int) []string {
+	addresses := CombineUnique(simulator.Nodekeys, simulator.Votekeys)
+	for i := range count {
+		addresses = append(addresses, fmt.Sprintf("address%d", i))
+	}
+	return addresses
+}
+
+func TestFetchBalances_Concurrency(t *testing.T) {
+	simulator, client := NewSimulator(t, 0)
+	addresses := balanceAddresses(simulator, 3*rpc.MaxMultipleAccounts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fetchedBalances, err := FetchBalances(ctx, client, rpc.CommitmentConfirmed, addresses, 3)
+	assert.NoError(t, err)
+	assert.Len(t, fetchedBalances, len(addresses))
+	assert.Equal(t, 6.0, fetchedBalances["CCC"])
+	assert.Equal(t, 0.0, fetchedBalances["address0"])
+	// each chunk is fetched in its own request:
+	assert.Equal(t, 4, simulator.Server.CallCount("getMultipleAccounts"))
+
+	// the errors of the failed chunks are returned:
+	simulator.Server.SetOpt(
+		rpc.EasyErrorsOpt, "getMultipleAccounts", rpc.Error{Code: -32000, Message: "failed"},
+	)
+	_, err = FetchBalances(ctx, client, rpc.CommitmentConfirmed, addresses, 3)
+	assert.ErrorContains(t, err, "failed")
+}
+
+// BenchmarkFetchBalances fetches the balances of 500 addresses from an rpc which does not support batch requests and
+// takes 10ms per request, at increasing concurrency.
+func BenchmarkFetchBalances(b *testing.B) {
+	for _, concurrency := range []int{1, 5} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			simulator, client := NewSimulator(b, 0)
+			simulator.Server.SetOpt(rpc.BatchesDisabledOpt, nil, true)
+			simulator.Server.SetOpt(rpc.LatencyOpt, "getMultipleAccounts", 10*time.Millisecond)
+			addresses := balanceAddresses(simulator, 5*rpc.MaxMultipleAccounts)
+
+			b.ResetTimer()
+			for range b.N {
+				_, err := FetchBalances(context.Background(), client, rpc.CommitmentConfirmed, addresses, concurrency)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 func TestGetAssociatedVoteAccounts(t *testing.T) {
 	simulator, client := NewSimulator(t, 1)
 
@@ -153,6 +211,120 @@ func TestEpochTrackedValidators_AddTrackedValidators(t *testing.T) {
 	)
 }
 
+func TestEstimateSecondsRemaining(t *testing.T) {
+	samples := []rpc.PerformanceSample{
+		{Slot: 300, NumSlots: 150, SamplePeriodSecs: 60},
+		{Slot: 150, NumSlots: 150, SamplePeriodSecs: 60},
+	}
+	// 300 slots in 120s is 0.4s per slot:
+	seconds, err := EstimateSecondsRemaining(1000, samples)
+	assert.NoError(t, err)
+	assert.Equal(t, 400.0, seconds)
+
+	// past the boundary, the estimate is clamped to zero:
+	seconds, err = EstimateSecondsRemaining(-10, samples)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, seconds)
+
+	_, err = EstimateSecondsRemaining(1000, nil)
+	assert.Error(t, err)
+}
+
+func TestGetSuperminority(t *testing.T) {
+	tests := []struct {
+		name     string
+		stakes   []int64
+		expected []string
+	}{
+		{name: "single dominant validator", stakes: []int64{50, 20, 15, 10, 5}, expected: []string{"0"}},
+		{name: "two validators", stakes: []int64{30, 30, 20, 20}, expected: []string{"0", "1"}},
+		{name: "skewed distribution", stakes: []int64{20, 20, 10, 10, 10, 10, 10, 10}, expected: []string{"0", "1"}},
+		{name: "equal stakes", stakes: []int64{10, 10, 10, 10, 10, 10}, expected: []string{"0", "1", "2"}},
+		{name: "exactly a third", stakes: []int64{10, 10, 10}, expected: []string{"0", "1"}},
+		{name: "no accounts", stakes: nil, expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var accounts []rpc.VoteAccount
+			// add them in reverse order to check that they get sorted:
+			for i := len(tt.stakes) - 1; i >= 0; i-- {
+				accounts = append(accounts, rpc.VoteAccount{VotePubkey: strconv.Itoa(i), ActivatedStake: tt.stakes[i]})
+			}
+			var superminority []string
+			for votekey := range GetSuperminority(accounts) {
+				superminority = append(superminority, votekey)
+			}
+			assert.ElementsMatch(t, tt.expected, superminority)
+		})
+	}
+}
+
+func TestGetCreditsRanks(t *testing.T) {
+	accounts := []rpc.VoteAccount{
+		{VotePubkey: "AAA", EpochCredits: [][3]int64{{1, 100, 0}, {2, 250, 100}}},
+		{VotePubkey: "BBB", EpochCredits: [][3]int64{{2, 400, 200}}},
+		{VotePubkey: "CCC", EpochCredits: [][3]int64{{2, 150, 0}}},
+		{VotePubkey: "DDD"},
+	}
+	// AAA and CCC earned the same credits in the latest epoch, so they share a rank:
+	assert.Equal(t, map[string]int{"AAA": 2, "BBB": 1, "CCC": 2, "DDD": 4}, GetCreditsRanks(accounts))
+}
+
+func TestGetMajorityFeatureSet(t *testing.T) {
+	featureSets := func(featureSets ...int64) []rpc.ContactInfo {
+		nodes := make([]rpc.ContactInfo, len(featureSets))
+		for i := range featureSets {
+			// a 0 stands for a node which does not advertise a feature set:
+			if featureSets[i] != 0 {
+				nodes[i].FeatureSet = &featureSets[i]
+			}
+		}
+		return nodes
+	}
+	assert.Equal(t, int64(2), GetMajorityFeatureSet(featureSets(1, 2, 2, 0, 0, 0)))
+	assert.Equal(t, int64(2), GetMajorityFeatureSet(featureSets(1, 2)))
+	assert.Equal(t, int64(0), GetMajorityFeatureSet(featureSets(0, 0)))
+}
+
+func TestValidatePubkey(t *testing.T) {
+	tests := []struct {
+		name    string
+		pubkey  string
+		wantErr bool
+	}{
+		{"valid", "Certusm1sa411sMpV9FPqU5dXAYhmmhygvxJ23S6hJ24", false},
+		{"leading zeros", "11111111111111111111111111111111", false},
+		{"program", "Stake11111111111111111111111111111111111111", false},
+		{"invalid character", "Certusm1sa411sMpV9FPqU5dXAYhmmhygvxJ23S6hJ2O", true},
+		{"too short", "Certusm1sa411sMpV9FPqU5dXAYhmmhygvxJ23S6h", true},
+		{"too long", "Certusm1sa411sMpV9FPqU5dXAYhmmhygvxJ23S6hJ24Certus", true},
+		{"placeholder", "<VALIDATOR_IDENTITY_1>", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePubkey(tt.pubkey)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEncodeBase58(t *testing.T) {
+	for _, address := range []string{
+		"Certusm1sa411sMpV9FPqU5dXAYhmmhygvxJ23S6hJ24",
+		"11111111111111111111111111111111",
+		"Stake11111111111111111111111111111111111111",
+	} {
+		decoded, err := decodeBase58(address)
+		assert.NoError(t, err)
+		assert.Equal(t, address, encodeBase58(decoded))
+	}
+	assert.Equal(t, "", encodeBase58(nil))
+}
+
 func TestBoolToFloat64(t *testing.T) {
 	assert.Equal(t, float64(1), BoolToFloat64(true))
 	assert.Equal(t, float64(0), BoolToFloat64(false))
@@ -191,3 +363,36 @@ func TestExtractHealthAndNumSlotsBehind(t *testing.T) {
 		})
 	})
 }
+
+func TestParseVersionToNumber(t *testing.T) {
+	tests := []struct {
+		version string
+		want    float64
+		wantErr bool
+	}{
+		{version: "2.2.14", want: 2_00002_00014},
+		{version: "v1.18.23", want: 1_00018_00023},
+		{version: "0.503.20214", want: 503_20214},
+		{version: "2.3.0-beta", want: 2_00003_00000},
+		{version: "2.3", want: 2_00003_00000},
+		{version: "1.2.3.4", wantErr: true},
+		{version: "0.1.100000", wantErr: true},
+		{version: "x.y.z", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := parseVersionToNumber(tt.version)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// the encoding preserves the ordering of versions:
+	older, _ := parseVersionToNumber("2.2.14")
+	newer, _ := parseVersionToNumber("2.10.0")
+	assert.Less(t, older, newer)
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/asymmetric-research/solana-exporter/pkg/rpc"
+	"github.com/asymmetric-research/solana-exporter/pkg/slog"
 )
 
 const (
@@ -17,171 +18,269 @@ const (
 
 	// SolanaEpochStatsAPI is the base URL for the Solana validators epoch stats API
 	SolanaEpochStatsAPI = "https://api.solana.org/api/epoch/required_versions"
+
+	// MaxRetries is the number of times a failed request to the API is retried
+	MaxRetries = 2
+
+	// RetryBackoff is the delay before the first retry, which is doubled on every subsequent retry
+	RetryBackoff = time.Second
+
+	// RefreshTimeout bounds the background refreshes of stale values, which are not bound by a scrape
+	RefreshTimeout = 30 * time.Second
 )
 
+// cachedRequiredVersion is a RequiredVersionInfo along with the time at which it was fetched.
+type cachedRequiredVersion struct {
+	info      RequiredVersionInfo
+	fetchedAt time.Time
+	// whether the (stale) value is being refreshed in the background:
+	refreshing bool
+}
+
 type Client struct {
 	HttpClient http.Client
 	baseURL    string
 	rpcClient  *rpc.Client
 	cache      struct {
-		agaveVersion          string
-		firedancerVersion     string
-		nextAgaveVersion      string
-		nextFiredancerVersion string
-		lastCheck             time.Time
-		epoch                 int
-		nextEpoch             int
+		current cachedRequiredVersion
+		next    cachedRequiredVersion
 	}
+	// whether the last request to the API succeeded:
+	up bool
 	mu sync.RWMutex
 	// How often to refresh the cache
 	cacheTimeout time.Duration
+	// How often, and after how long, to retry failed requests
+	maxRetries   int
+	retryBackoff time.Duration
+	// tracks the background refreshes, so that tests can wait for them
+	refreshes sync.WaitGroup
 }
 
-func NewClient(rpcClient *rpc.Client) *Client {
+// NewClient creates a client for the required versions API at baseURL (e.g., SolanaEpochStatsAPI).
+func NewClient(rpcClient *rpc.Client, baseURL string) *Client {
 	return &Client{
 		HttpClient:   http.Client{},
 		cacheTimeout: CacheTimeout,
-		baseURL:      SolanaEpochStatsAPI,
+		baseURL:      baseURL,
 		rpcClient:    rpcClient,
+		maxRetries:   MaxRetries,
+		retryBackoff: RetryBackoff,
 	}
 }
 
-func (c *Client) GetMinRequiredVersion(ctx context.Context, cluster string) (string, string, int, string, error) {
-	// Check cache first
+// Up returns whether the last request to the API succeeded.
+func (c *Client) Up() bool {
 	c.mu.RLock()
-	if !c.cache.lastCheck.IsZero() && time.Since(c.cache.lastCheck) < c.cacheTimeout {
-		version := c.cache.agaveVersion
-		firedancerVersion := c.cache.firedancerVersion
-		epoch := c.cache.epoch
-		c.mu.RUnlock()
-		return version, cluster, epoch, firedancerVersion, nil
-	}
-	c.mu.RUnlock()
-
-	// Make API request
-	url := fmt.Sprintf("%s?cluster=%s", c.baseURL, cluster)
+	defer c.mu.RUnlock()
+	return c.up
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return "", cluster, 0, "", fmt.Errorf("failed to create request: %w", err)
+// CacheAge returns how long ago the current epoch's required versions were fetched, or false if they never were.
+func (c *Client) CacheAge() (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.cache.current.fetchedAt.IsZero() {
+		return 0, false
 	}
+	return time.Since(c.cache.current.fetchedAt), true
+}
 
-	resp, err := c.HttpClient.Do(req)
-	if err != nil {
-		return "", cluster, 0, "", fmt.Errorf("failed to fetch min required version: %w", err)
-	}
-	defer resp.Body.Close()
+// GetMinRequiredVersion returns the required versions for the current epoch. Once fetched, a stale value is returned
+// right away while it is refreshed in the background, so it is still returned if the API cannot be reached.
+func (c *Client) GetMinRequiredVersion(ctx context.Context, cluster string) (*RequiredVersionInfo, error) {
+	return c.getRequiredVersion(ctx, cluster, &c.cache.current, c.fetchMinRequiredVersion)
+}
 
-	var stats ValidatorEpochStats
-	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
-		return "", cluster, 0, "", fmt.Errorf("failed to decode response: %w", err)
+// GetNextEpochMinRequiredVersion returns the required versions for the next epoch, falling back to those of the
+// current epoch if they have not been set yet. Once fetched, a stale value is returned right away while it is
+// refreshed in the background, so it is still returned if the API cannot be reached.
+func (c *Client) GetNextEpochMinRequiredVersion(ctx context.Context, cluster string) (*RequiredVersionInfo, error) {
+	return c.getRequiredVersion(ctx, cluster, &c.cache.next, c.fetchNextEpochMinRequiredVersion)
+}
+
+// getRequiredVersion returns the value in cached if it is fresh. Otherwise, it is fetched using fetch if there is no
+// cached value yet, or the stale value is returned and refreshed in the background, so that the requests to the API
+// (and their retries) do not hold up the scrape.
+func (c *Client) getRequiredVersion(
+	ctx context.Context,
+	cluster string,
+	cached *cachedRequiredVersion,
+	fetch func(context.Context, string) (*RequiredVersionInfo, error),
+) (*RequiredVersionInfo, error) {
+	// Check cache first
+	c.mu.RLock()
+	info, fetchedAt := cached.info, cached.fetchedAt
+	c.mu.RUnlock()
+	info.Cluster = cluster
+	if !fetchedAt.IsZero() && time.Since(fetchedAt) < c.cacheTimeout {
+		return &info, nil
 	}
 
-	// Validate the response
-	if len(stats.Data) == 0 {
-		return "", cluster, 0, "", fmt.Errorf("no data found in response")
+	if !fetchedAt.IsZero() {
+		c.refresh(ctx, cluster, cached, fetch)
+		return &info, nil
 	}
 
-	// Get the current epoch from the node
-	epochInfo, err := c.rpcClient.GetEpochInfo(ctx, rpc.CommitmentFinalized)
+	fetched, err := fetch(ctx, cluster)
 	if err != nil {
-		return "", cluster, 0, "", fmt.Errorf("failed to get current epoch: %w", err)
+		return nil, err
 	}
 
-	// Find the entry that matches the current epoch
-	var matchingEntry *struct {
-		Cluster                string  `json:"cluster"`
-		Epoch                  int     `json:"epoch"`
-		AgaveMinVersion        string  `json:"agave_min_version"`
-		AgaveMaxVersion        *string `json:"agave_max_version"`
-		FiredancerMaxVersion   *string `json:"firedancer_max_version"`
-		FiredancerMinVersion   string  `json:"firedancer_min_version"`
-		InheritedFromPrevEpoch bool    `json:"inherited_from_prev_epoch"`
-	}
-	for i := range stats.Data {
-		if stats.Data[i].Epoch == int(epochInfo.Epoch) {
-			matchingEntry = &stats.Data[i]
-			break
-		}
-	}
+	// Update cache
+	c.mu.Lock()
+	cached.info = *fetched
+	cached.fetchedAt = time.Now()
+	c.mu.Unlock()
 
-	// If no matching entry found, use the first entry as fallback
-	if matchingEntry == nil {
-		matchingEntry = &stats.Data[0]
-	}
+	return fetched, nil
+}
 
-	agaveMinVersion := matchingEntry.AgaveMinVersion
-	if agaveMinVersion == "" {
-		return "", cluster, 0, "", fmt.Errorf("agave_min_version not found in response")
+// refresh refreshes the stale value in cached using fetch in the background, unless it is already being refreshed.
+// The refresh outlives ctx (i.e., the scrape), and is bounded by RefreshTimeout instead.
+func (c *Client) refresh(
+	ctx context.Context,
+	cluster string,
+	cached *cachedRequiredVersion,
+	fetch func(context.Context, string) (*RequiredVersionInfo, error),
+) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached.refreshing {
+		return
 	}
+	cached.refreshing = true
+
+	c.refreshes.Add(1)
+	go func() {
+		defer c.refreshes.Done()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), RefreshTimeout)
+		defer cancel()
+		fetched, err := fetch(ctx, cluster)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		cached.refreshing = false
+		if err != nil {
+			slog.Get().Warnw(
+				"failed to refresh required versions, serving stale value",
+				"error", err, "age", time.Since(cached.fetchedAt),
+			)
+			return
+		}
+		cached.info = *fetched
+		cached.fetchedAt = time.Now()
+	}()
+}
 
-	firedancerMinVersion := matchingEntry.FiredancerMinVersion
-	epoch := matchingEntry.Epoch
+// fetchEpochStats fetches the required versions for the provided cluster from the API, retrying with exponential
+// backoff on failure (as long as the deadline of ctx allows), along with the current epoch of the node.
+func (c *Client) fetchEpochStats(ctx context.Context, cluster string) (*ValidatorEpochStats, int, error) {
+	var (
+		stats *ValidatorEpochStats
+		err   error
+	)
+	backoff := c.retryBackoff
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			// give up rather than wait past the deadline, e.g., the scrape timeout:
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return nil, 0, fmt.Errorf("%w (after %d attempts)", err, attempt)
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		if stats, err = c.requestEpochStats(ctx, cluster); err == nil {
+			break
+		}
+	}
 
-	// Update cache
 	c.mu.Lock()
-	c.cache.agaveVersion = agaveMinVersion
-	c.cache.firedancerVersion = firedancerMinVersion
-	c.cache.epoch = epoch
-	c.cache.lastCheck = time.Now()
+	c.up = err == nil
 	c.mu.Unlock()
+	if err != nil {
+		return nil, 0, err
+	}
 
-	return agaveMinVersion, cluster, epoch, firedancerMinVersion, nil
-}
-
-func (c *Client) GetNextEpochMinRequiredVersion(ctx context.Context, cluster string) (string, string, int, string, error) {
-	// Check cache first
-	c.mu.RLock()
-	if !c.cache.lastCheck.IsZero() && time.Since(c.cache.lastCheck) < c.cacheTimeout {
-		version := c.cache.nextAgaveVersion
-		firedancerVersion := c.cache.nextFiredancerVersion
-		epoch := c.cache.nextEpoch
-		c.mu.RUnlock()
-		return version, cluster, epoch, firedancerVersion, nil
+	// Get the current epoch from the node
+	epochInfo, err := c.rpcClient.GetEpochInfo(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get current epoch: %w", err)
 	}
-	c.mu.RUnlock()
+	return stats, int(epochInfo.Epoch), nil
+}
 
-	// Make API request
+// requestEpochStats makes a single request to the API for the required versions of the provided cluster.
+func (c *Client) requestEpochStats(ctx context.Context, cluster string) (*ValidatorEpochStats, error) {
 	url := fmt.Sprintf("%s?cluster=%s", c.baseURL, cluster)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return "", cluster, 0, "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := c.HttpClient.Do(req)
 	if err != nil {
-		return "", cluster, 0, "", fmt.Errorf("failed to fetch next epoch required version: %w", err)
+		return nil, fmt.Errorf("failed to fetch required versions: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch required versions: unexpected status %s", resp.Status)
+	}
+
 	var stats ValidatorEpochStats
 	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
-		return "", cluster, 0, "", fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	// Validate the response
 	if len(stats.Data) == 0 {
-		return "", cluster, 0, "", fmt.Errorf("no data found in response")
+		return nil, fmt.Errorf("no data found in response")
 	}
+	return &stats, nil
+}
 
-	// Get the current epoch from the node
-	epochInfo, err := c.rpcClient.GetEpochInfo(ctx, rpc.CommitmentFinalized)
+func (c *Client) fetchMinRequiredVersion(ctx context.Context, cluster string) (*RequiredVersionInfo, error) {
+	stats, currentEpoch, err := c.fetchEpochStats(ctx, cluster)
 	if err != nil {
-		return "", cluster, 0, "", fmt.Errorf("failed to get current epoch: %w", err)
+		return nil, err
 	}
 
-	// Find the entry that matches the next epoch
-	var matchingEntry *struct {
-		Cluster                string  `json:"cluster"`
-		Epoch                  int     `json:"epoch"`
-		AgaveMinVersion        string  `json:"agave_min_version"`
-		AgaveMaxVersion        *string `json:"agave_max_version"`
-		FiredancerMaxVersion   *string `json:"firedancer_max_version"`
-		FiredancerMinVersion   string  `json:"firedancer_min_version"`
-		InheritedFromPrevEpoch bool    `json:"inherited_from_prev_epoch"`
+	// Find the entry that matches the current epoch
+	var matchingEntry *EpochRequiredVersions
+	for i := range stats.Data {
+		if stats.Data[i].Epoch == currentEpoch {
+			matchingEntry = &stats.Data[i]
+			break
+		}
+	}
+
+	// If no matching entry found, use the first entry as fallback
+	if matchingEntry == nil {
+		matchingEntry = &stats.Data[0]
+	}
+
+	if matchingEntry.AgaveMinVersion == "" {
+		return nil, fmt.Errorf("agave_min_version not found in response")
+	}
+	info := matchingEntry.toRequiredVersionInfo(cluster)
+	return &info, nil
+}
+
+func (c *Client) fetchNextEpochMinRequiredVersion(ctx context.Context, cluster string) (*RequiredVersionInfo, error) {
+	stats, currentEpoch, err := c.fetchEpochStats(ctx, cluster)
+	if err != nil {
+		return nil, err
 	}
-	nextEpoch := int(epochInfo.Epoch) + 1
+
+	// Find the entry that matches the next epoch
+	var matchingEntry *EpochRequiredVersions
+	nextEpoch := currentEpoch + 1
 
 	// First try to find the exact next epoch
 	for i := range stats.Data {
@@ -195,7 +294,7 @@ func (c *Client) GetNextEpochMinRequiredVersion(ctx context.Context, cluster str
 	if matchingEntry == nil {
 		// Find the current epoch's entry
 		for i := range stats.Data {
-			if stats.Data[i].Epoch == int(epochInfo.Epoch) {
+			if stats.Data[i].Epoch == currentEpoch {
 				matchingEntry = &stats.Data[i]
 				break
 			}
@@ -207,30 +306,19 @@ func (c *Client) GetNextEpochMinRequiredVersion(ctx context.Context, cluster str
 		matchingEntry = &stats.Data[0]
 	}
 
-	// If we still don't have a matching entry, return an error
-	if matchingEntry == nil {
-		return "", cluster, 0, "", fmt.Errorf("no valid version requirements found in response")
-	}
-
-	agaveMinVersion := matchingEntry.AgaveMinVersion
-	if agaveMinVersion == "" {
-		return "", cluster, 0, "", fmt.Errorf("agave_min_version not found in response")
+	// as in fetchMinRequiredVersion, a missing firedancer_min_version is left to the collectors, as it only matters
+	// for Firedancer nodes:
+	if matchingEntry.AgaveMinVersion == "" {
+		return nil, fmt.Errorf("agave_min_version not found in response")
 	}
+	info := matchingEntry.toRequiredVersionInfo(cluster)
+	return &info, nil
+}
 
-	firedancerMinVersion := matchingEntry.FiredancerMinVersion
-	if firedancerMinVersion == "" {
-		return "", cluster, 0, "", fmt.Errorf("firedancer_min_version not found in response")
+// derefOrEmpty returns the value of the provided optional string, or an empty string if it is not set.
+func derefOrEmpty(s *string) string {
+	if s == nil {
+		return ""
 	}
-
-	epoch := matchingEntry.Epoch
-
-	// Update cache
-	c.mu.Lock()
-	c.cache.nextAgaveVersion = agaveMinVersion
-	c.cache.nextFiredancerVersion = firedancerMinVersion
-	c.cache.nextEpoch = epoch
-	c.cache.lastCheck = time.Now()
-	c.mu.Unlock()
-
-	return agaveMinVersion, cluster, epoch, firedancerMinVersion, nil
+	return *s
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -18,14 +19,15 @@ func init() {
 
 func TestClient_GetMinRequiredVersion(t *testing.T) {
 	tests := []struct {
-		name         string
-		cluster      string
-		mockJSON     string
-		currentEpoch int
-		wantErr      bool
-		wantErrMsg   string
-		want         string
-		wantEpoch    int
+		name          string
+		cluster       string
+		mockJSON      string
+		currentEpoch  int
+		wantErr       bool
+		wantErrMsg    string
+		want          string
+		wantEpoch     int
+		wantInherited bool
 	}{
 		{
 			name:    "valid mainnet response with matching epoch",
@@ -72,9 +74,10 @@ func TestClient_GetMinRequiredVersion(t *testing.T) {
 					}
 				]
 			}`,
-			currentEpoch: 797,
-			want:         "2.2.14",
-			wantEpoch:    796,
+			currentEpoch:  797,
+			want:          "2.2.14",
+			wantEpoch:     796,
+			wantInherited: true,
 		},
 		{
 			name:    "valid testnet response",
@@ -92,9 +95,10 @@ func TestClient_GetMinRequiredVersion(t *testing.T) {
 					}
 				]
 			}`,
-			currentEpoch: 797,
-			want:         "2.1.6",
-			wantEpoch:    797,
+			currentEpoch:  797,
+			want:          "2.1.6",
+			wantEpoch:     797,
+			wantInherited: true,
 		},
 		{
 			name:         "invalid json response",
@@ -152,12 +156,12 @@ func TestClient_GetMinRequiredVersion(t *testing.T) {
 			defer mockServer.Close()
 
 			// Create client with test server URL
-			client := NewClient(mockRPCClient)
-			client.baseURL = server.URL + "/api/epoch/required_versions"
+			client := NewClient(mockRPCClient, server.URL+"/api/epoch/required_versions")
 			client.cacheTimeout = time.Hour
+			client.retryBackoff = time.Millisecond
 
 			// Test GetMinRequiredVersion
-			got, gotCluster, gotEpoch, gotFiredancerVersion, err := client.GetMinRequiredVersion(context.Background(), tt.cluster)
+			got, err := client.GetMinRequiredVersion(context.Background(), tt.cluster)
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.wantErrMsg)
@@ -165,18 +169,16 @@ func TestClient_GetMinRequiredVersion(t *testing.T) {
 			}
 
 			assert.NoError(t, err)
-			assert.Equal(t, tt.want, got)
-			assert.Equal(t, tt.cluster, gotCluster)
-			assert.Equal(t, tt.wantEpoch, gotEpoch)
-			assert.NotEmpty(t, gotFiredancerVersion)
+			assert.Equal(t, tt.want, got.AgaveMinVersion)
+			assert.Equal(t, tt.cluster, got.Cluster)
+			assert.Equal(t, tt.wantEpoch, got.Epoch)
+			assert.NotEmpty(t, got.FiredancerMinVersion)
+			assert.Equal(t, tt.wantInherited, got.InheritedFromPrevEpoch)
 
 			// Test caching
-			cachedVersion, cachedCluster, cachedEpoch, cachedFiredancerVersion, err := client.GetMinRequiredVersion(context.Background(), tt.cluster)
+			cached, err := client.GetMinRequiredVersion(context.Background(), tt.cluster)
 			assert.NoError(t, err)
-			assert.Equal(t, tt.want, cachedVersion)
-			assert.Equal(t, tt.cluster, cachedCluster)
-			assert.Equal(t, tt.wantEpoch, cachedEpoch)
-			assert.NotEmpty(t, cachedFiredancerVersion)
+			assert.Equal(t, got, cached)
 		})
 	}
 }
@@ -191,6 +193,8 @@ func TestClient_GetNextEpochMinRequiredVersion(t *testing.T) {
 		wantErrMsg   string
 		want         string
 		wantEpoch    int
+		// wantFiredancer is the expected firedancer_min_version, which may be null:
+		wantFiredancer string
 	}{
 		{
 			name:    "valid mainnet response with next epoch",
@@ -226,6 +230,27 @@ func TestClient_GetNextEpochMinRequiredVersion(t *testing.T) {
 					}
 				]
 			}`,
+			currentEpoch:   797,
+			want:           "2.2.16",
+			wantEpoch:      798,
+			wantFiredancer: "0.503.20216",
+		},
+		{
+			name:    "null firedancer min version",
+			cluster: "mainnet-beta",
+			mockJSON: `{
+				"data": [
+					{
+						"cluster": "mainnet-beta",
+						"epoch": 798,
+						"agave_min_version": "2.2.16",
+						"agave_max_version": null,
+						"firedancer_max_version": null,
+						"firedancer_min_version": null,
+						"inherited_from_prev_epoch": false
+					}
+				]
+			}`,
 			currentEpoch: 797,
 			want:         "2.2.16",
 			wantEpoch:    798,
@@ -246,9 +271,10 @@ func TestClient_GetNextEpochMinRequiredVersion(t *testing.T) {
 					}
 				]
 			}`,
-			currentEpoch: 797,
-			want:         "2.2.15",
-			wantEpoch:    797,
+			currentEpoch:   797,
+			want:           "2.2.15",
+			wantEpoch:      797,
+			wantFiredancer: "0.503.20215",
 		},
 		{
 			name:         "empty data array",
@@ -290,12 +316,12 @@ func TestClient_GetNextEpochMinRequiredVersion(t *testing.T) {
 			defer mockServer.Close()
 
 			// Create client with test server URL
-			client := NewClient(mockRPCClient)
-			client.baseURL = server.URL + "/api/epoch/required_versions"
+			client := NewClient(mockRPCClient, server.URL+"/api/epoch/required_versions")
 			client.cacheTimeout = time.Hour
+			client.retryBackoff = time.Millisecond
 
 			// Test GetNextEpochMinRequiredVersion
-			got, gotCluster, gotEpoch, gotFiredancerVersion, err := client.GetNextEpochMinRequiredVersion(context.Background(), tt.cluster)
+			got, err := client.GetNextEpochMinRequiredVersion(context.Background(), tt.cluster)
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.wantErrMsg)
@@ -303,18 +329,148 @@ func TestClient_GetNextEpochMinRequiredVersion(t *testing.T) {
 			}
 
 			assert.NoError(t, err)
-			assert.Equal(t, tt.want, got)
-			assert.Equal(t, tt.cluster, gotCluster)
-			assert.Equal(t, tt.wantEpoch, gotEpoch)
-			assert.NotEmpty(t, gotFiredancerVersion)
+			assert.Equal(t, tt.want, got.AgaveMinVersion)
+			assert.Equal(t, tt.cluster, got.Cluster)
+			assert.Equal(t, tt.wantEpoch, got.Epoch)
+			assert.Equal(t, tt.wantFiredancer, got.FiredancerMinVersion)
 
 			// Test caching
-			cachedVersion, cachedCluster, cachedEpoch, cachedFiredancerVersion, err := client.GetNextEpochMinRequiredVersion(context.Background(), tt.cluster)
+			cached, err := client.GetNextEpochMinRequiredVersion(context.Background(), tt.cluster)
 			assert.NoError(t, err)
-			assert.Equal(t, tt.want, cachedVersion)
-			assert.Equal(t, tt.cluster, cachedCluster)
-			assert.Equal(t, tt.wantEpoch, cachedEpoch)
-			assert.NotEmpty(t, cachedFiredancerVersion)
+			assert.Equal(t, got, cached)
 		})
 	}
 }
+
+func TestClient_GetMinRequiredVersion_MaxVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"data": [
+				{
+					"cluster": "mainnet-beta",
+					"epoch": 797,
+					"agave_min_version": "2.2.14",
+					"agave_max_version": "2.3.0",
+					"firedancer_max_version": null,
+					"firedancer_min_version": "0.503.20214",
+					"inherited_from_prev_epoch": false
+				}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	mockServer, mockRPCClient := rpc.NewMockClient(t,
+		map[string]any{"getEpochInfo": map[string]int{"epoch": 797}},
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+	defer mockServer.Close()
+
+	client := NewClient(mockRPCClient, server.URL+"/api/epoch/required_versions")
+
+	got, err := client.GetMinRequiredVersion(context.Background(), "mainnet-beta")
+	assert.NoError(t, err)
+	assert.Equal(t, "2.3.0", got.AgaveMaxVersion)
+	assert.Equal(t, "", got.FiredancerMaxVersion)
+}
+
+func TestClient_GetMinRequiredVersion_APIDown(t *testing.T) {
+	var (
+		failing  atomic.Bool
+		requests atomic.Int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"data": [
+				{
+					"cluster": "mainnet-beta",
+					"epoch": 797,
+					"agave_min_version": "2.2.14",
+					"agave_max_version": null,
+					"firedancer_max_version": null,
+					"firedancer_min_version": "0.503.20214",
+					"inherited_from_prev_epoch": false
+				}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	mockServer, mockRPCClient := rpc.NewMockClient(t,
+		map[string]any{"getEpochInfo": map[string]int{"epoch": 797}},
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+	defer mockServer.Close()
+
+	client := NewClient(mockRPCClient, server.URL+"/api/epoch/required_versions")
+	client.retryBackoff = time.Millisecond
+	_, ok := client.CacheAge()
+	assert.False(t, ok)
+
+	// fill the cache:
+	got, err := client.GetMinRequiredVersion(context.Background(), "mainnet-beta")
+	assert.NoError(t, err)
+	assert.Equal(t, "2.2.14", got.AgaveMinVersion)
+	assert.True(t, client.Up())
+	assert.Equal(t, int32(1), requests.Load())
+
+	// now expire the cache and take the API down:
+	client.cacheTimeout = 0
+	failing.Store(true)
+	stale, err := client.GetMinRequiredVersion(context.Background(), "mainnet-beta")
+	assert.NoError(t, err)
+	assert.Equal(t, got, stale)
+	// the stale value is returned right away, and refreshed in the background:
+	client.refreshes.Wait()
+	assert.False(t, client.Up())
+	assert.Equal(t, int32(1+1+MaxRetries), requests.Load())
+	age, ok := client.CacheAge()
+	assert.True(t, ok)
+	assert.Greater(t, age, time.Duration(0))
+
+	// without a cached value, the error is returned:
+	_, err = client.GetNextEpochMinRequiredVersion(context.Background(), "mainnet-beta")
+	assert.ErrorContains(t, err, "500")
+
+	// and once the API recovers, the cache is refreshed:
+	failing.Store(false)
+	_, err = client.GetMinRequiredVersion(context.Background(), "mainnet-beta")
+	assert.NoError(t, err)
+	client.refreshes.Wait()
+	assert.True(t, client.Up())
+	age, _ = client.CacheAge()
+	assert.Less(t, age, time.Second)
+}
+
+func TestClient_GetMinRequiredVersion_RetryDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(nil, server.URL+"/api/epoch/required_versions")
+	client.retryBackoff = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// the retries are given up on rather than waiting past the deadline of the scrape:
+	start := time.Now()
+	_, err := client.GetMinRequiredVersion(ctx, "mainnet-beta")
+	assert.ErrorContains(t, err, "500")
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -15,37 +15,38 @@ func NewMockClient() *Client {
 		HttpClient:   http.Client{},
 		baseURL:      SolanaEpochStatsAPI,
 		cacheTimeout: CacheTimeout,
-		cache: struct {
-			agaveVersion          string
-			firedancerVersion     string
-			nextAgaveVersion      string
-			nextFiredancerVersion string
-			lastCheck             time.Time
-			epoch                 int
-			nextEpoch             int
-		}{},
+		up:           true,
 	}
 	return mock
 }
 
 func (m *Client) SetMinRequiredVersion(agaveVersion, firedancerVersion string) {
-	m.cache.agaveVersion = agaveVersion
-	m.cache.firedancerVersion = firedancerVersion
-	m.cache.epoch = 797 // Set a specific epoch value
-	m.cache.lastCheck = time.Now()
+	m.cache.current.info.AgaveMinVersion = agaveVersion
+	m.cache.current.info.FiredancerMinVersion = firedancerVersion
+	m.cache.current.info.Epoch = 797 // Set a specific epoch value
+	m.cache.current.fetchedAt = time.Now()
+}
+
+func (m *Client) SetMaxAllowedVersion(agaveMaxVersion, firedancerMaxVersion string) {
+	m.cache.current.info.AgaveMaxVersion = agaveMaxVersion
+	m.cache.current.info.FiredancerMaxVersion = firedancerMaxVersion
 }
 
 func (m *Client) SetNextEpochMinRequiredVersion(agaveVersion, firedancerVersion string) {
-	m.cache.nextAgaveVersion = agaveVersion
-	m.cache.nextFiredancerVersion = firedancerVersion
-	m.cache.nextEpoch = 798 // Set next epoch value
-	m.cache.lastCheck = time.Now()
+	m.cache.next.info.AgaveMinVersion = agaveVersion
+	m.cache.next.info.FiredancerMinVersion = firedancerVersion
+	m.cache.next.info.Epoch = 798 // Set next epoch value
+	m.cache.next.fetchedAt = time.Now()
 }
 
-func (m *MockClient) GetMinRequiredVersion(ctx context.Context, cluster string) (string, string, int, string, error) {
-	return m.cache.agaveVersion, cluster, m.cache.epoch, m.cache.firedancerVersion, nil
+func (m *MockClient) GetMinRequiredVersion(ctx context.Context, cluster string) (*RequiredVersionInfo, error) {
+	info := m.cache.current.info
+	info.Cluster = cluster
+	return &info, nil
 }
 
-func (m *MockClient) GetNextEpochMinRequiredVersion(ctx context.Context, cluster string) (string, string, int, string, error) {
-	return m.cache.nextAgaveVersion, cluster, m.cache.nextEpoch, m.cache.nextFiredancerVersion, nil
+func (m *MockClient) GetNextEpochMinRequiredVersion(ctx context.Context, cluster string) (*RequiredVersionInfo, error) {
+	info := m.cache.next.info
+	info.Cluster = cluster
+	return &info, nil
 }
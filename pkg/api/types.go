@@ -1,13 +1,41 @@
 package api
 
 type ValidatorEpochStats struct {
-	Data []struct {
-		Cluster                string  `json:"cluster"`
-		Epoch                  int     `json:"epoch"`
-		AgaveMinVersion        string  `json:"agave_min_version"`
-		AgaveMaxVersion        *string `json:"agave_max_version"`
-		FiredancerMaxVersion   *string `json:"firedancer_max_version"`
-		FiredancerMinVersion   string  `json:"firedancer_min_version"`
-		InheritedFromPrevEpoch bool    `json:"inherited_from_prev_epoch"`
-	} `json:"data"`
+	Data []EpochRequiredVersions `json:"data"`
+}
+
+// EpochRequiredVersions is a single entry of the required versions API response.
+type EpochRequiredVersions struct {
+	Cluster                string  `json:"cluster"`
+	Epoch                  int     `json:"epoch"`
+	AgaveMinVersion        string  `json:"agave_min_version"`
+	AgaveMaxVersion        *string `json:"agave_max_version"`
+	FiredancerMaxVersion   *string `json:"firedancer_max_version"`
+	FiredancerMinVersion   string  `json:"firedancer_min_version"`
+	InheritedFromPrevEpoch bool    `json:"inherited_from_prev_epoch"`
+}
+
+// RequiredVersionInfo describes the versions a validator is required to run on a given cluster and epoch. Empty max
+// versions mean that there is no maximum.
+type RequiredVersionInfo struct {
+	Cluster                string
+	Epoch                  int
+	AgaveMinVersion        string
+	AgaveMaxVersion        string
+	FiredancerMinVersion   string
+	FiredancerMaxVersion   string
+	InheritedFromPrevEpoch bool
+}
+
+// toRequiredVersionInfo converts the API entry to a RequiredVersionInfo for the provided cluster.
+func (e *EpochRequiredVersions) toRequiredVersionInfo(cluster string) RequiredVersionInfo {
+	return RequiredVersionInfo{
+		Cluster:                cluster,
+		Epoch:                  e.Epoch,
+		AgaveMinVersion:        e.AgaveMinVersion,
+		AgaveMaxVersion:        derefOrEmpty(e.AgaveMaxVersion),
+		FiredancerMinVersion:   e.FiredancerMinVersion,
+		FiredancerMaxVersion:   derefOrEmpty(e.FiredancerMaxVersion),
+		InheritedFromPrevEpoch: e.InheritedFromPrevEpoch,
+	}
 }
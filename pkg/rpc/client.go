@@ -2,12 +2,16 @@ package rpc
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"slices"
+	"sync/atomic"
 	"time"
 
 	"github.com/asymmetric-research/solana-exporter/pkg/slog"
@@ -16,11 +20,19 @@ import (
 
 type (
 	Client struct {
-		HttpClient            http.Client
-		RpcUrl                string
-		HttpTimeout           time.Duration
+		HttpClient  http.Client
+		RpcUrl      string
+		HttpTimeout time.Duration
+		// MethodTimeouts overrides HttpTimeout for the given methods:
+		MethodTimeouts map[string]time.Duration
+		// Headers are added to every rpc request, e.g., for authentication with rpc providers:
+		Headers http.Header
+		// Gzip requests gzip-compressed responses, which are transparently decompressed:
+		Gzip                  bool
 		logger                *zap.SugaredLogger
 		FiredancerMetricsPort int
+		// batchNotSupported records whether the rpc rejected a batch request, so that batches are not tried again:
+		batchNotSupported atomic.Bool
 	}
 
 	Request struct {
@@ -46,11 +58,79 @@ const (
 	// CommitmentProcessed level represents a transaction that has been received by the network and included in a block.
 	CommitmentProcessed Commitment = "processed"
 
+	StakeStateActive       = "active"
+	StakeStateInactive     = "inactive"
+	StakeStateActivating   = "activating"
+	StakeStateDeactivating = "deactivating"
+
+	// MinimumSlotsPerEpoch is the length of the first epoch of clusters with epoch warmup.
+	MinimumSlotsPerEpoch = 32
+
+	// MaxMultipleAccounts is the maximum number of accounts which can be fetched in a single getMultipleAccounts call.
+	MaxMultipleAccounts = 100
+
+	// SystemProgram is the native program owning all wallet accounts.
+	SystemProgram = "11111111111111111111111111111111"
+	// StakeProgram is the native program owning all stake accounts.
+	StakeProgram = "Stake11111111111111111111111111111111111111"
+	// StakeAccountSize is the data size (in bytes) of stake accounts.
+	StakeAccountSize = 200
+	// StakeWithdrawerOffset is the offset (in bytes) of the withdraw authority within the data of stake accounts.
+	StakeWithdrawerOffset = 44
+	// UpgradeableLoaderProgram is the program owning all upgradeable programs, and their ProgramData accounts.
+	UpgradeableLoaderProgram = "BPFLoaderUpgradeab1e11111111111111111111111"
+	// MaxAccountDataSize is the maximum data size (in bytes) of an account.
+	MaxAccountDataSize = 10 * 1024 * 1024
+
 	DevnetGenesisHash  = "EtWTRABZaYq6iMfeYKouRu166VU2xqa1wcaWoxPkrZBG"
 	TestnetGenesisHash = "4uhcVJyU9pJkvQyS88uRDiswHXSCkY3zQawwpjk2NsNY"
 	MainnetGenesisHash = "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdpKuc147dw2N9d"
 )
 
+// ErrBatchNotSupported is returned for batch requests to rpcs which only accept single requests, i.e., which reject
+// batches with an invalid request (or method not found) error.
+var ErrBatchNotSupported = errors.New("batch requests are not supported")
+
+// Commitments lists all the valid commitment levels.
+var Commitments = []Commitment{CommitmentProcessed, CommitmentConfirmed, CommitmentFinalized}
+
+// Methods lists all the rpc methods called by the Client.
+var Methods = []string{
+	"getEpochInfo",
+	"getVoteAccounts",
+	"getVersion",
+	"getIdentity",
+	"getClusterNodes",
+	"getSlot",
+	"getMaxShredInsertSlot",
+	"getBlockProduction",
+	"getBalance",
+	"getMinimumBalanceForRentExemption",
+	"getStakeMinimumDelegation",
+	"getLargestAccounts",
+	"getStakeActivation",
+	"getTokenAccountBalance",
+	"getAccountInfo",
+	"getMultipleAccounts",
+	"getProgramAccounts",
+	"getInflationReward",
+	"getLeaderSchedule",
+	"getSlotLeaders",
+	"getEpochSchedule",
+	"getRecentPerformanceSamples",
+	"getTransactionCount",
+	"getBlock",
+	"getHealth",
+	"minimumLedgerSlot",
+	"getFirstAvailableBlock",
+	"getHighestSnapshotSlot",
+	"getBlockTime",
+	"getGenesisHash",
+	"getLatestBlockhash",
+	"sendTransaction",
+	"getSignatureStatuses",
+}
+
 // GetClusterFromGenesisHash returns the cluster name based on the genesis hash
 func GetClusterFromGenesisHash(hash string) (string, error) {
 	switch hash {
@@ -75,12 +155,10 @@ func NewRPCClient(rpcAddr string, httpTimeout time.Duration, firedancerMetricsPo
 	}
 }
 
-func getResponse[T any](
-	ctx context.Context, client *Client, method string, params []any, rpcResponse *Response[T],
-) error {
+// post sends the request (or batch of requests) for method to the rpc, and returns the body and HTTP status code of
+// the response.
+func post(ctx context.Context, client *Client, method string, request any) ([]byte, int, error) {
 	logger := slog.Get()
-	// format request:
-	request := &Request{Jsonrpc: "2.0", Id: 1, Method: method, Params: params}
 	buffer, err := json.Marshal(request)
 	if err != nil {
 		logger.Fatalf("failed to marshal request: %v", err)
@@ -88,41 +166,148 @@ func getResponse[T any](
 	logger.Debugf("jsonrpc request: %s", string(buffer))
 
 	// make request:
-	ctx, cancel := context.WithTimeout(ctx, client.HttpTimeout)
+	ctx, cancel := context.WithTimeout(ctx, client.timeout(method))
 	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, "POST", client.RpcUrl, bytes.NewBuffer(buffer))
 	if err != nil {
 		logger.Fatalf("failed to create request: %v", err)
 	}
+	for key, values := range client.Headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
 	req.Header.Set("content-type", "application/json")
+	if client.Gzip {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
 
+	RequestsTotal.WithLabelValues(method).Inc()
+	start := time.Now()
 	resp, err := client.HttpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("%s rpc call failed: %w", method, err)
+		observeLatency(method, start)
+		recordError(method, ErrorCodeTransport)
+		return nil, 0, fmt.Errorf("%s rpc call failed: %w", method, err)
 	}
 	//goland:noinspection GoUnhandledErrorResult
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	// the size of the decompressed response is observed, as that is what has to be decoded:
+	reader := &countingReader{reader: resp.Body}
+	var gzipReader *gzip.Reader
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err = gzip.NewReader(resp.Body)
+		if err != nil {
+			observeLatency(method, start)
+			recordError(method, ErrorCodeTransport)
+			return nil, 0, fmt.Errorf("error decompressing %s rpc response: %w", method, err)
+		}
+		reader.reader = gzipReader
+	}
+	body, err := io.ReadAll(reader)
+	if gzipReader != nil {
+		// the errors of a corrupt or truncated stream (e.g., its checksum) may only be surfaced on close:
+		err = errors.Join(err, gzipReader.Close())
+	}
+	observeLatency(method, start)
+	ResponseBytes.WithLabelValues(method).Observe(float64(reader.count))
 	if err != nil {
-		return fmt.Errorf("error processing %s rpc call: %w", method, err)
+		recordError(method, ErrorCodeTransport)
+		return nil, 0, fmt.Errorf("error processing %s rpc call: %w", method, err)
 	}
 	// debug log response:
 	logger.Debugf("%s response: %v", method, string(body))
+	return body, resp.StatusCode, nil
+}
+
+func getResponse[T any](
+	ctx context.Context, client *Client, method string, params []any, rpcResponse *Response[T],
+) error {
+	body, _, err := post(ctx, client, method, &Request{Jsonrpc: "2.0", Id: 1, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
 
 	// unmarshal the response into the predicted format
 	if err = json.Unmarshal(body, rpcResponse); err != nil {
+		recordError(method, ErrorCodeDecode)
 		return fmt.Errorf("failed to decode %s response body: %w", method, err)
 	}
 
 	// check for an actual rpc error
 	if rpcResponse.Error.Code != 0 {
+		recordError(method, rpcErrorCode(rpcResponse.Error.Code))
 		rpcResponse.Error.Method = method
 		return &rpcResponse.Error
 	}
 	return nil
 }
 
+// getBatchResponses calls method with each of the provided params in a single batch request, and returns the
+// responses in the same order as params. ErrBatchNotSupported is returned if the rpc does not accept batch requests.
+func getBatchResponses[T any](
+	ctx context.Context, client *Client, method string, params [][]any,
+) ([]Response[T], error) {
+	requests := make([]Request, len(params))
+	for i := range params {
+		requests[i] = Request{Jsonrpc: "2.0", Id: i, Method: method, Params: params[i]}
+	}
+	body, status, err := post(ctx, client, method, requests)
+	if err != nil {
+		return nil, err
+	}
+
+	// rpcs which do not support batches reply with a single error response instead, which is told apart from the
+	// transient failures (e.g., rate limits or proxy errors) which may be the reply to any request:
+	if !bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		var rpcResponse Response[any]
+		if status != http.StatusOK || json.Unmarshal(body, &rpcResponse) != nil || rpcResponse.Error.Code == 0 {
+			recordError(method, ErrorCodeTransport)
+			return nil, fmt.Errorf("%s batch rpc call failed with status %d", method, status)
+		}
+		recordError(method, rpcErrorCode(rpcResponse.Error.Code))
+		rpcResponse.Error.Method = method
+		if code := rpcResponse.Error.Code; code == InvalidRequestCode || code == MethodNotFoundCode {
+			return nil, fmt.Errorf("%s rpc call failed: %w: %w", method, ErrBatchNotSupported, &rpcResponse.Error)
+		}
+		return nil, &rpcResponse.Error
+	}
+	var rpcResponses []Response[T]
+	if err = json.Unmarshal(body, &rpcResponses); err != nil {
+		recordError(method, ErrorCodeDecode)
+		return nil, fmt.Errorf("failed to decode %s batch response body: %w", method, err)
+	}
+	if len(rpcResponses) != len(params) {
+		recordError(method, ErrorCodeDecode)
+		return nil, fmt.Errorf("expected %d %s responses, got %d", len(params), method, len(rpcResponses))
+	}
+
+	// the responses of a batch may be returned in any order, so they are sorted by id:
+	ordered := make([]Response[T], len(params))
+	for _, rpcResponse := range rpcResponses {
+		if rpcResponse.Error.Code != 0 {
+			recordError(method, rpcErrorCode(rpcResponse.Error.Code))
+			rpcResponse.Error.Method = method
+			return nil, &rpcResponse.Error
+		}
+		if rpcResponse.Id < 0 || rpcResponse.Id >= len(params) {
+			recordError(method, ErrorCodeDecode)
+			return nil, fmt.Errorf("unexpected id %d in %s batch response", rpcResponse.Id, method)
+		}
+		ordered[rpcResponse.Id] = rpcResponse
+	}
+	return ordered, nil
+}
+
+// timeout returns the timeout of requests to the provided method, which is HttpTimeout unless overridden.
+func (c *Client) timeout(method string) time.Duration {
+	if timeout, ok := c.MethodTimeouts[method]; ok {
+		return timeout
+	}
+	return c.HttpTimeout
+}
+
 // GetEpochInfo returns information about the current epoch.
 // See API docs: https://solana.com/docs/rpc/http/getepochinfo
 func (c *Client) GetEpochInfo(ctx context.Context, commitment Commitment) (*EpochInfo, error) {
@@ -135,10 +320,14 @@ func (c *Client) GetEpochInfo(ctx context.Context, commitment Commitment) (*Epoc
 }
 
 // GetVoteAccounts returns the account info and associated stake for all the voting accounts in the current bank.
+// If votePubkey is not empty, only the vote account with that address is returned.
 // See API docs: https://solana.com/docs/rpc/http/getvoteaccounts
-func (c *Client) GetVoteAccounts(ctx context.Context, commitment Commitment) (*VoteAccounts, error) {
+func (c *Client) GetVoteAccounts(ctx context.Context, commitment Commitment, votePubkey string) (*VoteAccounts, error) {
 	// format params:
 	config := map[string]string{"commitment": string(commitment)}
+	if votePubkey != "" {
+		config["votePubkey"] = votePubkey
+	}
 	var resp Response[VoteAccounts]
 	if err := getResponse(ctx, c, "getVoteAccounts", []any{config}, &resp); err != nil {
 		return nil, err
@@ -146,16 +335,14 @@ func (c *Client) GetVoteAccounts(ctx context.Context, commitment Commitment) (*V
 	return &resp.Result, nil
 }
 
-// GetVersion returns the current Solana version running on the node.
+// GetVersion returns the current Solana version running on the node, along with its feature set.
 // See API docs: https://solana.com/docs/rpc/http/getversion
-func (c *Client) GetVersion(ctx context.Context) (string, error) {
-	var resp Response[struct {
-		Version string `json:"solana-core"`
-	}]
+func (c *Client) GetVersion(ctx context.Context) (*Version, error) {
+	var resp Response[Version]
 	if err := getResponse(ctx, c, "getVersion", []any{}, &resp); err != nil {
-		return "", err
+		return nil, err
 	}
-	return resp.Result.Version, nil
+	return &resp.Result, nil
 }
 
 // GetIdentity returns identity pubkey for the current node.
@@ -170,6 +357,53 @@ func (c *Client) GetIdentity(ctx context.Context) (string, error) {
 	return resp.Result.Identity, nil
 }
 
+// GetLatestBlockhash returns the latest blockhash (base58 encoded), to build transactions with.
+// See API docs: https://solana.com/docs/rpc/http/getlatestblockhash
+func (c *Client) GetLatestBlockhash(ctx context.Context, commitment Commitment) (string, error) {
+	config := map[string]string{"commitment": string(commitment)}
+	var resp Response[contextualResult[struct {
+		Blockhash string `json:"blockhash"`
+	}]]
+	if err := getResponse(ctx, c, "getLatestBlockhash", []any{config}, &resp); err != nil {
+		return "", err
+	}
+	return resp.Result.Value.Blockhash, nil
+}
+
+// SendTransaction submits the provided signed (wire-encoded) transaction to the cluster, and returns its signature.
+// See API docs: https://solana.com/docs/rpc/http/sendtransaction
+func (c *Client) SendTransaction(ctx context.Context, commitment Commitment, transaction []byte) (string, error) {
+	config := map[string]string{"encoding": "base64", "preflightCommitment": string(commitment)}
+	params := []any{base64.StdEncoding.EncodeToString(transaction), config}
+	var resp Response[string]
+	if err := getResponse(ctx, c, "sendTransaction", params, &resp); err != nil {
+		return "", err
+	}
+	return resp.Result, nil
+}
+
+// GetSignatureStatuses returns the statuses of the provided transaction signatures, which are nil for unknown
+// signatures. Only the recent status cache of the node is searched.
+// See API docs: https://solana.com/docs/rpc/http/getsignaturestatuses
+func (c *Client) GetSignatureStatuses(ctx context.Context, signatures []string) ([]*SignatureStatus, error) {
+	var resp Response[contextualResult[[]*SignatureStatus]]
+	if err := getResponse(ctx, c, "getSignatureStatuses", []any{signatures}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Result.Value, nil
+}
+
+// GetClusterNodes returns information about all the nodes participating in the cluster, as seen in the gossip
+// table of the node.
+// See API docs: https://solana.com/docs/rpc/http/getclusternodes
+func (c *Client) GetClusterNodes(ctx context.Context) ([]ContactInfo, error) {
+	var resp Response[[]ContactInfo]
+	if err := getResponse(ctx, c, "getClusterNodes", []any{}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Result, nil
+}
+
 // GetSlot returns the slot that has reached the given or default commitment level.
 // See API docs: https://solana.com/docs/rpc/http/getslot
 func (c *Client) GetSlot(ctx context.Context, commitment Commitment) (int64, error) {
@@ -181,6 +415,17 @@ func (c *Client) GetSlot(ctx context.Context, commitment Commitment) (int64, err
 	return resp.Result, nil
 }
 
+// GetMaxShredInsertSlot returns the highest slot for which the node has received shreds, which tracks the slot the
+// cluster is producing.
+// See API docs: https://solana.com/docs/rpc/http/getmaxshredinsertslot
+func (c *Client) GetMaxShredInsertSlot(ctx context.Context) (int64, error) {
+	var resp Response[int64]
+	if err := getResponse(ctx, c, "getMaxShredInsertSlot", []any{}, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Result, nil
+}
+
 // GetBlockProduction returns recent block production information from the current or previous epoch.
 // See API docs: https://solana.com/docs/rpc/http/getblockproduction
 func (c *Client) GetBlockProduction(
@@ -210,6 +455,203 @@ func (c *Client) GetBalance(ctx context.Context, commitment Commitment, address
 	return float64(resp.Result.Value) / float64(LamportsInSol), nil
 }
 
+// GetMinimumBalanceForRentExemption returns the minimum balance (in lamports) for an account with dataSize bytes of
+// data to be rent exempt.
+// See API docs: https://solana.com/docs/rpc/http/getminimumbalanceforrentexemption
+func (c *Client) GetMinimumBalanceForRentExemption(
+	ctx context.Context, commitment Commitment, dataSize int64,
+) (int64, error) {
+	config := map[string]string{"commitment": string(commitment)}
+	var resp Response[int64]
+	if err := getResponse(ctx, c, "getMinimumBalanceForRentExemption", []any{dataSize, config}, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Result, nil
+}
+
+// GetStakeMinimumDelegation returns the minimum delegation (in SOL) of a stake account.
+// See API docs: https://solana.com/docs/rpc/http/getstakeminimumdelegation
+func (c *Client) GetStakeMinimumDelegation(ctx context.Context, commitment Commitment) (float64, error) {
+	config := map[string]string{"commitment": string(commitment)}
+	var resp Response[contextualResult[int64]]
+	if err := getResponse(ctx, c, "getStakeMinimumDelegation", []any{config}, &resp); err != nil {
+		return 0, err
+	}
+	return float64(resp.Result.Value) / float64(LamportsInSol), nil
+}
+
+// GetBalances returns the balances (in SOL) of the provided addresses, which are fetched with getMultipleAccounts in
+// chunks of MaxMultipleAccounts addresses. The chunks are all sent in a single batch request or, if the rpc does not
+// support batch requests, one by one. Accounts which do not exist have a zero balance.
+func (c *Client) GetBalances(
+	ctx context.Context, commitment Commitment, addresses []string,
+) (map[string]float64, error) {
+	var chunks [][]string
+	for start := 0; start < len(addresses); start += MaxMultipleAccounts {
+		chunks = append(chunks, addresses[start:min(start+MaxMultipleAccounts, len(addresses))])
+	}
+	accounts, err := c.getAccountChunks(ctx, commitment, chunks)
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts) != len(addresses) {
+		return nil, fmt.Errorf("expected %d accounts, got %d", len(addresses), len(accounts))
+	}
+
+	balances := make(map[string]float64, len(addresses))
+	for i, address := range addresses {
+		balances[address] = 0
+		if accounts[i] != nil {
+			balances[address] = float64(accounts[i].Lamports) / float64(LamportsInSol)
+		}
+	}
+	return balances, nil
+}
+
+// getAccountChunks fetches the accounts of each chunk of addresses with getMultipleAccounts, in a single batch request
+// if the rpc supports it, and returns all the accounts in order.
+func (c *Client) getAccountChunks(ctx context.Context, commitment Commitment, chunks [][]string) ([]*Account, error) {
+	var accounts []*Account
+	if len(chunks) > 1 && !c.batchNotSupported.Load() {
+		params := make([][]any, len(chunks))
+		for i, chunk := range chunks {
+			params[i] = multipleAccountsParams(commitment, chunk)
+		}
+		responses, err := getBatchResponses[contextualResult[[]*Account]](ctx, c, "getMultipleAccounts", params)
+		if err == nil {
+			for _, response := range responses {
+				accounts = append(accounts, response.Result.Value...)
+			}
+			return accounts, nil
+		}
+		if !errors.Is(err, ErrBatchNotSupported) {
+			return nil, err
+		}
+		c.logger.Warnf("RPC does not support batch requests, fetching accounts one chunk at a time: %v", err)
+		c.batchNotSupported.Store(true)
+	}
+
+	for _, chunk := range chunks {
+		chunkAccounts, err := c.GetMultipleAccounts(ctx, commitment, chunk)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, chunkAccounts...)
+	}
+	return accounts, nil
+}
+
+// GetMultipleAccounts returns the accounts of the provided (up to MaxMultipleAccounts) addresses, in the same order,
+// which are nil for accounts that do not exist. Only the account metadata is fetched, not the account data.
+// See API docs: https://solana.com/docs/rpc/http/getmultipleaccounts
+func (c *Client) GetMultipleAccounts(
+	ctx context.Context, commitment Commitment, addresses []string,
+) ([]*Account, error) {
+	var resp Response[contextualResult[[]*Account]]
+	params := multipleAccountsParams(commitment, addresses)
+	if err := getResponse(ctx, c, "getMultipleAccounts", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Result.Value, nil
+}
+
+// multipleAccountsParams returns the getMultipleAccounts params for the provided addresses, which request an empty
+// slice of the account data, as only the account metadata is used.
+func multipleAccountsParams(commitment Commitment, addresses []string) []any {
+	config := map[string]any{
+		"commitment": string(commitment),
+		"encoding":   "base64",
+		"dataSlice":  map[string]int{"offset": 0, "length": 0},
+	}
+	return []any{addresses, config}
+}
+
+// GetLargestAccounts returns the (up to 20) largest accounts by lamport balance, in descending order. Note that the
+// results may be cached by the node for up to two hours.
+// See API docs: https://solana.com/docs/rpc/http/getlargestaccounts
+func (c *Client) GetLargestAccounts(ctx context.Context, commitment Commitment) ([]LargestAccount, error) {
+	config := map[string]string{"commitment": string(commitment)}
+	var resp Response[contextualResult[[]LargestAccount]]
+	if err := getResponse(ctx, c, "getLargestAccounts", []any{config}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Result.Value, nil
+}
+
+// GetStakeActivation returns the epoch activation information for the stake account of provided pubkey.
+// See API docs: https://solana.com/docs/rpc/deprecated/getstakeactivation
+func (c *Client) GetStakeActivation(
+	ctx context.Context, commitment Commitment, address string,
+) (*StakeActivation, error) {
+	config := map[string]string{"commitment": string(commitment)}
+	var resp Response[StakeActivation]
+	if err := getResponse(ctx, c, "getStakeActivation", []any{address, config}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Result, nil
+}
+
+// GetTokenAccountBalance returns the token balance of the SPL token account of provided pubkey.
+// See API docs: https://solana.com/docs/rpc/http/gettokenaccountbalance
+func (c *Client) GetTokenAccountBalance(
+	ctx context.Context, commitment Commitment, address string,
+) (*TokenAmount, error) {
+	config := map[string]string{"commitment": string(commitment)}
+	var resp Response[contextualResult[TokenAmount]]
+	if err := getResponse(ctx, c, "getTokenAccountBalance", []any{address, config}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Result.Value, nil
+}
+
+// GetTokenAccountMint returns the mint of the SPL token account of provided pubkey, using the jsonParsed encoding of
+// getAccountInfo.
+// See API docs: https://solana.com/docs/rpc/http/getaccountinfo
+func (c *Client) GetTokenAccountMint(ctx context.Context, commitment Commitment, address string) (string, error) {
+	config := map[string]string{"commitment": string(commitment), "encoding": "jsonParsed"}
+	var resp Response[contextualResult[*TokenAccountInfo]]
+	if err := getResponse(ctx, c, "getAccountInfo", []any{address, config}, &resp); err != nil {
+		return "", err
+	}
+	if resp.Result.Value == nil {
+		return "", fmt.Errorf("account %s not found", address)
+	}
+	mint := resp.Result.Value.Data.Parsed.Info.Mint
+	if mint == "" {
+		return "", fmt.Errorf("account %s is not a token account", address)
+	}
+	return mint, nil
+}
+
+// GetAccountInfo returns the account of provided pubkey, with its data in the jsonParsed encoding, or nil if it does
+// not exist.
+// See API docs: https://solana.com/docs/rpc/http/getaccountinfo
+func (c *Client) GetAccountInfo(ctx context.Context, commitment Commitment, address string) (*AccountInfo, error) {
+	config := map[string]string{"commitment": string(commitment), "encoding": "jsonParsed"}
+	var resp Response[contextualResult[*AccountInfo]]
+	if err := getResponse(ctx, c, "getAccountInfo", []any{address, config}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Result.Value, nil
+}
+
+// GetProgramAccounts returns all the accounts owned by the provided program which match all the provided filters,
+// with their data in the jsonParsed encoding.
+// See API docs: https://solana.com/docs/rpc/http/getprogramaccounts
+func (c *Client) GetProgramAccounts(
+	ctx context.Context, commitment Commitment, program string, filters ...ProgramAccountsFilter,
+) ([]ProgramAccount, error) {
+	config := map[string]any{"commitment": string(commitment), "encoding": "jsonParsed"}
+	if len(filters) > 0 {
+		config["filters"] = filters
+	}
+	var resp Response[[]ProgramAccount]
+	if err := getResponse(ctx, c, "getProgramAccounts", []any{program, config}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Result, nil
+}
+
 // GetInflationReward returns the inflation / staking reward for a list of addresses for an epoch.
 // See API docs: https://solana.com/docs/rpc/http/getinflationreward
 func (c *Client) GetInflationReward(
@@ -235,6 +677,48 @@ func (c *Client) GetLeaderSchedule(ctx context.Context, commitment Commitment, s
 	return resp.Result, nil
 }
 
+// GetSlotLeaders returns the leaders (identities) of the limit slots starting at startSlot.
+// See API docs: https://solana.com/docs/rpc/http/getslotleaders
+func (c *Client) GetSlotLeaders(ctx context.Context, startSlot int64, limit int64) ([]string, error) {
+	var resp Response[[]string]
+	if err := getResponse(ctx, c, "getSlotLeaders", []any{startSlot, limit}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Result, nil
+}
+
+// GetEpochSchedule returns the epoch schedule information from the cluster's genesis config.
+// See API docs: https://solana.com/docs/rpc/http/getepochschedule
+func (c *Client) GetEpochSchedule(ctx context.Context) (*EpochSchedule, error) {
+	var resp Response[EpochSchedule]
+	if err := getResponse(ctx, c, "getEpochSchedule", []any{}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Result, nil
+}
+
+// GetRecentPerformanceSamples returns the limit most recent performance samples, newest first. Samples are taken
+// every 60 seconds.
+// See API docs: https://solana.com/docs/rpc/http/getrecentperformancesamples
+func (c *Client) GetRecentPerformanceSamples(ctx context.Context, limit int64) ([]PerformanceSample, error) {
+	var resp Response[[]PerformanceSample]
+	if err := getResponse(ctx, c, "getRecentPerformanceSamples", []any{limit}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Result, nil
+}
+
+// GetTransactionCount returns the number of transactions in the ledger since genesis, including failed ones.
+// See API docs: https://solana.com/docs/rpc/http/gettransactioncount
+func (c *Client) GetTransactionCount(ctx context.Context, commitment Commitment) (int64, error) {
+	config := map[string]string{"commitment": string(commitment)}
+	var resp Response[int64]
+	if err := getResponse(ctx, c, "getTransactionCount", []any{config}, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Result, nil
+}
+
 // GetBlock returns identity and transaction information about a confirmed block in the ledger.
 // See API docs: https://solana.com/docs/rpc/http/getblock
 func (c *Client) GetBlock(
@@ -295,6 +779,28 @@ func (c *Client) GetFirstAvailableBlock(ctx context.Context) (int64, error) {
 	return resp.Result, nil
 }
 
+// GetHighestSnapshotSlot returns the highest slots of the full and incremental snapshots of the node. Nodes without
+// any snapshot return a NoSnapshotCode error.
+// See API docs: https://solana.com/docs/rpc/http/gethighestsnapshotslot
+func (c *Client) GetHighestSnapshotSlot(ctx context.Context) (*HighestSnapshotSlot, error) {
+	var resp Response[HighestSnapshotSlot]
+	if err := getResponse(ctx, c, "getHighestSnapshotSlot", []any{}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Result, nil
+}
+
+// GetBlockTime returns the estimated production time of the block at the provided slot, as a unix timestamp. The
+// returned time is nil if it is not available (e.g., because the slot was skipped).
+// See API docs: https://solana.com/docs/rpc/http/getblocktime
+func (c *Client) GetBlockTime(ctx context.Context, slot int64) (*int64, error) {
+	var resp Response[*int64]
+	if err := getResponse(ctx, c, "getBlockTime", []any{slot}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Result, nil
+}
+
 // GetGenesisHash returns the hash of the genesis block
 // See API docs: https://solana.com/docs/rpc/http/getgenesishash
 func (c *Client) GetGenesisHash(ctx context.Context) (string, error) {
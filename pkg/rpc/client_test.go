@@ -1,9 +1,19 @@
 package rpc
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,6 +26,89 @@ func newMethodTester(t *testing.T, method string, result any, err *Error) (*Mock
 	return NewMockClient(t, map[string]any{method: result}, errs, nil, nil, nil, nil)
 }
 
+func TestClient_MethodTimeouts(t *testing.T) {
+	server, client := NewMockClient(t, map[string]any{"getHealth": "ok", "getSlot": 10}, nil, nil, nil, nil, nil)
+	server.SetOpt(LatencyOpt, "getHealth", 200*time.Millisecond)
+	server.SetOpt(LatencyOpt, "getSlot", 200*time.Millisecond)
+	client.MethodTimeouts = map[string]time.Duration{"getHealth": 50 * time.Millisecond}
+	assert.Equal(t, 50*time.Millisecond, client.timeout("getHealth"))
+	assert.Equal(t, client.HttpTimeout, client.timeout("getSlot"))
+
+	// the overridden method times out:
+	_, err := client.GetHealth(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// whereas the others still use the (longer) default timeout:
+	slot, err := client.GetSlot(context.Background(), CommitmentConfirmed)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), slot)
+}
+
+func TestClient_Headers(t *testing.T) {
+	server, client := newMethodTester(t, "getHealth", "ok", nil)
+	client.Headers = http.Header{"X-Api-Key": {"secret"}, "Authorization": {"Bearer token"}}
+
+	_, err := client.GetHealth(context.Background())
+	assert.NoError(t, err)
+	headers := server.LastHeaders("getHealth")
+	assert.Equal(t, "secret", headers.Get("X-Api-Key"))
+	assert.Equal(t, "Bearer token", headers.Get("Authorization"))
+	assert.Equal(t, "application/json", headers.Get("Content-Type"))
+}
+
+func TestClient_Gzip(t *testing.T) {
+	version := Version{SolanaCore: strings.Repeat("2.0.0-", 100), FeatureSet: 3294202862}
+	server, client := newMethodTester(t, "getVersion", version, nil)
+	server.SetOpt(GzipOpt, nil, true)
+	client.HttpClient.Transport = NewTransport(TransportConfig{MaxIdleConnsPerHost: DefaultMaxIdleConnsPerHost})
+
+	// the histogram is global, so only compare the increments:
+	observedBytes := func() float64 {
+		metric := &dto.Metric{}
+		assert.NoError(t, ResponseBytes.WithLabelValues("getVersion").(prometheus.Metric).Write(metric))
+		return metric.GetHistogram().GetSampleSum()
+	}
+
+	// without -rpc-gzip, compression is not requested:
+	before := observedBytes()
+	result, err := client.GetVersion(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, version, *result)
+	assert.Empty(t, server.LastHeaders("getVersion").Get("Accept-Encoding"))
+	plainBytes := observedBytes() - before
+
+	client.Gzip = true
+	before = observedBytes()
+	result, err = client.GetVersion(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, version, *result)
+	assert.Equal(t, "gzip", server.LastHeaders("getVersion").Get("Accept-Encoding"))
+	// the decompressed size is observed:
+	assert.Equal(t, plainBytes, observedBytes()-before)
+}
+
+func TestClient_GzipCorrupt(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write([]byte(`{"jsonrpc":"2.0","result":"ok","id":1}`))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	// the trailer of a gzip stream is the CRC-32 of the data, followed by its size:
+	corrupt := compressed.Bytes()
+	corrupt[len(corrupt)-8] ^= 0xff
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(corrupt)
+	}))
+	defer server.Close()
+	client := NewRPCClient(server.URL, time.Second, 0)
+	client.Gzip = true
+
+	_, err = client.GetHealth(context.Background())
+	assert.ErrorIs(t, err, gzip.ErrChecksum)
+}
+
 func TestClient_GetBalance(t *testing.T) {
 	_, client := newMethodTester(t,
 		"getBalance",
@@ -30,6 +123,391 @@ func TestClient_GetBalance(t *testing.T) {
 	assert.Equal(t, float64(5), balance)
 }
 
+func TestClient_GetMinimumBalanceForRentExemption(t *testing.T) {
+	server, client := NewMockClient(t, nil, nil, nil, nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for dataSize, expected := range map[int64]int64{0: 890_880, 165: 2_039_280} {
+		minimum, err := client.GetMinimumBalanceForRentExemption(ctx, CommitmentFinalized, dataSize)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, minimum)
+		assert.Equal(
+			t,
+			[]any{float64(dataSize), map[string]any{"commitment": "finalized"}},
+			server.LastParams("getMinimumBalanceForRentExemption"),
+		)
+	}
+}
+
+func TestClient_GetStakeMinimumDelegation(t *testing.T) {
+	server, client := newMethodTester(
+		t,
+		"getStakeMinimumDelegation",
+		map[string]any{"context": map[string]int{"slot": 1}, "value": LamportsInSol / 2},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	minimum, err := client.GetStakeMinimumDelegation(ctx, CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, minimum)
+	assert.Equal(
+		t, []any{map[string]any{"commitment": string(CommitmentFinalized)}}, server.LastParams("getStakeMinimumDelegation"),
+	)
+}
+
+func TestClient_GetBalances(t *testing.T) {
+	// enough addresses for 3 getMultipleAccounts calls, the last of which do not exist:
+	balances := make(map[string]int)
+	expected := make(map[string]float64)
+	var addresses []string
+	for i := 0; i < 2*MaxMultipleAccounts+50; i++ {
+		address := fmt.Sprintf("address%d", i)
+		addresses = append(addresses, address)
+		expected[address] = 0
+		if i < 2*MaxMultipleAccounts+40 {
+			balances[address] = i * LamportsInSol
+			expected[address] = float64(i)
+		}
+	}
+	server, client := NewMockClient(t, nil, nil, balances, nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// all the balances are fetched in a single round trip:
+	fetched, err := client.GetBalances(ctx, CommitmentConfirmed, addresses)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, fetched)
+	assert.Equal(t, 1, server.RequestCount())
+	assert.Equal(t, 3, server.CallCount("getMultipleAccounts"))
+	assert.Equal(t, "address249", server.LastParams("getMultipleAccounts")[0].([]any)[49])
+
+	// and one chunk at a time once the rpc rejects batches, which are then no longer tried:
+	server.SetOpt(BatchesDisabledOpt, nil, true)
+	for i := 0; i < 2; i++ {
+		fetched, err = client.GetBalances(ctx, CommitmentConfirmed, addresses)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, fetched)
+	}
+	assert.Equal(t, 1+1+2*3, server.RequestCount())
+}
+
+func TestClient_GetBalances_TransientBatchFailure(t *testing.T) {
+	var addresses []string
+	for i := 0; i < MaxMultipleAccounts+1; i++ {
+		addresses = append(addresses, fmt.Sprintf("address%d", i))
+	}
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "proxy error", status: http.StatusBadGateway, body: "<html>502 Bad Gateway</html>"},
+		{name: "rate limit", status: http.StatusTooManyRequests, body: `{"error":{"code":429,"message":"Too many"}}`},
+		{
+			name:   "node unhealthy",
+			status: http.StatusOK,
+			body:   `{"jsonrpc":"2.0","error":{"code":-32005,"message":"Node is unhealthy"},"id":null}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+			client := NewRPCClient(server.URL, time.Second, 0)
+
+			// the failure is returned, but batches are still tried on the next call:
+			_, err := client.GetBalances(context.Background(), CommitmentConfirmed, addresses)
+			assert.Error(t, err)
+			assert.NotErrorIs(t, err, ErrBatchNotSupported)
+			assert.False(t, client.batchNotSupported.Load())
+		})
+	}
+}
+
+func TestClient_GetMultipleAccounts(t *testing.T) {
+	server, client := NewMockClient(t, nil, nil, map[string]int{"aaa": 5 * LamportsInSol}, nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// bbb does not exist:
+	accounts, err := client.GetMultipleAccounts(ctx, CommitmentFinalized, []string{"aaa", "bbb"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*Account{{Lamports: 5 * LamportsInSol, Owner: SystemProgram}, nil}, accounts)
+	assert.Equal(
+		t,
+		[]any{
+			[]any{"aaa", "bbb"},
+			map[string]any{
+				"commitment": string(CommitmentFinalized),
+				"encoding":   "base64",
+				"dataSlice":  map[string]any{"offset": float64(0), "length": float64(0)},
+			},
+		},
+		server.LastParams("getMultipleAccounts"),
+	)
+}
+
+func TestClient_GetStakeActivation(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getStakeActivation",
+		map[string]any{"state": "activating", "active": 1 * LamportsInSol, "inactive": 2 * LamportsInSol},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	activation, err := client.GetStakeActivation(ctx, CommitmentFinalized, "")
+	assert.NoError(t, err)
+	assert.Equal(
+		t, &StakeActivation{State: StakeStateActivating, Active: LamportsInSol, Inactive: 2 * LamportsInSol}, activation,
+	)
+}
+
+func TestClient_GetTokenAccountBalance(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getTokenAccountBalance",
+		map[string]any{
+			"context": map[string]int{"slot": 1},
+			"value": map[string]any{
+				"amount": "9864", "decimals": 2, "uiAmount": 98.64, "uiAmountString": "98.64",
+			},
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	balance, err := client.GetTokenAccountBalance(ctx, CommitmentFinalized, "")
+	assert.NoError(t, err)
+	assert.Equal(t, &TokenAmount{Amount: "9864", Decimals: 2}, balance)
+	amount, err := balance.UiAmount()
+	assert.NoError(t, err)
+	assert.Equal(t, 98.64, amount)
+}
+
+func TestClient_GetTokenAccountMint(t *testing.T) {
+	server, client := newMethodTester(t,
+		"getAccountInfo",
+		map[string]any{
+			"context": map[string]int{"slot": 1},
+			"value": map[string]any{
+				"data": map[string]any{
+					"parsed": map[string]any{
+						"info": map[string]any{"mint": "mint", "owner": "owner", "state": "initialized"},
+						"type": "account",
+					},
+					"program": "spl-token",
+				},
+			},
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mint, err := client.GetTokenAccountMint(ctx, CommitmentFinalized, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "mint", mint)
+
+	// accounts which do not exist:
+	server.SetOpt(EasyResultsOpt, "getAccountInfo", map[string]any{"context": map[string]int{"slot": 1}, "value": nil})
+	_, err = client.GetTokenAccountMint(ctx, CommitmentFinalized, "")
+	assert.Error(t, err)
+}
+
+func TestClient_GetAccountInfo(t *testing.T) {
+	// the ProgramData account of a program, as returned by mainnet rpc nodes (with its program bytes trimmed):
+	var programData any
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"context": {"apiVersion": "2.2.14", "slot": 345000000},
+		"value": {
+			"data": {
+				"parsed": {
+					"info": {
+						"authority": "9nUvyH9tbMFQ1hx3bfkDcbbV6aNT6Vh3jKpRjgkP4Zz2",
+						"data": ["f0VMRgIBAQAAAAAAAAAAAA==", "base64"],
+						"slot": 312345678
+					},
+					"type": "programData"
+				},
+				"program": "bpf-upgradeable-loader",
+				"space": 1259741
+			},
+			"executable": false,
+			"lamports": 8768837760,
+			"owner": "BPFLoaderUpgradeab1e11111111111111111111111",
+			"rentEpoch": 18446744073709551615,
+			"space": 1259741
+		}
+	}`), &programData))
+	server, client := newMethodTester(t, "getAccountInfo", programData, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	account, err := client.GetAccountInfo(ctx, CommitmentFinalized, "programData")
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]any{"programData", map[string]any{"commitment": "finalized", "encoding": "jsonParsed"}},
+		server.LastParams("getAccountInfo"),
+	)
+	assert.Equal(t, int64(1259741), account.Space)
+	state, err := account.UpgradeableLoaderState()
+	assert.NoError(t, err)
+	authority := "9nUvyH9tbMFQ1hx3bfkDcbbV6aNT6Vh3jKpRjgkP4Zz2"
+	assert.Equal(t, &UpgradeableLoaderState{Type: "programData", Slot: 312345678, Authority: &authority}, state)
+
+	// immutable programs have no upgrade authority:
+	account.Data = json.RawMessage(`{"parsed": {"info": {"authority": null, "slot": 5}, "type": "programData"}}`)
+	state, err = account.UpgradeableLoaderState()
+	assert.NoError(t, err)
+	assert.Equal(t, &UpgradeableLoaderState{Type: "programData", Slot: 5}, state)
+
+	// programs point to their ProgramData account:
+	account.Data = json.RawMessage(`{"parsed": {"info": {"programData": "programData"}, "type": "program"}}`)
+	state, err = account.UpgradeableLoaderState()
+	assert.NoError(t, err)
+	assert.Equal(t, &UpgradeableLoaderState{Type: "program", ProgramData: "programData"}, state)
+
+	// data which the rpc did not parse is not decoded:
+	account.Data = json.RawMessage(`["AwAAAA==", "base64"]`)
+	_, err = account.UpgradeableLoaderState()
+	assert.Error(t, err)
+	// and neither are accounts of other programs:
+	account.Owner = SystemProgram
+	_, err = account.UpgradeableLoaderState()
+	assert.Error(t, err)
+
+	// accounts which do not exist are nil:
+	server.SetOpt(EasyResultsOpt, "getAccountInfo", map[string]any{"context": map[string]int{"slot": 1}, "value": nil})
+	account, err = client.GetAccountInfo(ctx, CommitmentFinalized, "missing")
+	assert.NoError(t, err)
+	assert.Nil(t, account)
+}
+
+func TestClient_GetProgramAccounts(t *testing.T) {
+	server, client := newMethodTester(t,
+		"getProgramAccounts",
+		[]map[string]any{
+			{
+				"pubkey": "aaa",
+				"account": map[string]any{
+					"lamports": 3 * LamportsInSol,
+					"owner":    StakeProgram,
+					"data": map[string]any{
+						"parsed": map[string]any{
+							"info": map[string]any{
+								"stake": map[string]any{"delegation": map[string]any{"stake": "2000000000"}},
+							},
+							"type": "delegated",
+						},
+					},
+				},
+			},
+			{
+				"pubkey": "bbb",
+				"account": map[string]any{
+					"lamports": 1 * LamportsInSol,
+					"owner":    StakeProgram,
+					"data":     map[string]any{"parsed": map[string]any{"info": map[string]any{}, "type": "initialized"}},
+				},
+			},
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	accounts, err := client.GetProgramAccounts(
+		ctx, CommitmentFinalized, StakeProgram,
+		ProgramAccountsFilter{DataSize: StakeAccountSize},
+		ProgramAccountsFilter{Memcmp: &MemcmpFilter{Offset: StakeWithdrawerOffset, Bytes: "withdrawer"}},
+	)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]any{
+			StakeProgram,
+			map[string]any{
+				"commitment": string(CommitmentFinalized),
+				"encoding":   "jsonParsed",
+				"filters": []any{
+					map[string]any{"dataSize": float64(StakeAccountSize)},
+					map[string]any{"memcmp": map[string]any{"offset": float64(StakeWithdrawerOffset), "bytes": "withdrawer"}},
+				},
+			},
+		},
+		server.LastParams("getProgramAccounts"),
+	)
+	assert.Len(t, accounts, 2)
+	assert.Equal(t, "aaa", accounts[0].Pubkey)
+	assert.Equal(t, int64(3*LamportsInSol), accounts[0].Account.Lamports)
+
+	stake, err := accounts[0].DelegatedStake()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2*LamportsInSol), stake)
+	stake, err = accounts[1].DelegatedStake()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), stake)
+}
+
+func TestClient_GetLargestAccounts(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getLargestAccounts",
+		map[string]any{
+			"context": map[string]int{"slot": 1},
+			"value": []map[string]any{
+				{"address": "aaa", "lamports": 3 * LamportsInSol},
+				{"address": "bbb", "lamports": 2 * LamportsInSol},
+			},
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	accounts, err := client.GetLargestAccounts(ctx, CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		[]LargestAccount{{Address: "aaa", Lamports: 3 * LamportsInSol}, {Address: "bbb", Lamports: 2 * LamportsInSol}},
+		accounts,
+	)
+}
+
+func TestClient_GetHighestSnapshotSlot(t *testing.T) {
+	t.Run("snapshots", func(t *testing.T) {
+		_, client := newMethodTester(t,
+			"getHighestSnapshotSlot",
+			map[string]any{"full": 100, "incremental": 110},
+			nil,
+		)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		snapshotSlot, err := client.GetHighestSnapshotSlot(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(100), snapshotSlot.Full)
+		assert.Equal(t, int64(110), *snapshotSlot.Incremental)
+	})
+
+	t.Run("no-snapshot", func(t *testing.T) {
+		noSnapshotErr := Error{Code: NoSnapshotCode, Message: "No snapshot", Method: "getHighestSnapshotSlot"}
+		_, client := newMethodTester(t, "getHighestSnapshotSlot", nil, &noSnapshotErr)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		snapshotSlot, err := client.GetHighestSnapshotSlot(ctx)
+		assert.Nil(t, snapshotSlot)
+		assert.Equal(t, &noSnapshotErr, err)
+	})
+}
+
 func TestClient_GetBlock(t *testing.T) {
 	_, client := newMethodTester(t,
 		"getBlock",
@@ -127,6 +605,78 @@ func TestClient_GetEpochInfo(t *testing.T) {
 	)
 }
 
+func TestClient_GetEpochSchedule(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getEpochSchedule",
+		map[string]any{
+			"firstNormalEpoch":         8,
+			"firstNormalSlot":          8160,
+			"leaderScheduleSlotOffset": 8192,
+			"slotsPerEpoch":            8192,
+			"warmup":                   true,
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	schedule, err := client.GetEpochSchedule(ctx)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		&EpochSchedule{
+			SlotsPerEpoch:            8192,
+			LeaderScheduleSlotOffset: 8192,
+			Warmup:                   true,
+			FirstNormalEpoch:         8,
+			FirstNormalSlot:          8160,
+		},
+		schedule,
+	)
+
+	tests := []struct {
+		slot      int64
+		remaining int64
+		epoch     int64
+		firstSlot int64
+	}{
+		// warmup epochs of 32, 64, 128, ... slots:
+		{0, 32, 0, 0},
+		{31, 1, 0, 0},
+		{32, 64, 1, 32},
+		{100, 124, 2, 96},
+		// normal epochs:
+		{8160, 8192, 8, 8160},
+		{8161 + 8192, 8191, 9, 8160 + 8192},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.remaining, schedule.SlotsRemaining(test.slot), test.slot)
+		assert.Equal(t, test.epoch, schedule.Epoch(test.slot), test.slot)
+		assert.Equal(t, test.firstSlot, schedule.FirstSlot(test.slot), test.slot)
+	}
+}
+
+func TestClient_GetRecentPerformanceSamples(t *testing.T) {
+	server, client := newMethodTester(t,
+		"getRecentPerformanceSamples",
+		[]map[string]any{
+			{"numSlots": 126, "numTransactions": 126, "samplePeriodSecs": 60, "slot": 348125},
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	samples, err := client.GetRecentPerformanceSamples(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]PerformanceSample{{Slot: 348125, NumTransactions: 126, NumSlots: 126, SamplePeriodSecs: 60}},
+		samples,
+	)
+	assert.Equal(t, []any{float64(1)}, server.LastParams("getRecentPerformanceSamples"))
+}
+
 func TestClient_GetFirstAvailableBlock(t *testing.T) {
 	_, client := newMethodTester(t, "getFirstAvailableBlock", 250_000, nil)
 	ctx, cancel := context.WithCancel(context.Background())
@@ -240,6 +790,40 @@ func TestClient_GetSlot(t *testing.T) {
 	assert.Equal(t, int64(1234), slot)
 }
 
+func TestClient_GetMaxShredInsertSlot(t *testing.T) {
+	_, client := newMethodTester(t, "getMaxShredInsertSlot", 1236, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	slot, err := client.GetMaxShredInsertSlot(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1236), slot)
+}
+
+func TestClient_GetTransactionCount(t *testing.T) {
+	server, client := newMethodTester(t, "getTransactionCount", 268, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	count, err := client.GetTransactionCount(ctx, CommitmentConfirmed)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(268), count)
+	assert.Equal(
+		t, []any{map[string]any{"commitment": string(CommitmentConfirmed)}}, server.LastParams("getTransactionCount"),
+	)
+}
+
+func TestClient_GetSlotLeaders(t *testing.T) {
+	server, client := newMethodTester(t, "getSlotLeaders", []string{"aaa", "aaa", "bbb"}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leaders, err := client.GetSlotLeaders(ctx, 100, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"aaa", "aaa", "bbb"}, leaders)
+	assert.Equal(t, []any{float64(100), float64(3)}, server.LastParams("getSlotLeaders"))
+}
+
 func TestClient_GetVersion(t *testing.T) {
 	expectedResult := map[string]any{"feature-set": 2891131721, "solana-core": "1.16.7"}
 	_, client := newMethodTester(t, "getVersion", expectedResult, nil)
@@ -248,7 +832,7 @@ func TestClient_GetVersion(t *testing.T) {
 
 	version, err := client.GetVersion(ctx)
 	assert.NoError(t, err)
-	assert.Equal(t, expectedResult["solana-core"], version)
+	assert.Equal(t, &Version{SolanaCore: "1.16.7", FeatureSet: 2891131721}, version)
 }
 
 func TestClient_GetVoteAccounts(t *testing.T) {
@@ -257,7 +841,7 @@ func TestClient_GetVoteAccounts(t *testing.T) {
 		map[string]any{
 			"current": []map[string]any{
 				{
-					"commission":       0,
+					"commission":       5,
 					"epochVoteAccount": true,
 					"epochCredits":     [][]int{{1, 64, 0}, {2, 192, 64}},
 					"nodePubkey":       "B97CCUW3AEZFGy6uUg6zUdnNYvnVq5VG8PUtb2HayTDD",
@@ -273,7 +857,7 @@ func TestClient_GetVoteAccounts(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	voteAccounts, err := client.GetVoteAccounts(ctx, CommitmentFinalized)
+	voteAccounts, err := client.GetVoteAccounts(ctx, CommitmentFinalized, "")
 	assert.NoError(t, err)
 	assert.Equal(t,
 		&VoteAccounts{
@@ -283,11 +867,93 @@ func TestClient_GetVoteAccounts(t *testing.T) {
 					LastVote:       147,
 					ActivatedStake: 42,
 					VotePubkey:     "3ZT31jkAGhUaw8jsy4bTknwBMP8i4Eueh52By4zXcsVw",
+					Commission:     5,
+					EpochCredits:   [][3]int64{{1, 64, 0}, {2, 192, 64}},
 				},
 			},
 		},
 		voteAccounts,
 	)
+	assert.Equal(t, int64(128), voteAccounts.Current[0].LatestEpochCredits())
+}
+
+func TestClient_GetLatestBlockhash(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getLatestBlockhash",
+		map[string]any{
+			"context": map[string]int{"slot": 2792},
+			"value":   map[string]any{"blockhash": "EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N", "lastValidBlockHeight": 3090},
+		},
+		nil,
+	)
+	blockhash, err := client.GetLatestBlockhash(context.Background(), CommitmentConfirmed)
+	assert.NoError(t, err)
+	assert.Equal(t, "EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N", blockhash)
+}
+
+func TestClient_SendTransaction(t *testing.T) {
+	server, client := newMethodTester(t, "sendTransaction", "5VERv8NMvzbJMEkV8xnrLkEaWRtSz9CosKDYjCJjBRnb", nil)
+	signature, err := client.SendTransaction(context.Background(), CommitmentConfirmed, []byte{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, "5VERv8NMvzbJMEkV8xnrLkEaWRtSz9CosKDYjCJjBRnb", signature)
+	assert.Equal(
+		t,
+		[]any{"AQID", map[string]any{"encoding": "base64", "preflightCommitment": "confirmed"}},
+		server.LastParams("sendTransaction"),
+	)
+}
+
+func TestClient_GetSignatureStatuses(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getSignatureStatuses",
+		map[string]any{
+			"context": map[string]int{"slot": 82},
+			"value": []any{
+				map[string]any{"slot": 72, "confirmations": 10, "err": nil, "confirmationStatus": "confirmed"},
+				nil,
+			},
+		},
+		nil,
+	)
+	statuses, err := client.GetSignatureStatuses(context.Background(), []string{"aaa", "bbb"})
+	assert.NoError(t, err)
+	confirmations := int64(10)
+	assert.Equal(
+		t,
+		[]*SignatureStatus{
+			{Slot: 72, Confirmations: &confirmations, ConfirmationStatus: CommitmentConfirmed},
+			nil,
+		},
+		statuses,
+	)
+}
+
+func TestClient_GetClusterNodes(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getClusterNodes",
+		[]map[string]any{
+			{
+				"pubkey": "aaa", "gossip": "10.0.0.1:8001", "tpuQuic": "10.0.0.1:8009", "version": "2.2.14",
+				"featureSet": 3294202862,
+			},
+			{"pubkey": "bbb", "gossip": nil, "tpu": nil, "rpc": nil, "version": nil, "featureSet": nil},
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nodes, err := client.GetClusterNodes(ctx)
+	assert.NoError(t, err)
+	gossip, tpuQuic, version, featureSet := "10.0.0.1:8001", "10.0.0.1:8009", "2.2.14", int64(3294202862)
+	assert.Equal(
+		t,
+		[]ContactInfo{
+			{Pubkey: "aaa", Gossip: &gossip, TpuQuic: &tpuQuic, Version: &version, FeatureSet: &featureSet},
+			{Pubkey: "bbb"},
+		},
+		nodes,
+	)
 }
 
 func TestClient_GetIdentity(t *testing.T) {
@@ -302,3 +968,42 @@ func TestClient_GetIdentity(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "random2r1F4iWqVcb8M1DbAjQuFpebkQuW2DJtestkey", identity)
 }
+
+func TestClient_GetBlockTime(t *testing.T) {
+	_, client := NewMockClient(t,
+		nil,
+		nil,
+		nil,
+		nil,
+		map[int]MockSlotInfo{
+			10: {Leader: "aaa", Block: &MockBlockInfo{BlockTime: 1_700_000_000}},
+			11: {Leader: "aaa", Block: nil},
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blockTime, err := client.GetBlockTime(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1_700_000_000), *blockTime)
+
+	// skipped slots have a null block time:
+	blockTime, err = client.GetBlockTime(ctx, 11)
+	assert.NoError(t, err)
+	assert.Nil(t, blockTime)
+}
+
+func TestGetClusterFromGenesisHash(t *testing.T) {
+	for hash, expected := range map[string]string{
+		MainnetGenesisHash: "mainnet-beta",
+		TestnetGenesisHash: "testnet",
+		DevnetGenesisHash:  "devnet",
+	} {
+		cluster, err := GetClusterFromGenesisHash(hash)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, cluster)
+	}
+	_, err := GetClusterFromGenesisHash("11111111111111111111111111111111")
+	assert.Error(t, err)
+}
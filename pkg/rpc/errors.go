@@ -2,6 +2,7 @@ package rpc
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 )
 
@@ -27,12 +28,28 @@ const (
 	SlotNotEpochBoundaryCode                     = -32018
 )
 
+// standard JSON-RPC error codes: https://www.jsonrpc.org/specification#error_object
+const (
+	InvalidRequestCode = -32600
+	MethodNotFoundCode = -32601
+)
+
 type (
 	NodeUnhealthyErrorData struct {
 		NumSlotsBehind int64 `json:"numSlotsBehind"`
 	}
 )
 
+// IsSkippedSlotError returns whether err is the rpc error returned for slots which were skipped (i.e., have no block),
+// whether recently or in long-term storage.
+func IsSkippedSlotError(err error) bool {
+	var rpcError *Error
+	if !errors.As(err, &rpcError) {
+		return false
+	}
+	return rpcError.Code == SlotSkippedCode || rpcError.Code == LongTermStorageSlotSkippedCode
+}
+
 func UnpackRpcErrorData[T any](rpcErr *Error, formatted T) error {
 	bytesData, err := json.Marshal(rpcErr.Data)
 	if err != nil {
@@ -0,0 +1,20 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSkippedSlotError(t *testing.T) {
+	assert.True(t, IsSkippedSlotError(&Error{Code: SlotSkippedCode}))
+	assert.True(t, IsSkippedSlotError(&Error{Code: LongTermStorageSlotSkippedCode}))
+	// wrapped errors are unwrapped:
+	assert.True(t, IsSkippedSlotError(fmt.Errorf("getBlock failed: %w", &Error{Code: SlotSkippedCode})))
+
+	assert.False(t, IsSkippedSlotError(&Error{Code: BlockNotAvailableCode}))
+	assert.False(t, IsSkippedSlotError(errors.New("slot skipped")))
+	assert.False(t, IsSkippedSlotError(nil))
+}
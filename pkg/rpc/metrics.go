@@ -0,0 +1,128 @@
+package rpc
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// ErrorCodeTransport is the code label of errors in making the request or reading the response.
+	ErrorCodeTransport = "transport"
+	// ErrorCodeDecode is the code label of responses which could not be decoded.
+	ErrorCodeDecode = "decode"
+)
+
+// DefaultMetricPrefix is the prefix of all the metric names, unless overridden (e.g., with -metric-prefix).
+const DefaultMetricPrefix = "solana_"
+
+// DefaultLatencyBuckets are the default buckets (in seconds) of LatencySeconds, from 5ms to 10s.
+var DefaultLatencyBuckets = prometheus.DefBuckets
+
+var (
+	// RequestsTotal counts the rpc requests made by all clients, per method.
+	RequestsTotal *prometheus.CounterVec
+	// ErrorsTotal counts the failed rpc requests made by all clients, per method and error code. The code is the
+	// JSON-RPC error code (see errors.go), or one of ErrorCodeTransport and ErrorCodeDecode.
+	ErrorsTotal *prometheus.CounterVec
+	// OpenConnections tracks the connections to the rpc node currently open by clients using NewTransport.
+	OpenConnections prometheus.Gauge
+	// LatencySeconds observes the latency of the rpc requests made by all clients, per method, including that of
+	// failed requests.
+	LatencySeconds *prometheus.HistogramVec
+	// ResponseBytes observes the size of the rpc responses read by all clients, per method.
+	ResponseBytes *prometheus.HistogramVec
+)
+
+// responseBytesBuckets are the buckets (in bytes) of ResponseBytes, from 256B to 64MiB.
+var responseBytesBuckets = prometheus.ExponentialBuckets(256, 4, 10)
+
+func init() {
+	SetupMetrics(DefaultMetricPrefix, DefaultLatencyBuckets)
+}
+
+// PrefixedName replaces the DefaultMetricPrefix of the provided metric name with prefix. An empty prefix leaves the
+// name unchanged.
+func PrefixedName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + strings.TrimPrefix(name, DefaultMetricPrefix)
+}
+
+// SetupMetrics replaces the rpc metrics with ones named with the provided prefix (see PrefixedName), and whose
+// latency histogram has the provided buckets (in seconds). As previous observations are discarded, it should be
+// called at startup, before the metrics are registered.
+func SetupMetrics(prefix string, latencyBuckets []float64) {
+	RequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: PrefixedName(prefix, "solana_exporter_rpc_requests_total"),
+			Help: "Total number of rpc requests made by the exporter, grouped by method",
+		},
+		[]string{"method"},
+	)
+	ErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: PrefixedName(prefix, "solana_exporter_rpc_errors_total"),
+			Help: "Total number of failed rpc requests made by the exporter, grouped by method and error code",
+		},
+		[]string{"method", "code"},
+	)
+	OpenConnections = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: PrefixedName(prefix, "solana_exporter_rpc_open_connections"),
+			Help: "Number of connections to the rpc node currently open by the exporter",
+		},
+	)
+	LatencySeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    PrefixedName(prefix, "solana_exporter_rpc_latency_seconds"),
+			Help:    "Latency of the rpc requests made by the exporter, grouped by method",
+			Buckets: latencyBuckets,
+		},
+		[]string{"method"},
+	)
+	ResponseBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    PrefixedName(prefix, "solana_exporter_rpc_response_bytes"),
+			Help:    "Size in bytes of the rpc responses read by the exporter, grouped by method",
+			Buckets: responseBytesBuckets,
+		},
+		[]string{"method"},
+	)
+}
+
+// Collectors returns the rpc metrics, to be registered (or listed) along with the other metrics of the exporter.
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{RequestsTotal, ErrorsTotal, LatencySeconds, OpenConnections, ResponseBytes}
+}
+
+// observeLatency observes the time elapsed since start in the latency histogram of the provided method.
+func observeLatency(method string, start time.Time) {
+	LatencySeconds.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// countingReader wraps a reader, counting the bytes read from it.
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// recordError increments the error counter of the provided method with the provided code.
+func recordError(method string, code string) {
+	ErrorsTotal.WithLabelValues(method, code).Inc()
+}
+
+// rpcErrorCode formats a JSON-RPC error code as a label value.
+func rpcErrorCode(code int64) string {
+	return strconv.FormatInt(code, 10)
+}
@@ -0,0 +1,114 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_RequestMetrics(t *testing.T) {
+	server, client := NewMockClient(t,
+		map[string]any{"getHealth": "ok", "getSlot": 10},
+		map[string]*Error{"getIdentity": {Code: NodeUnhealthyCode, Message: "Node is unhealthy"}},
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the counters are global, so only compare the increments:
+	requests := func(method string) float64 { return testutil.ToFloat64(RequestsTotal.WithLabelValues(method)) }
+	errors := func(method, code string) float64 {
+		return testutil.ToFloat64(ErrorsTotal.WithLabelValues(method, code))
+	}
+	healthBefore, identityBefore := requests("getHealth"), requests("getIdentity")
+	unhealthyBefore := errors("getIdentity", rpcErrorCode(NodeUnhealthyCode))
+	healthErrorsBefore := errors("getHealth", ErrorCodeTransport)
+	notFoundBefore := errors("getVersion", "-32601")
+	transportBefore := errors("getSlot", ErrorCodeTransport)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.GetHealth(ctx)
+		assert.NoError(t, err)
+	}
+	for i := 0; i < 2; i++ {
+		_, err := client.GetIdentity(ctx)
+		assert.Error(t, err)
+	}
+	// unknown methods return a method-not-found error:
+	_, err := client.GetVersion(ctx)
+	assert.Error(t, err)
+	// and once the server is down, requests fail altogether:
+	assert.NoError(t, server.Close())
+	_, err = client.GetSlot(ctx, CommitmentConfirmed)
+	assert.Error(t, err)
+
+	assert.Equal(t, 3.0, requests("getHealth")-healthBefore)
+	assert.Equal(t, 2.0, requests("getIdentity")-identityBefore)
+	assert.Equal(t, 2.0, errors("getIdentity", rpcErrorCode(NodeUnhealthyCode))-unhealthyBefore)
+	assert.Equal(t, 0.0, errors("getHealth", ErrorCodeTransport)-healthErrorsBefore)
+	assert.Equal(t, 1.0, errors("getVersion", "-32601")-notFoundBefore)
+	assert.Equal(t, 1.0, errors("getSlot", ErrorCodeTransport)-transportBefore)
+}
+
+func TestPrefixedName(t *testing.T) {
+	assert.Equal(t, "solana_node_slot_height", PrefixedName("", "solana_node_slot_height"))
+	assert.Equal(t, "solana_node_slot_height", PrefixedName(DefaultMetricPrefix, "solana_node_slot_height"))
+	assert.Equal(t, "myorg_solana_node_slot_height", PrefixedName("myorg_solana_", "solana_node_slot_height"))
+}
+
+func TestClient_LatencyMetrics(t *testing.T) {
+	server, client := NewMockClient(t, map[string]any{"getHealth": "ok"}, nil, nil, nil, nil, nil)
+	defer server.Close()
+	server.SetOpt(LatencyOpt, "getHealth", 10*time.Millisecond)
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(LatencySeconds)
+	_, err := client.GetHealth(context.Background())
+	assert.NoError(t, err)
+
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+	assert.Len(t, families, 1)
+	assert.Equal(t, "solana_exporter_rpc_latency_seconds", families[0].GetName())
+	assert.Equal(t, dto.MetricType_HISTOGRAM, families[0].GetType())
+
+	var histogram *dto.Histogram
+	for _, metric := range families[0].GetMetric() {
+		if metric.GetLabel()[0].GetValue() == "getHealth" {
+			histogram = metric.GetHistogram()
+		}
+	}
+	if assert.NotNil(t, histogram) {
+		assert.Positive(t, histogram.GetSampleCount())
+		assert.GreaterOrEqual(t, histogram.GetSampleSum(), 0.01)
+		assert.Len(t, histogram.GetBucket(), len(DefaultLatencyBuckets))
+	}
+}
+
+func TestClient_ResponseBytesMetrics(t *testing.T) {
+	server, client := NewMockClient(t, map[string]any{"getHealth": "ok"}, nil, nil, nil, nil, nil)
+	defer server.Close()
+
+	// the histogram is global, so only compare the increments:
+	histogram := func() *dto.Histogram {
+		metric := &dto.Metric{}
+		observer := ResponseBytes.WithLabelValues("getHealth").(prometheus.Metric)
+		assert.NoError(t, observer.Write(metric))
+		return metric.GetHistogram()
+	}
+	before := histogram()
+	_, err := client.GetHealth(context.Background())
+	assert.NoError(t, err)
+	after := histogram()
+
+	assert.Equal(t, uint64(1), after.GetSampleCount()-before.GetSampleCount())
+	assert.Positive(t, after.GetSampleSum()-before.GetSampleSum())
+}
@@ -1,11 +1,16 @@
 package rpc
 
 import (
-	"context"
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -22,7 +27,16 @@ const (
 	EasyResultsOpt
 	SlotInfosOpt
 	ValidatorInfoOpt
-	EasyErrorsOpt = 5
+	EasyErrorsOpt      = 5
+	LatencyOpt         = 6
+	StakeActivationOpt = 7
+	TokenAccountOpt    = 8
+	StakeAccountOpt    = 9
+	// BatchesDisabledOpt makes the server reject batch requests (the key is ignored):
+	BatchesDisabledOpt = 10
+	// GzipOpt makes the server gzip its responses to requests accepting it (the key is ignored):
+	GzipOpt    = 11
+	ProgramOpt = 12
 )
 
 type (
@@ -38,13 +52,52 @@ type (
 		easyResults      map[string]any
 		easyErrors       map[string]*Error
 
-		SlotInfos      map[int]MockSlotInfo
-		validatorInfos map[string]MockValidatorInfo
+		SlotInfos        map[int]MockSlotInfo
+		validatorInfos   map[string]MockValidatorInfo
+		stakeActivations map[string]StakeActivation
+		tokenAccounts    map[string]MockTokenAccount
+		stakeAccounts    map[string]MockStakeAccount
+		programs         map[string]MockProgram
+		// latencies delays the responses to the given methods:
+		latencies map[string]time.Duration
+		// callCounts counts the requests received per method:
+		callCounts map[string]int
+		// lastParams holds the params of the last request received per method:
+		lastParams map[string][]any
+		// lastHeaders holds the headers of the last request received per method:
+		lastHeaders map[string]http.Header
+		// requestCount counts the http requests received, where a batch request counts once:
+		requestCount int
+		// batchesDisabled makes the server reject batch requests, as some rpc providers do:
+		batchesDisabled bool
+		// gzip makes the server gzip its responses to requests accepting it:
+		gzip bool
+	}
+
+	MockTokenAccount struct {
+		Mint     string
+		Amount   uint64
+		Decimals int
+	}
+
+	MockStakeAccount struct {
+		Withdrawer string
+		// DelegatedStake is nil for stake accounts which are not delegated
+		DelegatedStake *int64
+	}
+
+	// MockProgram is an upgradeable program, whose ProgramData account is at ProgramData.
+	MockProgram struct {
+		ProgramData string
+		Slot        int64
+		// Authority is nil for immutable programs
+		Authority *string
 	}
 
 	MockBlockInfo struct {
 		Fee          int
 		Transactions [][]string
+		BlockTime    int64
 	}
 
 	MockSlotInfo struct {
@@ -58,9 +111,19 @@ type (
 		LastVote   int
 		Delinquent bool
 		RootSlot   int
+		// Credits are the credits earned in the current epoch:
+		Credits    int
+		Commission int
 	}
 )
 
+// MockRentExemptMinimum returns the minimum balance (in lamports) for an account with dataSize bytes of data to be rent
+// exempt, as per the default rent of the cluster: 3480 lamports per byte-year, for 2 years, with 128 bytes of
+// account overhead.
+func MockRentExemptMinimum(dataSize int64) int64 {
+	return (128 + dataSize) * 3480 * 2
+}
+
 // NewMockServer creates a new mock server instance
 func NewMockServer(
 	easyResults map[string]any,
@@ -103,11 +166,10 @@ func (s *MockServer) URL() string {
 	return fmt.Sprintf("http://%s", s.listener.Addr().String())
 }
 
-// Close shuts down the mock server
+// Close shuts down the mock server. Connections are closed immediately, rather than gracefully, as clients making
+// concurrent requests can leave unused connections open, which would otherwise stall the shutdown.
 func (s *MockServer) Close() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	return s.server.Shutdown(ctx)
+	return s.server.Close()
 }
 
 func (s *MockServer) MustClose() {
@@ -151,6 +213,35 @@ func (s *MockServer) SetOpt(opt MockOpt, key any, value any) {
 		}
 		err := value.(Error)
 		s.easyErrors[key.(string)] = &err
+	case StakeActivationOpt:
+		if s.stakeActivations == nil {
+			s.stakeActivations = make(map[string]StakeActivation)
+		}
+		s.stakeActivations[key.(string)] = value.(StakeActivation)
+	case TokenAccountOpt:
+		if s.tokenAccounts == nil {
+			s.tokenAccounts = make(map[string]MockTokenAccount)
+		}
+		s.tokenAccounts[key.(string)] = value.(MockTokenAccount)
+	case StakeAccountOpt:
+		if s.stakeAccounts == nil {
+			s.stakeAccounts = make(map[string]MockStakeAccount)
+		}
+		s.stakeAccounts[key.(string)] = value.(MockStakeAccount)
+	case ProgramOpt:
+		if s.programs == nil {
+			s.programs = make(map[string]MockProgram)
+		}
+		s.programs[key.(string)] = value.(MockProgram)
+	case LatencyOpt:
+		if s.latencies == nil {
+			s.latencies = make(map[string]time.Duration)
+		}
+		s.latencies[key.(string)] = value.(time.Duration)
+	case BatchesDisabledOpt:
+		s.batchesDisabled = value.(bool)
+	case GzipOpt:
+		s.gzip = value.(bool)
 	}
 }
 
@@ -160,6 +251,34 @@ func (s *MockServer) GetValidatorInfo(nodekey string) MockValidatorInfo {
 	return s.validatorInfos[nodekey]
 }
 
+// CallCount returns the number of requests received for the given method.
+func (s *MockServer) CallCount(method string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.callCounts[method]
+}
+
+// RequestCount returns the number of http requests received, where a batch request counts once.
+func (s *MockServer) RequestCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.requestCount
+}
+
+// LastHeaders returns the headers of the last request received for the given method.
+func (s *MockServer) LastHeaders(method string) http.Header {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastHeaders[method]
+}
+
+// LastParams returns the params of the last request received for the given method.
+func (s *MockServer) LastParams(method string) []any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastParams[method]
+}
+
 func (s *MockServer) getResult(method string, params ...any) (any, *Error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -179,6 +298,124 @@ func (s *MockServer) getResult(method string, params ...any) (any, *Error) {
 		return result, nil
 	}
 
+	if method == "getMinimumBalanceForRentExemption" {
+		return MockRentExemptMinimum(int64(params[0].(float64))), nil
+	}
+
+	if method == "getMultipleAccounts" && s.balances != nil {
+		addresses := params[0].([]any)
+		// accounts without a balance do not exist:
+		accounts := make([]map[string]any, len(addresses))
+		for i, address := range addresses {
+			if balance, ok := s.balances[address.(string)]; ok {
+				accounts[i] = map[string]any{
+					"lamports":   balance,
+					"owner":      SystemProgram,
+					"executable": false,
+					"space":      0,
+					"data":       []string{"", "base64"},
+				}
+			}
+		}
+		result := map[string]any{
+			"context": map[string]int{"slot": 1},
+			"value":   accounts,
+		}
+		return result, nil
+	}
+
+	if method == "getStakeActivation" && s.stakeActivations != nil {
+		address := params[0].(string)
+		activation, ok := s.stakeActivations[address]
+		if !ok {
+			return nil, &Error{Code: -32602, Message: "Invalid param: account not found"}
+		}
+		return activation, nil
+	}
+
+	if method == "getTokenAccountBalance" && s.tokenAccounts != nil {
+		address := params[0].(string)
+		account, ok := s.tokenAccounts[address]
+		if !ok {
+			return nil, &Error{Code: -32602, Message: "Invalid param: could not find account"}
+		}
+		result := map[string]any{
+			"context": map[string]int{"slot": 1},
+			"value": map[string]any{
+				"amount":         strconv.FormatUint(account.Amount, 10),
+				"decimals":       account.Decimals,
+				"uiAmountString": strconv.FormatFloat(float64(account.Amount)/math.Pow10(account.Decimals), 'f', -1, 64),
+			},
+		}
+		return result, nil
+	}
+
+	if method == "getAccountInfo" && (s.tokenAccounts != nil || s.programs != nil) {
+		address := params[0].(string)
+		var value any
+		if account, ok := s.tokenAccounts[address]; ok {
+			value = map[string]any{
+				"data": map[string]any{
+					"parsed":  map[string]any{"info": map[string]any{"mint": account.Mint}, "type": "account"},
+					"program": "spl-token",
+				},
+			}
+		}
+		for programID, program := range s.programs {
+			var parsed map[string]any
+			switch address {
+			case programID:
+				parsed = map[string]any{"info": map[string]any{"programData": program.ProgramData}, "type": "program"}
+			case program.ProgramData:
+				info := map[string]any{"authority": program.Authority, "data": []string{"", "base64"}, "slot": program.Slot}
+				parsed = map[string]any{"info": info, "type": "programData"}
+			default:
+				continue
+			}
+			value = map[string]any{
+				"owner":      UpgradeableLoaderProgram,
+				"executable": address == programID,
+				"data":       map[string]any{"parsed": parsed, "program": "bpf-upgradeable-loader"},
+			}
+		}
+		return map[string]any{"context": map[string]int{"slot": 1}, "value": value}, nil
+	}
+
+	if method == "getProgramAccounts" && s.stakeAccounts != nil {
+		program := params[0].(string)
+		config := params[1].(map[string]any)
+		if program != StakeProgram {
+			return []any{}, nil
+		}
+		filters, _ := config["filters"].([]any)
+		accounts := []map[string]any{}
+		for pubkey, account := range s.stakeAccounts {
+			if !account.matches(filters) {
+				continue
+			}
+			info := map[string]any{"meta": map[string]any{"authorized": map[string]any{"withdrawer": account.Withdrawer}}}
+			accountType := "initialized"
+			if account.DelegatedStake != nil {
+				info["stake"] = map[string]any{
+					"delegation": map[string]any{"stake": strconv.FormatInt(*account.DelegatedStake, 10)},
+				}
+				accountType = "delegated"
+			}
+			accounts = append(accounts, map[string]any{
+				"pubkey": pubkey,
+				"account": map[string]any{
+					"owner": StakeProgram,
+					"data": map[string]any{
+						"parsed":  map[string]any{"info": info, "type": accountType},
+						"program": "stake",
+						"space":   StakeAccountSize,
+					},
+				},
+			})
+		}
+		return accounts, nil
+	}
+
 	if method == "getInflationReward" && s.inflationRewards != nil {
 		addresses := params[0].([]any)
 		config := params[1].(map[string]any)
@@ -228,6 +465,19 @@ func (s *MockServer) getResult(method string, params ...any) (any, *Error) {
 		return map[string]any{"rewards": rewards, "transactions": transactions}, nil
 	}
 
+	if method == "getBlockTime" && s.SlotInfos != nil {
+		slot := int(params[0].(float64))
+		slotInfo, ok := s.SlotInfos[slot]
+		if !ok {
+			return nil, &Error{Code: BlockNotAvailableCode, Message: "Block not available."}
+		}
+		if slotInfo.Block == nil {
+			// the block time of skipped slots is null:
+			return nil, nil
+		}
+		return slotInfo.Block.BlockTime, nil
+	}
+
 	if method == "getBlockProduction" && s.SlotInfos != nil {
 		// get params:
 		config := params[0].(map[string]any)
@@ -256,14 +506,21 @@ func (s *MockServer) getResult(method string, params ...any) (any, *Error) {
 	}
 
 	if method == "getVoteAccounts" && s.validatorInfos != nil {
+		config := params[0].(map[string]any)
+		votePubkey, _ := config["votePubkey"].(string)
 		var currentVoteAccounts, delinquentVoteAccounts []map[string]any
 		for nodekey, info := range s.validatorInfos {
+			if votePubkey != "" && info.Votekey != votePubkey {
+				continue
+			}
 			voteAccount := map[string]any{
 				"activatedStake": int64(info.Stake),
 				"lastVote":       info.LastVote,
 				"nodePubkey":     nodekey,
 				"rootSlot":       info.RootSlot,
 				"votePubkey":     info.Votekey,
+				"commission":     info.Commission,
+				"epochCredits":   [][]int{{0, info.Credits, 0}},
 			}
 			if info.Delinquent {
 				delinquentVoteAccounts = append(delinquentVoteAccounts, voteAccount)
@@ -286,17 +543,97 @@ func (s *MockServer) getResult(method string, params ...any) (any, *Error) {
 	return result, nil
 }
 
+// matches returns whether the stake account matches all the provided getProgramAccounts filters. Only dataSize
+// filters and memcmp filters on the withdraw authority are supported.
+func (a MockStakeAccount) matches(filters []any) bool {
+	for _, filter := range filters {
+		filter := filter.(map[string]any)
+		if dataSize, ok := filter["dataSize"]; ok && int(dataSize.(float64)) != StakeAccountSize {
+			return false
+		}
+		if memcmp, ok := filter["memcmp"].(map[string]any); ok {
+			if int(memcmp["offset"].(float64)) != StakeWithdrawerOffset || memcmp["bytes"] != a.Withdrawer {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 func (s *MockServer) handleRPCRequest(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var request Request
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	s.mu.Lock()
+	s.requestCount++
+	batchesDisabled, gzipEnabled := s.batchesDisabled, s.gzip
+	s.mu.Unlock()
+
+	var response any
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		var requests []Request
+		if err = json.Unmarshal(body, &requests); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if batchesDisabled {
+			response = Response[any]{Jsonrpc: "2.0", Error: Error{Code: -32600, Message: "Invalid request"}}
+		} else {
+			responses := make([]Response[any], len(requests))
+			for i, request := range requests {
+				responses[i] = s.respond(request, r.Header)
+			}
+			response = responses
+		}
+	} else {
+		var request Request
+		if err = json.Unmarshal(body, &request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response = s.respond(request, r.Header)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if gzipEnabled && strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		w.Header().Set("Content-Encoding", "gzip")
+		gzipWriter := gzip.NewWriter(w)
+		//goland:noinspection GoUnhandledErrorResult
+		defer gzipWriter.Close()
+		err = json.NewEncoder(gzipWriter).Encode(response)
+	} else {
+		err = json.NewEncoder(w).Encode(response)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// respond records a single request (which may be part of a batch) and returns its response.
+func (s *MockServer) respond(request Request, header http.Header) Response[any] {
+	s.mu.Lock()
+	if s.callCounts == nil {
+		s.callCounts = make(map[string]int)
+	}
+	s.callCounts[request.Method]++
+	if s.lastParams == nil {
+		s.lastParams = make(map[string][]any)
+	}
+	s.lastParams[request.Method] = request.Params
+	if s.lastHeaders == nil {
+		s.lastHeaders = make(map[string]http.Header)
+	}
+	s.lastHeaders[request.Method] = header.Clone()
+	latency := s.latencies[request.Method]
+	s.mu.Unlock()
+	time.Sleep(latency)
 
 	response := Response[any]{Jsonrpc: "2.0", Id: request.Id}
 	result, rpcErr := s.getResult(request.Method, request.Params...)
@@ -305,17 +642,12 @@ func (s *MockServer) handleRPCRequest(w http.ResponseWriter, r *http.Request) {
 	} else {
 		response.Result = result
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	err := json.NewEncoder(w).Encode(response)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
+	return response
 }
 
 // NewMockClient creates a new test client with a running mock server
 func NewMockClient(
-	t *testing.T,
+	t testing.TB,
 	easyResults map[string]any,
 	easyErrors map[string]*Error,
 	balances map[string]int,
@@ -117,6 +117,48 @@ func TestMockServer_getInflationReward(t *testing.T) {
 	)
 }
 
+func TestMockServer_getTokenAccountBalance(t *testing.T) {
+	server, client := NewMockClient(t, nil, nil, nil, nil, nil, nil)
+	server.SetOpt(TokenAccountOpt, "aaa", MockTokenAccount{Mint: "mint", Amount: 1_500_000, Decimals: 6})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	balance, err := client.GetTokenAccountBalance(ctx, CommitmentFinalized, "aaa")
+	assert.NoError(t, err)
+	assert.Equal(t, &TokenAmount{Amount: "1500000", Decimals: 6}, balance)
+
+	mint, err := client.GetTokenAccountMint(ctx, CommitmentFinalized, "aaa")
+	assert.NoError(t, err)
+	assert.Equal(t, "mint", mint)
+
+	_, err = client.GetTokenAccountBalance(ctx, CommitmentFinalized, "bbb")
+	assert.Error(t, err)
+	_, err = client.GetTokenAccountMint(ctx, CommitmentFinalized, "bbb")
+	assert.Error(t, err)
+}
+
+func TestMockServer_getProgramAccounts(t *testing.T) {
+	server, client := NewMockClient(t, nil, nil, nil, nil, nil, nil)
+	stake := int64(5 * LamportsInSol)
+	server.SetOpt(StakeAccountOpt, "aaa", MockStakeAccount{Withdrawer: "pool", DelegatedStake: &stake})
+	server.SetOpt(StakeAccountOpt, "bbb", MockStakeAccount{Withdrawer: "pool"})
+	server.SetOpt(StakeAccountOpt, "ccc", MockStakeAccount{Withdrawer: "other", DelegatedStake: &stake})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	accounts, err := client.GetProgramAccounts(
+		ctx, CommitmentFinalized, StakeProgram,
+		ProgramAccountsFilter{Memcmp: &MemcmpFilter{Offset: StakeWithdrawerOffset, Bytes: "pool"}},
+	)
+	assert.NoError(t, err)
+	stakes := make(map[string]int64)
+	for _, account := range accounts {
+		stakes[account.Pubkey], err = account.DelegatedStake()
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, map[string]int64{"aaa": stake, "bbb": 0}, stakes)
+}
+
 func TestMockServer_getVoteAccounts(t *testing.T) {
 	_, client := NewMockClient(t,
 		nil,
@@ -125,15 +167,15 @@ func TestMockServer_getVoteAccounts(t *testing.T) {
 		nil,
 		nil,
 		map[string]MockValidatorInfo{
-			"aaa": {"AAA", 1, 2, false, 10},
-			"bbb": {"BBB", 3, 4, false, 11},
-			"ccc": {"CCC", 5, 6, true, 12},
+			"aaa": {"AAA", 1, 2, false, 10, 100, 5},
+			"bbb": {"BBB", 3, 4, false, 11, 110, 6},
+			"ccc": {"CCC", 5, 6, true, 12, 120, 7},
 		},
 	)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	voteAccounts, err := client.GetVoteAccounts(ctx, CommitmentFinalized)
+	voteAccounts, err := client.GetVoteAccounts(ctx, CommitmentFinalized, "")
 	assert.NoError(t, err)
 	// sort the vote accounts before comparing:
 	sort.Slice(voteAccounts.Current, func(i, j int) bool {
@@ -142,13 +184,37 @@ func TestMockServer_getVoteAccounts(t *testing.T) {
 	assert.Equal(t,
 		VoteAccounts{
 			Current: []VoteAccount{
-				{1, 2, "aaa", 10, "AAA"},
-				{3, 4, "bbb", 11, "BBB"},
+				{1, 2, "aaa", 10, "AAA", 5, [][3]int64{{0, 100, 0}}},
+				{3, 4, "bbb", 11, "BBB", 6, [][3]int64{{0, 110, 0}}},
 			},
 			Delinquent: []VoteAccount{
-				{5, 6, "ccc", 12, "CCC"},
+				{5, 6, "ccc", 12, "CCC", 7, [][3]int64{{0, 120, 0}}},
 			},
 		},
 		*voteAccounts,
 	)
 }
+
+func TestMockServer_getVoteAccounts_votePubkey(t *testing.T) {
+	_, client := NewMockClient(t,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		map[string]MockValidatorInfo{
+			"aaa": {"AAA", 1, 2, false, 10, 100, 5},
+			"bbb": {"BBB", 3, 4, false, 11, 110, 6},
+			"ccc": {"CCC", 5, 6, true, 12, 120, 7},
+		},
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	voteAccounts, err := client.GetVoteAccounts(ctx, CommitmentFinalized, "CCC")
+	assert.NoError(t, err)
+	assert.Equal(t,
+		VoteAccounts{Delinquent: []VoteAccount{{5, 6, "ccc", 12, "CCC", 7, [][3]int64{{0, 120, 0}}}}},
+		*voteAccounts,
+	)
+}
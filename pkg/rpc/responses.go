@@ -3,6 +3,8 @@ package rpc
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
 )
 
 type (
@@ -28,6 +30,12 @@ type (
 		} `json:"context"`
 	}
 
+	Version struct {
+		SolanaCore string `json:"solana-core"`
+		// FeatureSet is the unique identifier of the feature set of the node, which may be unset (i.e., 0):
+		FeatureSet int64 `json:"feature-set"`
+	}
+
 	EpochInfo struct {
 		AbsoluteSlot     int64 `json:"absoluteSlot"`
 		BlockHeight      int64 `json:"blockHeight"`
@@ -37,12 +45,31 @@ type (
 		TransactionCount int64 `json:"transactionCount"`
 	}
 
+	EpochSchedule struct {
+		SlotsPerEpoch            int64 `json:"slotsPerEpoch"`
+		LeaderScheduleSlotOffset int64 `json:"leaderScheduleSlotOffset"`
+		Warmup                   bool  `json:"warmup"`
+		FirstNormalEpoch         int64 `json:"firstNormalEpoch"`
+		FirstNormalSlot          int64 `json:"firstNormalSlot"`
+	}
+
+	PerformanceSample struct {
+		Slot             int64 `json:"slot"`
+		NumTransactions  int64 `json:"numTransactions"`
+		NumSlots         int64 `json:"numSlots"`
+		SamplePeriodSecs int64 `json:"samplePeriodSecs"`
+	}
+
 	VoteAccount struct {
 		ActivatedStake int64  `json:"activatedStake"`
 		LastVote       int    `json:"lastVote"`
 		NodePubkey     string `json:"nodePubkey"`
 		RootSlot       int    `json:"rootSlot"`
 		VotePubkey     string `json:"votePubkey"`
+		// Commission is the percentage of the rewards paid to the validator:
+		Commission int `json:"commission"`
+		// EpochCredits holds the credit history of the latest epochs, as [epoch, credits, previousCredits] entries:
+		EpochCredits [][3]int64 `json:"epochCredits"`
 	}
 
 	VoteAccounts struct {
@@ -81,6 +108,113 @@ type (
 		RewardType string `json:"rewardType"`
 	}
 
+	// Account holds the metadata of an account, as returned by getMultipleAccounts.
+	Account struct {
+		Lamports   int64  `json:"lamports"`
+		Owner      string `json:"owner"`
+		Executable bool   `json:"executable"`
+		// Space is the length (in bytes) of the account data.
+		Space int64 `json:"space"`
+	}
+
+	// AccountInfo is an account, as returned by getAccountInfo with the jsonParsed encoding.
+	AccountInfo struct {
+		Lamports   int64  `json:"lamports"`
+		Owner      string `json:"owner"`
+		Executable bool   `json:"executable"`
+		Space      int64  `json:"space"`
+		// Data is either the parsed data of the account, or the raw data if it could not be parsed by the rpc:
+		Data json.RawMessage `json:"data"`
+	}
+
+	// UpgradeableLoaderState is the state of an account owned by the UpgradeableLoaderProgram. Only the fields of its
+	// Type are set.
+	UpgradeableLoaderState struct {
+		// Type is one of 'uninitialized', 'buffer', 'program' or 'programData'.
+		Type string
+		// ProgramData is the address of the ProgramData account of a program:
+		ProgramData string
+		// Slot is the slot in which a program was last deployed, as per its ProgramData account:
+		Slot int64
+		// Authority is the upgrade authority of a program (or buffer), or nil if it is immutable:
+		Authority *string
+	}
+
+	// ContactInfo is the gossip information of a node, as returned by getClusterNodes. Unset fields are nil.
+	ContactInfo struct {
+		Pubkey  string  `json:"pubkey"`
+		Gossip  *string `json:"gossip"`
+		Tpu     *string `json:"tpu"`
+		TpuQuic *string `json:"tpuQuic"`
+		Rpc     *string `json:"rpc"`
+		Version *string `json:"version"`
+		// FeatureSet is the feature set advertised by the node, if any:
+		FeatureSet *int64 `json:"featureSet"`
+	}
+
+	// SignatureStatus is the status of a transaction, which failed if Err is not nil.
+	SignatureStatus struct {
+		Slot int64 `json:"slot"`
+		// Confirmations is the number of blocks since the transaction was confirmed, or nil once it is rooted:
+		Confirmations      *int64     `json:"confirmations"`
+		Err                any        `json:"err"`
+		ConfirmationStatus Commitment `json:"confirmationStatus"`
+	}
+
+	LargestAccount struct {
+		Address  string `json:"address"`
+		Lamports int64  `json:"lamports"`
+	}
+
+	HighestSnapshotSlot struct {
+		Full int64 `json:"full"`
+		// Incremental is nil if there is no incremental snapshot
+		Incremental *int64 `json:"incremental"`
+	}
+
+	TokenAmount struct {
+		// Amount is the raw balance without decimals, as a string representation of an u64
+		Amount   string `json:"amount"`
+		Decimals int    `json:"decimals"`
+	}
+
+	TokenAccountInfo struct {
+		Data struct {
+			Parsed struct {
+				Info struct {
+					Mint string `json:"mint"`
+				} `json:"info"`
+			} `json:"parsed"`
+		} `json:"data"`
+	}
+
+	// ProgramAccountsFilter filters the results of getProgramAccounts, by exactly one of its fields.
+	ProgramAccountsFilter struct {
+		DataSize int64         `json:"dataSize,omitempty"`
+		Memcmp   *MemcmpFilter `json:"memcmp,omitempty"`
+	}
+
+	// MemcmpFilter matches accounts whose data contains Bytes (base58 encoded) at Offset.
+	MemcmpFilter struct {
+		Offset int64  `json:"offset"`
+		Bytes  string `json:"bytes"`
+	}
+
+	ProgramAccount struct {
+		Pubkey  string `json:"pubkey"`
+		Account struct {
+			Lamports int64           `json:"lamports"`
+			Owner    string          `json:"owner"`
+			Data     json.RawMessage `json:"data"`
+		} `json:"account"`
+	}
+
+	StakeActivation struct {
+		State    string `json:"state"`
+		Active   int64  `json:"active"`
+		Inactive int64  `json:"inactive"`
+	}
+
 	FullTransaction struct {
 		Transaction struct {
 			Message struct {
@@ -94,6 +228,126 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("%s rpc error (code: %d): %s (data: %v)", e.Method, e.Code, e.Message, e.Data)
 }
 
+// UiAmount returns the token amount with its decimals applied. The amount is computed from the raw u64 amount, rather
+// than taken from the deprecated (and lossy) uiAmount field.
+func (t *TokenAmount) UiAmount() (float64, error) {
+	amount, err := strconv.ParseUint(t.Amount, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse token amount %q: %w", t.Amount, err)
+	}
+	return float64(amount) / math.Pow10(t.Decimals), nil
+}
+
+// DelegatedStake returns the stake (in lamports) delegated by the stake account, which must have been fetched with
+// the jsonParsed encoding. Stake accounts which are not delegated have no delegated stake.
+func (a *ProgramAccount) DelegatedStake() (int64, error) {
+	var data struct {
+		Parsed struct {
+			Info struct {
+				Stake *struct {
+					Delegation struct {
+						Stake string `json:"stake"`
+					} `json:"delegation"`
+				} `json:"stake"`
+			} `json:"info"`
+		} `json:"parsed"`
+	}
+	if err := json.Unmarshal(a.Account.Data, &data); err != nil {
+		return 0, fmt.Errorf("failed to decode stake account %s: %w", a.Pubkey, err)
+	}
+	if data.Parsed.Info.Stake == nil {
+		return 0, nil
+	}
+	stake, err := strconv.ParseInt(data.Parsed.Info.Stake.Delegation.Stake, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse delegated stake of %s: %w", a.Pubkey, err)
+	}
+	return stake, nil
+}
+
+// UpgradeableLoaderState decodes the state of an account owned by the UpgradeableLoaderProgram, which must have been
+// fetched with the jsonParsed encoding.
+func (a *AccountInfo) UpgradeableLoaderState() (*UpgradeableLoaderState, error) {
+	if a.Owner != UpgradeableLoaderProgram {
+		return nil, fmt.Errorf("account is owned by %s, not the upgradeable loader", a.Owner)
+	}
+	var data struct {
+		Parsed struct {
+			Type string `json:"type"`
+			Info struct {
+				ProgramData string  `json:"programData"`
+				Slot        int64   `json:"slot"`
+				Authority   *string `json:"authority"`
+			} `json:"info"`
+		} `json:"parsed"`
+	}
+	// accounts which the rpc could not parse have their raw data instead, which is not decoded here:
+	if err := json.Unmarshal(a.Data, &data); err != nil || data.Parsed.Type == "" {
+		return nil, fmt.Errorf("upgradeable loader account data was not parsed by the rpc")
+	}
+	info := data.Parsed.Info
+	state := &UpgradeableLoaderState{Type: data.Parsed.Type}
+	switch state.Type {
+	case "program":
+		state.ProgramData = info.ProgramData
+	case "programData":
+		state.Slot, state.Authority = info.Slot, info.Authority
+	case "buffer":
+		state.Authority = info.Authority
+	}
+	return state, nil
+}
+
+// LatestEpochCredits returns the credits earned by the vote account in the latest epoch of its credit history.
+func (a *VoteAccount) LatestEpochCredits() int64 {
+	if len(a.EpochCredits) == 0 {
+		return 0
+	}
+	latest := a.EpochCredits[len(a.EpochCredits)-1]
+	return latest[1] - latest[2]
+}
+
+// SlotsRemaining returns the number of slots from slot until the first slot of the next epoch. During warmup, epochs
+// start at MinimumSlotsPerEpoch slots and double in length until the first normal epoch.
+func (s *EpochSchedule) SlotsRemaining(slot int64) int64 {
+	if !s.Warmup || slot >= s.FirstNormalSlot {
+		return s.SlotsPerEpoch - (slot-s.FirstNormalSlot)%s.SlotsPerEpoch
+	}
+	firstSlot, slotsInEpoch := int64(0), int64(MinimumSlotsPerEpoch)
+	for slot >= firstSlot+slotsInEpoch {
+		firstSlot += slotsInEpoch
+		slotsInEpoch *= 2
+	}
+	return firstSlot + slotsInEpoch - slot
+}
+
+// FirstSlot returns the first slot of the epoch which slot belongs to.
+func (s *EpochSchedule) FirstSlot(slot int64) int64 {
+	if !s.Warmup || slot >= s.FirstNormalSlot {
+		return slot - (slot-s.FirstNormalSlot)%s.SlotsPerEpoch
+	}
+	firstSlot, slotsInEpoch := int64(0), int64(MinimumSlotsPerEpoch)
+	for slot >= firstSlot+slotsInEpoch {
+		firstSlot += slotsInEpoch
+		slotsInEpoch *= 2
+	}
+	return firstSlot
+}
+
+// Epoch returns the epoch which slot belongs to.
+func (s *EpochSchedule) Epoch(slot int64) int64 {
+	if !s.Warmup || slot >= s.FirstNormalSlot {
+		return s.FirstNormalEpoch + (slot-s.FirstNormalSlot)/s.SlotsPerEpoch
+	}
+	epoch, firstSlot, slotsInEpoch := int64(0), int64(0), int64(MinimumSlotsPerEpoch)
+	for slot >= firstSlot+slotsInEpoch {
+		epoch++
+		firstSlot += slotsInEpoch
+		slotsInEpoch *= 2
+	}
+	return epoch
+}
+
 func (hp *HostProduction) UnmarshalJSON(data []byte) error {
 	var arr []int64
 	if err := json.Unmarshal(data, &arr); err != nil {
@@ -0,0 +1,115 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxIdleConnsPerHost is the default number of idle (keep-alive) connections kept per host, which is
+	// higher than that of http.DefaultTransport (2) so that concurrent collectors can reuse their connections.
+	DefaultMaxIdleConnsPerHost = 16
+	// DefaultIdleConnTimeout is the default time after which idle connections are closed.
+	DefaultIdleConnTimeout = 90 * time.Second
+)
+
+type (
+	// TransportConfig configures the connection pooling (and TLS) of the http.Transport created by NewTransport.
+	TransportConfig struct {
+		// MaxIdleConnsPerHost is the maximum number of idle (keep-alive) connections to keep per host.
+		MaxIdleConnsPerHost int
+		// MaxConnsPerHost limits the total number of connections per host, or is 0 for no limit.
+		MaxConnsPerHost int
+		// IdleConnTimeout is the time after which idle connections are closed, or is 0 for no limit.
+		IdleConnTimeout time.Duration
+		// TLS configures the TLS connections (e.g., see TLSFiles), or is nil for the defaults.
+		TLS *tls.Config
+	}
+
+	// TLSFiles are the PEM files configuring TLS connections to rpc nodes, any of which may be empty.
+	TLSFiles struct {
+		// CertFile and KeyFile are the client certificate (and its key) presented to rpc nodes requiring mutual TLS:
+		CertFile string
+		KeyFile  string
+		// CAFile holds the CA certificates which verify rpc nodes, instead of the system's:
+		CAFile string
+	}
+
+	// trackedConn is a net.Conn which is counted in OpenConnections until it is closed.
+	trackedConn struct {
+		net.Conn
+		once sync.Once
+	}
+)
+
+// NewTransport creates an http.Transport with the provided connection pooling, whose connections are counted in
+// OpenConnections. Compression is left to Client.Gzip, and other settings (e.g., proxies) are those of
+// http.DefaultTransport.
+func NewTransport(config TransportConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	if transport.MaxIdleConns < config.MaxIdleConnsPerHost {
+		transport.MaxIdleConns = config.MaxIdleConnsPerHost
+	}
+	transport.MaxConnsPerHost = config.MaxConnsPerHost
+	transport.IdleConnTimeout = config.IdleConnTimeout
+	if config.TLS != nil {
+		transport.TLSClientConfig = config.TLS
+	}
+	// compression is only requested as per Client.Gzip:
+	transport.DisableCompression = true
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, address)
+		if err != nil {
+			return nil, err
+		}
+		OpenConnections.Inc()
+		return &trackedConn{Conn: conn}, nil
+	}
+	return transport
+}
+
+// Load loads the TLS config of the files, or returns nil if none are set. The certificate and key must be set
+// together.
+func (f TLSFiles) Load() (*tls.Config, error) {
+	if f == (TLSFiles{}) {
+		return nil, nil
+	}
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if f.CertFile != "" || f.KeyFile != "" {
+		if f.CertFile == "" || f.KeyFile == "" {
+			return nil, fmt.Errorf("the client certificate and key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(f.CertFile, f.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if f.CAFile != "" {
+		data, err := os.ReadFile(f.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no CA certificates found in %s", f.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(OpenConnections.Dec)
+	return c.Conn.Close()
+}
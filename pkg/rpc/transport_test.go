@@ -0,0 +1,185 @@
+package rpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTransport(t *testing.T) {
+	_, client := NewMockClient(t, map[string]any{"getHealth": "ok"}, nil, nil, nil, nil, nil)
+	transport := NewTransport(
+		TransportConfig{MaxIdleConnsPerHost: DefaultMaxIdleConnsPerHost, IdleConnTimeout: DefaultIdleConnTimeout},
+	)
+	client.HttpClient.Transport = transport
+	ctx := context.Background()
+
+	// the gauge is global, so only compare the increments:
+	before := testutil.ToFloat64(OpenConnections)
+	for i := 0; i < 3; i++ {
+		_, err := client.GetHealth(ctx)
+		assert.NoError(t, err)
+	}
+	// sequential requests reuse the same connection:
+	assert.Equal(t, 1.0, testutil.ToFloat64(OpenConnections)-before)
+
+	transport.CloseIdleConnections()
+	assert.Eventually(
+		t,
+		func() bool { return testutil.ToFloat64(OpenConnections) == before },
+		time.Second,
+		10*time.Millisecond,
+	)
+}
+
+// BenchmarkClient_ConnectionReuse makes concurrent requests with the idle connection pool of http.DefaultTransport
+// (2 per host) and that of NewTransport, reporting the number of connections dialled: with too few idle connections,
+// most of the concurrent requests have to dial a new connection rather than reuse one.
+func BenchmarkClient_ConnectionReuse(b *testing.B) {
+	for _, maxIdleConnsPerHost := range []int{2, DefaultMaxIdleConnsPerHost} {
+		b.Run(fmt.Sprintf("max_idle_conns_per_host=%d", maxIdleConnsPerHost), func(b *testing.B) {
+			_, client := NewMockClient(b, map[string]any{"getHealth": "ok"}, nil, nil, nil, nil, nil)
+			transport := NewTransport(
+				TransportConfig{MaxIdleConnsPerHost: maxIdleConnsPerHost, IdleConnTimeout: DefaultIdleConnTimeout},
+			)
+			var dials atomic.Int64
+			dial := transport.DialContext
+			transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+				dials.Add(1)
+				return dial(ctx, network, address)
+			}
+			client.HttpClient.Transport = transport
+			defer transport.CloseIdleConnections()
+
+			b.SetParallelism(8)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := client.GetHealth(context.Background()); err != nil {
+						b.Error(err)
+					}
+				}
+			})
+			b.ReportMetric(float64(dials.Load()), "dials")
+		})
+	}
+}
+
+// writeCertificate writes a PEM certificate (and its key) signed by parent (or self-signed if nil) into dir, and
+// returns it along with the paths of its files.
+func writeCertificate(
+	t *testing.T, dir, name string, template *x509.Certificate, parent *tls.Certificate,
+) (cert tls.Certificate, certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	signer, signerKey := template, any(key)
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certFile, keyFile = filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	assert.NoError(t, os.WriteFile(certFile, certPem, 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, keyPem, 0o600))
+	cert, err = tls.X509KeyPair(certPem, keyPem)
+	assert.NoError(t, err)
+	cert.Leaf, err = x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return cert, certFile, keyFile
+}
+
+func TestNewTransport_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, _, _ := writeCertificate(t, dir, "ca", &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil)
+	_, certFile, keyFile := writeCertificate(t, dir, "client", &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "exporter"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, &ca)
+
+	// the rpc node only accepts clients with a certificate signed by the CA:
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","result":"ok","id":1}`))
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.Leaf)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+	// and has a self-signed certificate of its own:
+	serverCAFile := filepath.Join(dir, "server.crt")
+	serverPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.NoError(t, os.WriteFile(serverCAFile, serverPem, 0o600))
+
+	tests := []struct {
+		name    string
+		files   TLSFiles
+		wantErr bool
+	}{
+		{name: "without client certificate", files: TLSFiles{CAFile: serverCAFile}, wantErr: true},
+		{name: "with client certificate", files: TLSFiles{CertFile: certFile, KeyFile: keyFile, CAFile: serverCAFile}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := tt.files.Load()
+			assert.NoError(t, err)
+			client := NewRPCClient(server.URL, time.Second, 0)
+			client.HttpClient.Transport = NewTransport(TransportConfig{TLS: tlsConfig})
+
+			health, err := client.GetHealth(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "ok", health)
+			}
+		})
+	}
+}
+
+func TestTLSFiles_Load(t *testing.T) {
+	tlsConfig, err := TLSFiles{}.Load()
+	assert.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+
+	// the certificate and key must be set together:
+	_, err = TLSFiles{CertFile: "client.crt"}.Load()
+	assert.Error(t, err)
+	_, err = TLSFiles{CAFile: filepath.Join(t.TempDir(), "missing.crt")}.Load()
+	assert.Error(t, err)
+}
@@ -8,21 +8,34 @@ import (
 	"strings"
 )
 
-var log *zap.SugaredLogger
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
 
-// Init initializes the logger
-func Init() {
-	config := zap.NewProductionConfig()
+var (
+	log *zap.SugaredLogger
 
-	// configure:
-	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	config.Level = zap.NewAtomicLevelAt(getEnvLogLevel())
+	// Formats are the supported log formats.
+	Formats = []string{FormatJSON, FormatConsole}
+)
 
-	logger, err := config.Build()
+// Init initializes the logger, in the json format and at the level of the 'LOG_LEVEL' environment variable
+func Init() {
+	if err := Configure(FormatJSON, ""); err != nil {
+		panic(err)
+	}
+}
+
+// Configure (re)initializes the logger with the provided format (one of Formats) and level. An empty level falls back
+// to the 'LOG_LEVEL' environment variable.
+func Configure(format, level string) error {
+	logger, err := newLogger(format, level, "stderr")
 	if err != nil {
-		panic(fmt.Errorf("error initializing logger: %v", err))
+		return err
 	}
-	log = logger.Sugar()
+	log = logger
+	return nil
 }
 
 // Get returns the global logger instance
@@ -35,26 +48,67 @@ func Sync() error {
 	return log.Sync()
 }
 
-func getEnvLogLevel() zapcore.Level {
-	level, ok := os.LookupEnv("LOG_LEVEL")
-	if !ok {
-		return zapcore.InfoLevel
-	}
+// ParseLevel parses a log level, one of 'debug', 'info', 'warn', 'error', 'panic' or 'fatal'.
+func ParseLevel(level string) (zapcore.Level, error) {
 	switch strings.ToLower(level) {
 	case "debug":
-		return zapcore.DebugLevel
+		return zapcore.DebugLevel, nil
 	case "info":
-		return zapcore.InfoLevel
+		return zapcore.InfoLevel, nil
 	case "warn":
-		return zapcore.WarnLevel
+		return zapcore.WarnLevel, nil
 	case "error":
-		return zapcore.ErrorLevel
+		return zapcore.ErrorLevel, nil
 	case "panic":
-		return zapcore.PanicLevel
+		return zapcore.PanicLevel, nil
 	case "fatal":
-		return zapcore.FatalLevel
+		return zapcore.FatalLevel, nil
 	default:
-		fmt.Printf("Unrecognised 'LOG_LEVEL' environment variable '%s', using 'info'\n", level)
+		return zapcore.InfoLevel, fmt.Errorf("unrecognised log level '%s'", level)
+	}
+}
+
+// newLogger builds a logger with the provided format and level, writing to outputPath.
+func newLogger(format, level, outputPath string) (*zap.SugaredLogger, error) {
+	config := zap.NewProductionConfig()
+
+	// configure:
+	switch format {
+	case FormatJSON, "":
+		config.Encoding = FormatJSON
+	case FormatConsole:
+		config.Encoding = FormatConsole
+		config.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	default:
+		return nil, fmt.Errorf("unrecognised log format '%s', must be one of %v", format, Formats)
+	}
+	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	if level == "" {
+		config.Level = zap.NewAtomicLevelAt(getEnvLogLevel())
+	} else {
+		parsed, err := ParseLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		config.Level = zap.NewAtomicLevelAt(parsed)
+	}
+	config.OutputPaths = []string{outputPath}
+
+	logger, err := config.Build()
+	if err != nil {
+		return nil, fmt.Errorf("error initializing logger: %v", err)
+	}
+	return logger.Sugar(), nil
+}
+
+func getEnvLogLevel() zapcore.Level {
+	level, ok := os.LookupEnv("LOG_LEVEL")
+	if !ok {
 		return zapcore.InfoLevel
 	}
+	parsed, err := ParseLevel(level)
+	if err != nil {
+		fmt.Printf("Unrecognised 'LOG_LEVEL' environment variable '%s', using 'info'\n", level)
+	}
+	return parsed
 }
@@ -0,0 +1,53 @@
+package slog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLogger(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "log.json")
+		logger, err := newLogger(FormatJSON, "debug", path)
+		assert.NoError(t, err)
+		logger.Debugw("rpc error", "method", "getSlot", "code", -32005)
+		assert.NoError(t, logger.Sync())
+
+		output, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		var entry map[string]any
+		assert.NoError(t, json.Unmarshal(output, &entry))
+		for _, key := range []string{"level", "ts", "caller", "msg", "method", "code"} {
+			assert.Contains(t, entry, key)
+		}
+		assert.Equal(t, "debug", entry["level"])
+		assert.Equal(t, "getSlot", entry["method"])
+		assert.Equal(t, -32005.0, entry["code"])
+	})
+
+	t.Run("console", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "log.txt")
+		logger, err := newLogger(FormatConsole, "warn", path)
+		assert.NoError(t, err)
+		logger.Info("hidden")
+		logger.Warnw("shown", "method", "getSlot")
+		assert.NoError(t, logger.Sync())
+
+		output, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(output), "hidden")
+		assert.Contains(t, string(output), "WARN")
+		assert.Contains(t, string(output), `{"method": "getSlot"}`)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := newLogger("xml", "", "stderr")
+		assert.Error(t, err)
+		_, err = newLogger(FormatJSON, "verbose", "stderr")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,17 @@
+// Package version holds the build information of the exporter, which is set at build time through -ldflags, e.g.:
+//
+//	go build -ldflags "-X github.com/asymmetric-research/solana-exporter/pkg/version.Version=v3.1.0 \
+//		-X github.com/asymmetric-research/solana-exporter/pkg/version.Commit=$(git rev-parse HEAD)" \
+//		./cmd/solana-exporter
+package version
+
+import "runtime"
+
+var (
+	// Version is the release version of the exporter, or "dev" if it was not set at build time.
+	Version = "dev"
+	// Commit is the git commit the exporter was built from, or "unknown" if it was not set at build time.
+	Commit = "unknown"
+	// GoVersion is the version of Go the exporter was built with.
+	GoVersion = runtime.Version()
+)